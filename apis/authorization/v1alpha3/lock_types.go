@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A LockLevel restricts the operations that may be performed on the locked
+// resources.
+// Keep synced with "github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-09-01/locks".LockLevel
+// +kubebuilder:validation:Enum=CanNotDelete;ReadOnly
+type LockLevel string
+
+// All valid values of LockLevel
+const (
+	// LockLevelCanNotDelete allows authorized users to read and modify the
+	// locked resources, but not to delete them.
+	LockLevelCanNotDelete LockLevel = "CanNotDelete"
+
+	// LockLevelReadOnly allows authorized users to read the locked resources,
+	// but not to modify or delete them.
+	LockLevelReadOnly LockLevel = "ReadOnly"
+)
+
+// ManagementLockParameters define the desired state of an Azure management
+// lock. A lock applies either to a resource group, or to an Azure resource.
+// The locked Azure resource may be specified by its ID, or by referencing a
+// VirtualNetwork, PostgreSQLServer, or MySQLServer.
+type ManagementLockParameters struct {
+	// Level of the lock. CanNotDelete allows authorized users to read and
+	// modify the locked resources, but not to delete them. ReadOnly allows
+	// authorized users to read the locked resources, but not to modify or
+	// delete them. Note that a ReadOnly lock also prevents Crossplane from
+	// updating the locked resources.
+	Level LockLevel `json:"level"`
+
+	// Notes about the lock.
+	// +kubebuilder:validation:MaxLength=512
+	// +optional
+	Notes *string `json:"notes,omitempty"`
+
+	// ResourceGroupName - Name of the resource group to lock, or of the
+	// resource group of the resource to lock.
+	// +optional
+	ResourceGroupName string `json:"resourceGroupName,omitempty"`
+
+	// ResourceGroupNameRef - A reference to the resource group to lock.
+	// +optional
+	ResourceGroupNameRef *xpv1.Reference `json:"resourceGroupNameRef,omitempty"`
+
+	// ResourceGroupNameSelector - Selects a reference to the resource group
+	// to lock.
+	// +optional
+	ResourceGroupNameSelector *xpv1.Selector `json:"resourceGroupNameSelector,omitempty"`
+
+	// ResourceID - ID of the Azure resource to lock. The resource group is
+	// locked if no resource ID is specified or referenced. At most one of
+	// the VirtualNetwork, PostgreSQLServer, and MySQLServer references and
+	// selectors may be set.
+	// +optional
+	ResourceID *string `json:"resourceId,omitempty"`
+
+	// VirtualNetworkIDRef - A reference to a VirtualNetwork to lock.
+	// +optional
+	VirtualNetworkIDRef *xpv1.Reference `json:"virtualNetworkIdRef,omitempty"`
+
+	// VirtualNetworkIDSelector - Selects a reference to a VirtualNetwork to
+	// lock.
+	// +optional
+	VirtualNetworkIDSelector *xpv1.Selector `json:"virtualNetworkIdSelector,omitempty"`
+
+	// PostgreSQLServerIDRef - A reference to a PostgreSQLServer to lock.
+	// +optional
+	PostgreSQLServerIDRef *xpv1.Reference `json:"postgresqlServerIdRef,omitempty"`
+
+	// PostgreSQLServerIDSelector - Selects a reference to a PostgreSQLServer
+	// to lock.
+	// +optional
+	PostgreSQLServerIDSelector *xpv1.Selector `json:"postgresqlServerIdSelector,omitempty"`
+
+	// MySQLServerIDRef - A reference to a MySQLServer to lock.
+	// +optional
+	MySQLServerIDRef *xpv1.Reference `json:"mysqlServerIdRef,omitempty"`
+
+	// MySQLServerIDSelector - Selects a reference to a MySQLServer to lock.
+	// +optional
+	MySQLServerIDSelector *xpv1.Selector `json:"mysqlServerIdSelector,omitempty"`
+}
+
+// A ManagementLockSpec defines the desired state of a ManagementLock.
+type ManagementLockSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ManagementLockParameters `json:"forProvider"`
+}
+
+// ManagementLockObservation represents the observed state of an Azure
+// management lock.
+type ManagementLockObservation struct {
+	// ID - The resource ID of the lock.
+	ID string `json:"id,omitempty"`
+
+	// Name - The name of the lock.
+	Name string `json:"name,omitempty"`
+
+	// Type - The resource type of the lock.
+	Type string `json:"type,omitempty"`
+}
+
+// A ManagementLockStatus represents the observed state of a ManagementLock.
+type ManagementLockStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ManagementLockObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ManagementLock is a managed resource that represents an Azure management
+// lock, which protects a resource group or Azure resource from accidental
+// deletion or modification.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="LEVEL",type="string",JSONPath=".spec.forProvider.level"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,azure}
+type ManagementLock struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagementLockSpec   `json:"spec"`
+	Status ManagementLockStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ManagementLockList contains a list of ManagementLock.
+type ManagementLockList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagementLock `json:"items"`
+}
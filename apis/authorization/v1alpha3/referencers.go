@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+
+	databasev1beta1 "github.com/crossplane/provider-azure/apis/database/v1beta1"
+	networkv1alpha3 "github.com/crossplane/provider-azure/apis/network/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1alpha3"
+)
+
+const errMultipleResourceIDReferences = "spec.forProvider.resourceId: at most one of virtualNetworkId, postgresqlServerId, and mysqlServerId may be referenced or selected"
+
+// ResolveReferences of this ManagementLock. It returns an error if more than
+// one of the VirtualNetwork, PostgreSQLServer, and MySQLServer references or
+// selectors is set, since they all resolve the resource ID.
+func (mg *ManagementLock) ResolveReferences(ctx context.Context, c client.Reader) error {
+	p := mg.Spec.ForProvider
+	set := 0
+	for _, ok := range []bool{
+		p.VirtualNetworkIDRef != nil || p.VirtualNetworkIDSelector != nil,
+		p.PostgreSQLServerIDRef != nil || p.PostgreSQLServerIDSelector != nil,
+		p.MySQLServerIDRef != nil || p.MySQLServerIDSelector != nil,
+	} {
+		if ok {
+			set++
+		}
+	}
+	if set > 1 {
+		return errors.New(errMultipleResourceIDReferences)
+	}
+
+	r := reference.NewAPIResolver(c, mg)
+
+	// Resolve spec.forProvider.resourceGroupName
+	rsp, err := r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: mg.Spec.ForProvider.ResourceGroupName,
+		Reference:    mg.Spec.ForProvider.ResourceGroupNameRef,
+		Selector:     mg.Spec.ForProvider.ResourceGroupNameSelector,
+		To:           reference.To{Managed: &v1alpha3.ResourceGroup{}, List: &v1alpha3.ResourceGroupList{}},
+		Extract:      reference.ExternalName(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "spec.forProvider.resourceGroupName")
+	}
+	mg.Spec.ForProvider.ResourceGroupName = rsp.ResolvedValue
+	mg.Spec.ForProvider.ResourceGroupNameRef = rsp.ResolvedReference
+
+	// Resolve spec.forProvider.resourceId from a VirtualNetwork
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.ResourceID),
+		Reference:    mg.Spec.ForProvider.VirtualNetworkIDRef,
+		Selector:     mg.Spec.ForProvider.VirtualNetworkIDSelector,
+		To:           reference.To{Managed: &networkv1alpha3.VirtualNetwork{}, List: &networkv1alpha3.VirtualNetworkList{}},
+		Extract:      networkv1alpha3.VirtualNetworkID(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "spec.forProvider.resourceId")
+	}
+	mg.Spec.ForProvider.ResourceID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.VirtualNetworkIDRef = rsp.ResolvedReference
+
+	// Resolve spec.forProvider.resourceId from a PostgreSQLServer
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.ResourceID),
+		Reference:    mg.Spec.ForProvider.PostgreSQLServerIDRef,
+		Selector:     mg.Spec.ForProvider.PostgreSQLServerIDSelector,
+		To:           reference.To{Managed: &databasev1beta1.PostgreSQLServer{}, List: &databasev1beta1.PostgreSQLServerList{}},
+		Extract:      databasev1beta1.PostgreSQLServerID(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "spec.forProvider.resourceId")
+	}
+	mg.Spec.ForProvider.ResourceID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.PostgreSQLServerIDRef = rsp.ResolvedReference
+
+	// Resolve spec.forProvider.resourceId from a MySQLServer
+	rsp, err = r.Resolve(ctx, reference.ResolutionRequest{
+		CurrentValue: reference.FromPtrValue(mg.Spec.ForProvider.ResourceID),
+		Reference:    mg.Spec.ForProvider.MySQLServerIDRef,
+		Selector:     mg.Spec.ForProvider.MySQLServerIDSelector,
+		To:           reference.To{Managed: &databasev1beta1.MySQLServer{}, List: &databasev1beta1.MySQLServerList{}},
+		Extract:      databasev1beta1.MySQLServerID(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "spec.forProvider.resourceId")
+	}
+	mg.Spec.ForProvider.ResourceID = reference.ToPtrValue(rsp.ResolvedValue)
+	mg.Spec.ForProvider.MySQLServerIDRef = rsp.ResolvedReference
+
+	return nil
+}
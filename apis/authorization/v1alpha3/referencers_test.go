@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	networkv1alpha3 "github.com/crossplane/provider-azure/apis/network/v1alpha3"
+)
+
+func TestManagementLockResolveReferences(t *testing.T) {
+	id := "/subscriptions/cool/resourceGroups/coolrg/providers/Microsoft.Network/virtualNetworks/coolvnet"
+
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			if v, ok := obj.(*networkv1alpha3.VirtualNetwork); ok {
+				v.Status.ID = id
+			}
+			return nil
+		},
+	}
+
+	type want struct {
+		params ManagementLockParameters
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		params ManagementLockParameters
+		want   want
+	}{
+		"SingleReference": {
+			reason: "A single reference should resolve the resource ID.",
+			params: ManagementLockParameters{
+				ResourceGroupName:   "coolrg",
+				VirtualNetworkIDRef: &xpv1.Reference{Name: "coolvnet"},
+			},
+			want: want{params: ManagementLockParameters{
+				ResourceGroupName:   "coolrg",
+				ResourceID:          &id,
+				VirtualNetworkIDRef: &xpv1.Reference{Name: "coolvnet"},
+			}},
+		},
+		"MultipleReferences": {
+			reason: "Setting more than one reference should return an error, since they all resolve the resource ID.",
+			params: ManagementLockParameters{
+				ResourceGroupName:     "coolrg",
+				VirtualNetworkIDRef:   &xpv1.Reference{Name: "coolvnet"},
+				PostgreSQLServerIDRef: &xpv1.Reference{Name: "coolserver"},
+			},
+			want: want{
+				params: ManagementLockParameters{
+					ResourceGroupName:     "coolrg",
+					VirtualNetworkIDRef:   &xpv1.Reference{Name: "coolvnet"},
+					PostgreSQLServerIDRef: &xpv1.Reference{Name: "coolserver"},
+				},
+				err: errors.New(errMultipleResourceIDReferences),
+			},
+		},
+		"ReferenceAndSelector": {
+			reason: "Setting a reference and a selector of different kinds should return an error.",
+			params: ManagementLockParameters{
+				ResourceGroupName:     "coolrg",
+				VirtualNetworkIDRef:   &xpv1.Reference{Name: "coolvnet"},
+				MySQLServerIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"cool": "server"}},
+			},
+			want: want{
+				params: ManagementLockParameters{
+					ResourceGroupName:     "coolrg",
+					VirtualNetworkIDRef:   &xpv1.Reference{Name: "coolvnet"},
+					MySQLServerIDSelector: &xpv1.Selector{MatchLabels: map[string]string{"cool": "server"}},
+				},
+				err: errors.New(errMultipleResourceIDReferences),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mg := &ManagementLock{Spec: ManagementLockSpec{ForProvider: tc.params}}
+			err := mg.ResolveReferences(context.Background(), kube)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.params, mg.Spec.ForProvider); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ package apis
 import (
 	"k8s.io/apimachinery/pkg/runtime"
 
+	authorizationv1alpha3 "github.com/crossplane/provider-azure/apis/authorization/v1alpha3"
 	cachev1beta1 "github.com/crossplane/provider-azure/apis/cache/v1beta1"
 	computev1alpha3 "github.com/crossplane/provider-azure/apis/compute/v1alpha3"
 	databasev1alpha3 "github.com/crossplane/provider-azure/apis/database/v1alpha3"
@@ -35,6 +36,7 @@ func init() {
 	AddToSchemes = append(AddToSchemes,
 		azurev1alpha3.SchemeBuilder.AddToScheme,
 		azurev1beta1.SchemeBuilder.AddToScheme,
+		authorizationv1alpha3.SchemeBuilder.AddToScheme,
 		cachev1beta1.SchemeBuilder.AddToScheme,
 		computev1alpha3.SchemeBuilder.AddToScheme,
 		databasev1alpha3.SchemeBuilder.AddToScheme,
@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	apisv1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
 )
 
 const (
@@ -75,9 +77,11 @@ type RedisParameters struct {
 	// Sku - The SKU of the Redis cache to deploy.
 	SKU SKU `json:"sku"`
 
-	// Location in which to create this resource.
+	// Location in which to create this resource. Defaults to the default
+	// location of the ProviderConfig.
 	// +immutable
-	Location string `json:"location"`
+	// +optional
+	Location string `json:"location,omitempty"`
 
 	// SubnetID specifies the full resource ID of a subnet in a virtual network
 	// to deploy the Redis cache in. Example format:
@@ -167,6 +171,10 @@ type RedisObservation struct {
 
 	// Name - Resource name.
 	Name string `json:"name,omitempty"`
+
+	// LastOperation represents the state of the last operation started by the
+	// controller.
+	LastOperation apisv1alpha3.AsyncOperation `json:"lastOperation,omitempty"`
 }
 
 // A RedisStatus represents the observed state of a Redis.
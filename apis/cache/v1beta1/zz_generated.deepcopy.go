@@ -92,6 +92,7 @@ func (in *RedisObservation) DeepCopyInto(out *RedisObservation) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	out.LastOperation = in.LastOperation
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisObservation.
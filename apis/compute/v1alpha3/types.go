@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	apisv1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
 )
 
 const (
@@ -42,8 +44,10 @@ type AKSClusterParameters struct {
 	// retrieve its name
 	ResourceGroupNameSelector *xpv1.Selector `json:"resourceGroupNameSelector,omitempty"`
 
-	// Location is the Azure location that the cluster will be created in
-	Location string `json:"location"`
+	// Location is the Azure location that the cluster will be created in.
+	// Defaults to the default location of the ProviderConfig.
+	// +optional
+	Location string `json:"location,omitempty"`
 
 	// Version is the Kubernetes version that will be deployed to the cluster
 	Version string `json:"version"`
@@ -102,6 +106,10 @@ type AKSClusterStatus struct {
 
 	// Endpoint is the endpoint where the cluster can be reached
 	Endpoint string `json:"endpoint,omitempty"`
+
+	// LastOperation represents the state of the last operation started by the
+	// controller.
+	LastOperation apisv1alpha3.AsyncOperation `json:"lastOperation,omitempty"`
 }
 
 // +kubebuilder:object:root=true
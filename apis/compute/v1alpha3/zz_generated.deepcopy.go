@@ -145,6 +145,7 @@ func (in *AKSClusterSpec) DeepCopy() *AKSClusterSpec {
 func (in *AKSClusterStatus) DeepCopyInto(out *AKSClusterStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	out.LastOperation = in.LastOperation
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AKSClusterStatus.
@@ -21,6 +21,8 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/services/cosmos-db/mgmt/2015-04-08/documentdb"
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	apisv1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
 )
 
 // +kubebuilder:object:root=true
@@ -75,8 +77,10 @@ type CosmosDBAccountParameters struct {
 
 	// Location - The location of the resource. This will be one of the
 	// supported and registered Azure Geo Regions (e.g. West US, East US,
-	// Southeast Asia, etc.).
-	Location string `json:"location"`
+	// Southeast Asia, etc.). Defaults to the default location of the
+	// ProviderConfig.
+	// +optional
+	Location string `json:"location,omitempty"`
 
 	// Properties - Account properties like databaseAccountOfferType,
 	// ipRangeFilters, etc.
@@ -179,4 +183,9 @@ type CosmosDBAccountStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
 	// + optional
 	AtProvider *CosmosDBAccountObservation `json:"atProvider,omitempty"`
+
+	// LastOperation represents the state of the last operation started by the
+	// controller.
+	// + optional
+	LastOperation apisv1alpha3.AsyncOperation `json:"lastOperation,omitempty"`
 }
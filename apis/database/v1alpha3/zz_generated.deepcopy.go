@@ -243,6 +243,7 @@ func (in *CosmosDBAccountStatus) DeepCopyInto(out *CosmosDBAccountStatus) {
 		*out = new(CosmosDBAccountObservation)
 		**out = **in
 	}
+	out.LastOperation = in.LastOperation
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CosmosDBAccountStatus.
@@ -149,9 +149,11 @@ type SQLServerParameters struct {
 	// SKU is the billing information related properties of the server.
 	SKU SKU `json:"sku"`
 
-	// Location specifies the location of this SQLServer.
+	// Location specifies the location of this SQLServer. Defaults to the
+	// default location of the ProviderConfig.
 	// +immutable
-	Location string `json:"location"`
+	// +optional
+	Location string `json:"location,omitempty"`
 
 	// AdministratorLogin - The administrator's login name of a server. Can only be specified when the server is being created (and is required for creation).
 	// +immutable
@@ -40,6 +40,18 @@ func SubnetID() reference.ExtractValueFn {
 	}
 }
 
+// VirtualNetworkID extracts status.ID from the supplied managed resource,
+// which must be a VirtualNetwork.
+func VirtualNetworkID() reference.ExtractValueFn {
+	return func(mg resource.Managed) string {
+		v, ok := mg.(*VirtualNetwork)
+		if !ok {
+			return ""
+		}
+		return v.Status.ID
+	}
+}
+
 // ResolveReferences of this VirtualNetwork
 func (mg *VirtualNetwork) ResolveReferences(ctx context.Context, c client.Reader) error {
 	r := reference.NewAPIResolver(c, mg)
@@ -20,6 +20,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	apisv1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
 )
 
 // AddressSpace contains an array of IP address ranges that can be used by
@@ -67,8 +69,10 @@ type VirtualNetworkSpec struct {
 	// VirtualNetworkPropertiesFormat - Properties of the virtual network.
 	VirtualNetworkPropertiesFormat `json:"properties"`
 
-	// Location - Resource location.
-	Location string `json:"location"`
+	// Location - Resource location. Defaults to the default location of the
+	// ProviderConfig.
+	// +optional
+	Location string `json:"location,omitempty"`
 
 	// Tags - Resource tags.
 	// +optional
@@ -90,7 +94,7 @@ type VirtualNetworkStatus struct {
 	ID string `json:"id,omitempty"`
 
 	// Etag - A unique read-only string that changes whenever the resource is
-	// updated.
+	// updated. Updates are conditional on it.
 	Etag string `json:"etag,omitempty"`
 
 	// ResourceGUID - The GUID of this VirtualNetwork.
@@ -98,6 +102,10 @@ type VirtualNetworkStatus struct {
 
 	// Type of this VirtualNetwork.
 	Type string `json:"type,omitempty"`
+
+	// LastOperation represents the state of the last operation started by the
+	// controller.
+	LastOperation apisv1alpha3.AsyncOperation `json:"lastOperation,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -191,6 +199,7 @@ type SubnetStatus struct {
 	Message string `json:"message,omitempty"`
 
 	// Etag - A unique string that changes whenever the resource is updated.
+	// Updates are conditional on it.
 	Etag string `json:"etag,omitempty"`
 
 	// ID of this Subnet.
@@ -199,6 +208,10 @@ type SubnetStatus struct {
 	// Purpose - A string identifying the intention of use for this subnet based
 	// on delegations and other user-defined properties.
 	Purpose string `json:"purpose,omitempty"`
+
+	// LastOperation represents the state of the last operation started by the
+	// controller.
+	LastOperation apisv1alpha3.AsyncOperation `json:"lastOperation,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -75,6 +75,10 @@ type ResourceGroupStatus struct {
 
 	// ProvisioningState - The provisioning state of the resource group.
 	ProvisioningState ProvisioningState `json:"provisioningState,omitempty"`
+
+	// LastOperation represents the state of the last operation started by the
+	// controller.
+	LastOperation AsyncOperation `json:"lastOperation,omitempty"`
 }
 
 // A ResourceGroup is a managed resource that represents an Azure Resource
@@ -109,6 +113,10 @@ type AsyncOperation struct {
 	// PollingURL is used to fetch the status of the given operation.
 	PollingURL string `json:"pollingUrl,omitempty"`
 
+	// PollingMethod is the method Azure uses to report the status of the
+	// given operation, e.g. AsyncOperation or Location.
+	PollingMethod string `json:"pollingMethod,omitempty"`
+
 	// Status represents the status of the operation.
 	Status string `json:"status,omitempty"`
 
@@ -22,30 +22,151 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
+// CredentialsSourceWorkloadIdentity indicates that the provider should
+// exchange a projected Kubernetes service account token for an Azure AD token
+// using workload identity federation.
+const CredentialsSourceWorkloadIdentity xpv1.CredentialsSource = "WorkloadIdentity"
+
+// AnnotationKeySubscriptionID is the annotation a managed resource may use to
+// override the subscription ID of its ProviderConfig. The subscription must be
+// allowed by the ProviderConfig. This annotation is the only way to override
+// the subscription; managed resources have no spec field for it. It applies
+// to managed resources that use a ProviderConfig, not a Provider.
+const AnnotationKeySubscriptionID = "azure.crossplane.io/subscription-id"
+
 // A ProviderConfigSpec defines the desired state of a ProviderConfig.
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+
+	// SubscriptionID of the Azure subscription that managed resources using
+	// this ProviderConfig live in. It is required when the credentials source
+	// is InjectedIdentity or WorkloadIdentity, and takes precedence over the
+	// subscriptionId key of the credentials otherwise.
+	// +optional
+	SubscriptionID *string `json:"subscriptionID,omitempty"`
+
+	// AllowedSubscriptionIDs are the Azure subscriptions that managed
+	// resources using this ProviderConfig may override its subscription with.
+	// Managed resources override it only using the
+	// azure.crossplane.io/subscription-id annotation; they have no spec field
+	// for it. The subscription of the ProviderConfig is always allowed.
+	// +optional
+	AllowedSubscriptionIDs []string `json:"allowedSubscriptionIDs,omitempty"`
+
+	// AuxiliaryTenantIDs are the IDs of up to three other Azure AD tenants in
+	// which the service principal is registered. Clients that support
+	// cross-tenant operations authenticate to these tenants too; currently
+	// those of MySQL and PostgreSQL server virtual network rules, whose subnet
+	// may belong to another tenant. Only supported when authenticating with a
+	// service principal client secret.
+	// +kubebuilder:validation:MaxItems=3
+	// +optional
+	AuxiliaryTenantIDs []string `json:"auxiliaryTenantIDs,omitempty"`
+
+	// Environment is the Azure cloud that managed resources using this
+	// ProviderConfig live in. Defaults to the Azure public cloud, unless the
+	// credentials specify their own endpoints.
+	// +optional
+	Environment *Environment `json:"environment,omitempty"`
+
+	// Defaults applied to the managed resources that use this ProviderConfig.
+	// +optional
+	Defaults *ResourceDefaults `json:"defaults,omitempty"`
+}
+
+// ResourceDefaults are applied to managed resources when they are created or
+// updated.
+type ResourceDefaults struct {
+	// Location of managed resources that don't specify one.
+	// +optional
+	Location *string `json:"location,omitempty"`
+
+	// ResourceGroupName of managed resources that neither specify nor
+	// reference a resource group.
+	// +optional
+	ResourceGroupName *string `json:"resourceGroupName,omitempty"`
+
+	// Tags that are merged into the tags of every managed resource that
+	// supports tags. These tags take precedence over any tag of the same key
+	// that a managed resource specifies.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// An EnvironmentName identifies a well-known Azure cloud.
+type EnvironmentName string
+
+// Well-known Azure clouds.
+const (
+	EnvironmentAzurePublicCloud       EnvironmentName = "AzurePublicCloud"
+	EnvironmentAzureChinaCloud        EnvironmentName = "AzureChinaCloud"
+	EnvironmentAzureUSGovernmentCloud EnvironmentName = "AzureUSGovernmentCloud"
+)
+
+// An Environment identifies an Azure cloud, either by name or by the
+// endpoint from which its metadata may be discovered.
+type Environment struct {
+	// Name of a well-known Azure cloud.
+	// +kubebuilder:validation:Enum=AzurePublicCloud;AzureChinaCloud;AzureUSGovernmentCloud
+	// +optional
+	Name *EnvironmentName `json:"name,omitempty"`
+
+	// MetadataURL is the Azure Resource Manager endpoint of a custom cloud,
+	// e.g. an Azure Stack Hub, which serves the metadata document describing
+	// the cloud's endpoints. Takes precedence over Name.
+	// +optional
+	MetadataURL *string `json:"metadataURL,omitempty"`
 }
 
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
-	// Source of the provider credentials.
-	// +kubebuilder:validation:Enum=None;Secret;Environment;Filesystem
+	// Source of the provider credentials. InjectedIdentity authenticates as
+	// the managed identity of the node the provider runs on, while
+	// WorkloadIdentity exchanges the provider's service account token for an
+	// Azure AD token.
+	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;WorkloadIdentity;Environment;Filesystem
 	Source xpv1.CredentialsSource `json:"source"`
 
 	xpv1.CommonCredentialSelectors `json:",inline"`
+
+	// ClientID of the identity to authenticate as. When the source is
+	// InjectedIdentity it selects a user-assigned managed identity, and the
+	// system-assigned identity is used if it is omitted. When the source is
+	// WorkloadIdentity it defaults to the AZURE_CLIENT_ID environment variable.
+	// +optional
+	ClientID *string `json:"clientID,omitempty"`
+
+	// TenantID of the Azure AD tenant to authenticate against when the source
+	// is WorkloadIdentity. Defaults to the AZURE_TENANT_ID environment
+	// variable.
+	// +optional
+	TenantID *string `json:"tenantID,omitempty"`
+
+	// TokenFilePath is the path of the projected service account token to
+	// exchange when the source is WorkloadIdentity. Defaults to the
+	// AZURE_FEDERATED_TOKEN_FILE environment variable.
+	// +optional
+	TokenFilePath *string `json:"tokenFilePath,omitempty"`
 }
 
 // A ProviderConfigStatus represents the status of a ProviderConfig.
 type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
+
+	// CredentialsExpiry is the time at which the credentials of this
+	// ProviderConfig expire, if known. The expiry of a client secret is only
+	// known if the service principal may read its own application.
+	// +optional
+	CredentialsExpiry *metav1.Time `json:"credentialsExpiry,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 
 // A ProviderConfig configures an Azure 'provider', i.e. a connection to a particular
 // Azure account using a particular Azure Service Principal.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="EXPIRY",type="date",JSONPath=".status.credentialsExpiry"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentialsSecretRef.name",priority=1
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,azure}
 // +kubebuilder:subresource:status
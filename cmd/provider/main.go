@@ -17,18 +17,31 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	"go.opentelemetry.io/otel"
 	"gopkg.in/alecthomas/kingpin.v2"
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 
 	"github.com/crossplane/provider-azure/apis"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/clients/whatif"
 	"github.com/crossplane/provider-azure/pkg/controller"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
+	"github.com/crossplane/provider-azure/pkg/importer"
+	"github.com/crossplane/provider-azure/pkg/migration"
+	"github.com/crossplane/provider-azure/pkg/orphan"
 )
 
 func main() {
@@ -37,9 +50,32 @@ func main() {
 		debug          = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
 		syncInterval   = app.Flag("sync", "Sync interval controls how often all resources will be double checked for drift.").Short('s').Default("1h").Duration()
 		pollInterval   = app.Flag("poll", "Poll interval controls how often an individual resource should be checked for drift.").Default("1m").Duration()
+		healthInterval = app.Flag("provider-config-health-interval", "How often the credentials of each ProviderConfig should be validated.").Default("10m").Duration()
 		leaderElection = app.Flag("leader-election", "Use leader election for the conroller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
+		otlpEndpoint   = app.Flag("otlp-endpoint", "Export traces to the OTLP gRPC collector at this address, e.g. localhost:4317. Tracing is disabled if unset.").String()
+		otlpInsecure   = app.Flag("otlp-insecure", "Connect to the OTLP collector without TLS.").Bool()
+		traceRatio     = app.Flag("trace-sample-ratio", "Fraction of reconciles to trace, between 0 and 1.").Default("1").Float64()
+		enabled        = app.Flag("enable-controllers", "Comma separated groups (e.g. network) or kinds (e.g. VirtualNetwork) of managed resource controllers to start. All are started if unset.").Strings()
+		disabled       = app.Flag("disable-controllers", "Comma separated groups or kinds of managed resource controllers not to start.").Strings()
+		concurrency    = app.Flag("max-reconcile-concurrency", "Number of resources each managed resource controller may reconcile concurrently.").Default("1").Int()
+		groupConc      = app.Flag("group-reconcile-concurrency", "Number of resources the managed resource controllers of a group or kind may reconcile concurrently, e.g. compute=4. May be repeated.").StringMap()
+		eventGridAddr  = app.Flag("event-grid-address", "Receive Azure Event Grid resource events at this address, e.g. :8080, and reconcile the managed resources they concern immediately. Disabled if unset.").String()
+		eventGridKey   = app.Flag("event-grid-key", "Require Event Grid deliveries to include this key as the 'key' query parameter.").OverrideDefaultFromEnvar("EVENT_GRID_KEY").String()
+		graphInterval  = app.Flag("resource-graph-interval", "Query Azure Resource Graph for the Azure resources of managed resources at this interval, and observe them using the results rather than individual requests. Disabled if zero.").Default("0").Duration()
+		whatIf         = app.Flag("what-if", "Run managed resources in dry-run mode unless annotated azure.crossplane.io/dry-run=false: report the changes creating, updating, or deleting their Azure resources would make, predicted using the Azure Resource Manager what-if API, rather than making them.").Bool()
+
+		_          = app.Command("start", "Start the Azure controllers.").Default()
+		migrateCmd = app.Command("migrate", "Migrate Providers to ProviderConfigs, and managed resources from providerRef to providerConfigRef.")
+		dryRun     = migrateCmd.Flag("dry-run", "Print the changes that would be made, without making them.").Bool()
+		importCmd  = app.Command("import", "Print managed resources for the existing Azure resources of a resource group, so that they may be adopted.")
+		importRG   = importCmd.Flag("resource-group", "Resource group whose Azure resources should be imported.").Required().String()
+		importPC   = importCmd.Flag("provider-config", "ProviderConfig used to list Azure resources, and referenced by the imported managed resources.").Default("default").String()
+		importNS   = importCmd.Flag("connection-secret-namespace", "Namespace imported managed resources should write their connection secrets to. They don't write them if unset.").String()
+		importObs  = importCmd.Flag("observe-only", "Annotate imported managed resources with the ObserveOnly management policy, such that their Azure resources are never modified.").Bool()
+		orphansCmd = app.Command("orphans", "List Azure resources that are tagged as created by the provider, but whose managed resource no longer exists.")
+		orphansPC  = orphansCmd.Flag("provider-config", "ProviderConfig whose subscription should be searched for orphaned Azure resources.").Default("default").String()
 	)
-	kingpin.MustParse(app.Parse(os.Args[1:]))
+	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
 
 	zl := zap.New(zap.UseDevMode(*debug))
 	log := logging.NewLogrLogger(zl.WithName("provider-azure"))
@@ -50,11 +86,56 @@ func main() {
 		ctrl.SetLogger(zl)
 	}
 
-	log.Debug("Starting", "sync-period", syncInterval.String())
-
 	cfg, err := ctrl.GetConfig()
 	kingpin.FatalIfError(err, "Cannot get API server rest config")
 
+	if cmd == migrateCmd.FullCommand() {
+		s := runtime.NewScheme()
+		kingpin.FatalIfError(apis.AddToScheme(s), "Cannot add Azure APIs to scheme")
+		c, err := client.New(cfg, client.Options{Scheme: s})
+		kingpin.FatalIfError(err, "Cannot create API server client")
+		m := migration.NewMigrator(c, migration.WithOutput(os.Stdout), migration.WithDryRun(*dryRun))
+		kingpin.FatalIfError(m.Migrate(context.Background()), "Cannot migrate Providers to ProviderConfigs")
+		return
+	}
+
+	if cmd == importCmd.FullCommand() {
+		s := runtime.NewScheme()
+		kingpin.FatalIfError(apis.AddToScheme(s), "Cannot add Azure APIs to scheme")
+		c, err := client.New(cfg, client.Options{Scheme: s})
+		kingpin.FatalIfError(err, "Cannot create API server client")
+		l, err := importer.NewAzureLister(context.Background(), c, *importPC)
+		kingpin.FatalIfError(err, "Cannot get Azure credentials")
+		i := importer.NewImporter(l,
+			importer.WithOutput(os.Stdout),
+			importer.WithProviderConfig(*importPC),
+			importer.WithConnectionSecretNamespace(*importNS),
+			importer.WithObserveOnly(*importObs))
+		kingpin.FatalIfError(i.Import(context.Background(), *importRG), "Cannot import Azure resources")
+		return
+	}
+
+	if cmd == orphansCmd.FullCommand() {
+		s := runtime.NewScheme()
+		kingpin.FatalIfError(apis.AddToScheme(s), "Cannot add Azure APIs to scheme")
+		c, err := client.New(cfg, client.Options{Scheme: s})
+		kingpin.FatalIfError(err, "Cannot create API server client")
+		l, err := orphan.NewGraphLister(context.Background(), c, *orphansPC)
+		kingpin.FatalIfError(err, "Cannot get Azure credentials")
+		f := orphan.NewFinder(c, l, orphan.WithOutput(os.Stdout))
+		kingpin.FatalIfError(f.Report(context.Background()), "Cannot find orphaned Azure resources")
+		return
+	}
+
+	log.Debug("Starting", "sync-period", syncInterval.String())
+
+	if *otlpEndpoint != "" {
+		tp, err := tracing.NewTracerProvider(context.Background(), *otlpEndpoint, *otlpInsecure, *traceRatio)
+		kingpin.FatalIfError(err, "Cannot create tracer provider")
+		otel.SetTracerProvider(tp)
+		defer tp.Shutdown(context.Background()) // nolint:errcheck
+	}
+
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		LeaderElection:   *leaderElection,
 		LeaderElectionID: "crossplane-leader-election-provider-azure",
@@ -63,7 +144,43 @@ func main() {
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add Azure APIs to scheme")
-	kingpin.FatalIfError(controller.Setup(mgr, log, ratelimiter.NewDefaultProviderRateLimiter(ratelimiter.DefaultProviderRPS), *pollInterval), "Cannot setup Azure controllers")
+	gc := make(map[string]int, len(*groupConc))
+	for name, v := range *groupConc {
+		n, err := strconv.Atoi(v)
+		kingpin.FatalIfError(err, "Cannot parse concurrency of %s", name)
+		gc[name] = n
+	}
+	o := controller.Options{
+		PollInterval:     *pollInterval,
+		HealthInterval:   *healthInterval,
+		Enabled:          splitCommas(*enabled),
+		Disabled:         splitCommas(*disabled),
+		Concurrency:      *concurrency,
+		GroupConcurrency: gc,
+	}
+	whatif.SetDefault(*whatIf)
+	eventgrid.SetEnabled(*eventGridAddr != "")
+	kingpin.FatalIfError(controller.Setup(mgr, log, ratelimiter.NewDefaultProviderRateLimiter(ratelimiter.DefaultProviderRPS), o), "Cannot setup Azure controllers")
+	if *graphInterval > 0 {
+		kingpin.FatalIfError(mgr.Add(azure.NewGraphCacheRunnable(log.WithValues("cache", "resourcegraph"), *graphInterval)), "Cannot add Azure Resource Graph cache")
+	}
+	if *eventGridAddr != "" {
+		r := eventgrid.NewReceiver(eventgrid.WithLogger(log.WithValues("receiver", "eventgrid")), eventgrid.WithKey(*eventGridKey))
+		kingpin.FatalIfError(mgr.Add(eventgrid.NewServer(*eventGridAddr, r)), "Cannot add Event Grid receiver")
+	}
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
+}
 
+// splitCommas splits each of the supplied values on commas, so that lists can
+// be supplied either as one comma separated flag or as repeated flags.
+func splitCommas(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
 }
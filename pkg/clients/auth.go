@@ -0,0 +1,353 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/graphrbac/1.6/graphrbac"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+)
+
+// Credentials keys that are not part of the Azure SDK credentials file format.
+// They are populated from the ProviderConfig when the provider authenticates
+// with an identity rather than a service principal secret, or when it targets
+// an Azure environment other than the public cloud.
+const (
+	// CredentialsKeyMSIEndpoint is the endpoint used to acquire managed
+	// identity tokens, i.e. IMDS or the App Service MSI endpoint.
+	CredentialsKeyMSIEndpoint = "msiEndpoint"
+	// CredentialsKeyFederatedTokenFile is the path of a projected service
+	// account token that is trusted by a federated identity credential.
+	CredentialsKeyFederatedTokenFile = "federatedTokenFile"
+	// CredentialsKeyStorageEndpointSuffix is the DNS suffix of storage
+	// account endpoints, e.g. core.windows.net.
+	CredentialsKeyStorageEndpointSuffix = "storageEndpointSuffix"
+	// CredentialsKeyTokenAudience is the audience of Azure Resource Manager
+	// tokens, if it differs from the Azure Resource Manager endpoint as it
+	// does for Azure Stack Hub.
+	CredentialsKeyTokenAudience = "tokenAudience"
+	// CredentialsKeyAuxiliaryTenantIDs is a comma separated list of other
+	// tenants in which the service principal is registered, and for which
+	// auxiliary tokens are acquired.
+	CredentialsKeyAuxiliaryTenantIDs = "auxiliaryTenantIds"
+)
+
+// Environment variables injected by the Azure workload identity webhook.
+const (
+	envClientID           = "AZURE_CLIENT_ID"
+	envTenantID           = "AZURE_TENANT_ID"
+	envFederatedTokenFile = "AZURE_FEDERATED_TOKEN_FILE"
+)
+
+const clientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// Error strings.
+const (
+	errNewOAuthConfig       = "cannot create OAuth configuration"
+	errNewToken             = "cannot create service principal token"
+	errReadFederatedToken   = "cannot read federated service account token"
+	errNoFederatedTokenFile = "no service account token file was supplied for workload identity"
+	errNoClientIDFmt        = "no client ID was supplied for credentials source %s"
+	errNoTenantIDFmt        = "no tenant ID was supplied for credentials source %s"
+	errNoSubscriptionIDFmt  = "no subscription ID was supplied for credentials source %s"
+	errNoAuxiliaryTenants   = "no auxiliary tenant IDs were supplied"
+	errAuxiliaryNeedsSecret = "auxiliary tenant tokens require a service principal client secret"
+
+	errReadClientCertificate = "cannot read client certificate"
+	errDecodePFX             = "cannot decode PFX client certificate"
+	errDecryptPEMBlock       = "cannot decrypt PEM block of client certificate"
+	errParseCertificate      = "cannot parse client certificate"
+	errParsePrivateKey       = "cannot parse client certificate private key"
+	errNotRSAPrivateKey      = "client certificate private key is not an RSA key"
+	errNoCertificateInPEM    = "no certificate found in PEM encoded client certificate"
+	errNoPrivateKeyInPEM     = "no private key found in PEM encoded client certificate"
+
+	errGetApplication = "cannot get service principal application"
+)
+
+// PEM block types of client certificates.
+const (
+	pemBlockTypeCertificate     = "CERTIFICATE"
+	pemBlockTypeRSAPrivateKey   = "RSA PRIVATE KEY"
+	pemBlockTypePKCS8PrivateKey = "PRIVATE KEY"
+)
+
+// tokenSender sends the requests made by tokens to acquire and refresh
+// themselves, recording them as spans so that they can be told apart from the
+// requests that they authorize.
+var tokenSender = tracing.NewSender(autorest.CreateSender())
+
+// NewServicePrincipalToken returns a token that authenticates to the supplied
+// resource, e.g. Azure Resource Manager or the AAD graph, using the supplied
+// credentials. Managed identity is used if an MSI endpoint is present,
+// workload identity if a federated token file is present, a client
+// certificate if one is present, and a service principal secret otherwise.
+func NewServicePrincipalToken(creds map[string]string, resource string) (*adal.ServicePrincipalToken, error) {
+	t, err := newServicePrincipalToken(creds, resource)
+	if err != nil {
+		return nil, err
+	}
+	t.SetSender(tokenSender)
+	return t, nil
+}
+
+func newServicePrincipalToken(creds map[string]string, resource string) (*adal.ServicePrincipalToken, error) {
+	if ep := creds[CredentialsKeyMSIEndpoint]; ep != "" {
+		if id := creds[CredentialsKeyClientID]; id != "" {
+			t, err := adal.NewServicePrincipalTokenFromMSIWithUserAssignedID(ep, resource, id)
+			return t, errors.Wrap(err, errNewToken)
+		}
+		t, err := adal.NewServicePrincipalTokenFromMSI(ep, resource)
+		return t, errors.Wrap(err, errNewToken)
+	}
+
+	cfg, err := adal.NewOAuthConfig(creds[CredentialsKeyActiveDirectoryEndpointURL], creds[CredentialsKeyTenantID])
+	if err != nil {
+		return nil, errors.Wrap(err, errNewOAuthConfig)
+	}
+
+	if f := creds[CredentialsKeyFederatedTokenFile]; f != "" {
+		t, err := adal.NewServicePrincipalTokenWithSecret(*cfg, creds[CredentialsKeyClientID], resource, &federatedTokenSecret{path: f})
+		return t, errors.Wrap(err, errNewToken)
+	}
+
+	if creds[CredentialsKeyClientCertificate] != "" || creds[CredentialsKeyClientCertificatePath] != "" {
+		cert, key, err := ClientCertificate(creds)
+		if err != nil {
+			return nil, err
+		}
+		t, err := adal.NewServicePrincipalTokenFromCertificate(*cfg, creds[CredentialsKeyClientID], cert, key, resource)
+		return t, errors.Wrap(err, errNewToken)
+	}
+
+	t, err := adal.NewServicePrincipalToken(*cfg, creds[CredentialsKeyClientID], creds[CredentialsKeyClientSecret], resource)
+	return t, errors.Wrap(err, errNewToken)
+}
+
+// NewMultiTenantServicePrincipalToken returns a token that authenticates to
+// the supplied resource using the supplied credentials, in both the primary
+// tenant and the auxiliary tenants of the credentials. Only service principal
+// secrets are supported.
+func NewMultiTenantServicePrincipalToken(creds map[string]string, resource string) (*adal.MultiTenantServicePrincipalToken, error) {
+	aux := AuxiliaryTenantIDs(creds)
+	if len(aux) == 0 {
+		return nil, errors.New(errNoAuxiliaryTenants)
+	}
+	if creds[CredentialsKeyClientSecret] == "" {
+		return nil, errors.New(errAuxiliaryNeedsSecret)
+	}
+	cfg, err := adal.NewMultiTenantOAuthConfig(creds[CredentialsKeyActiveDirectoryEndpointURL], creds[CredentialsKeyTenantID], aux, adal.OAuthOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, errNewOAuthConfig)
+	}
+	t, err := adal.NewMultiTenantServicePrincipalToken(cfg, creds[CredentialsKeyClientID], creds[CredentialsKeyClientSecret], resource)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewToken)
+	}
+	t.PrimaryToken.SetSender(tokenSender)
+	for _, aux := range t.AuxiliaryTokens {
+		aux.SetSender(tokenSender)
+	}
+	return t, nil
+}
+
+// AuxiliaryTenantIDs returns the auxiliary tenant IDs of the supplied
+// credentials.
+func AuxiliaryTenantIDs(creds map[string]string) []string {
+	var ids []string
+	for _, id := range strings.Split(creds[CredentialsKeyAuxiliaryTenantIDs], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ClientCertificate returns the certificate and private key a service
+// principal authenticates with. The certificate is read from the
+// clientCertificate key, or from the file at clientCertificatePath if that key
+// is empty. It may be PEM encoded, or a PFX archive. PFX archives embedded in
+// the credentials must be base64 encoded, since they are binary.
+func ClientCertificate(creds map[string]string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	data := []byte(creds[CredentialsKeyClientCertificate])
+	if len(data) == 0 {
+		d, err := ioutil.ReadFile(creds[CredentialsKeyClientCertificatePath])
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errReadClientCertificate)
+		}
+		data = d
+	}
+	pw := creds[CredentialsKeyClientCertificatePassword]
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		return decodePEMCertificate(data, pw)
+	}
+	if pfx, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data))); err == nil {
+		data = pfx
+	}
+	cert, key, err := adal.DecodePfxCertificateData(data, pw)
+	return cert, key, errors.Wrap(err, errDecodePFX)
+}
+
+// decodePEMCertificate returns the first certificate and RSA private key found
+// in the supplied PEM data. Encrypted private keys are decrypted using the
+// supplied password.
+func decodePEMCertificate(data []byte, password string) (*x509.Certificate, *rsa.PrivateKey, error) { // nolint:gocyclo
+	var cert *x509.Certificate
+	var key *rsa.PrivateKey
+	for b, rest := pem.Decode(data); b != nil; b, rest = pem.Decode(rest) {
+		der := b.Bytes
+		// Legacy PEM encryption is insecure, but it is what many tools still
+		// produce when asked for an encrypted private key.
+		if x509.IsEncryptedPEMBlock(b) { // nolint:staticcheck
+			d, err := x509.DecryptPEMBlock(b, []byte(password)) // nolint:staticcheck
+			if err != nil {
+				return nil, nil, errors.Wrap(err, errDecryptPEMBlock)
+			}
+			der = d
+		}
+		switch b.Type {
+		case pemBlockTypeCertificate:
+			if cert != nil {
+				// The first certificate is the leaf; the rest are its chain.
+				continue
+			}
+			c, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, errParseCertificate)
+			}
+			cert = c
+		case pemBlockTypeRSAPrivateKey:
+			k, err := x509.ParsePKCS1PrivateKey(der)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, errParsePrivateKey)
+			}
+			key = k
+		case pemBlockTypePKCS8PrivateKey:
+			k, err := x509.ParsePKCS8PrivateKey(der)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, errParsePrivateKey)
+			}
+			rk, ok := k.(*rsa.PrivateKey)
+			if !ok {
+				return nil, nil, errors.New(errNotRSAPrivateKey)
+			}
+			key = rk
+		}
+	}
+	if cert == nil {
+		return nil, nil, errors.New(errNoCertificateInPEM)
+	}
+	if key == nil {
+		return nil, nil, errors.New(errNoPrivateKeyInPEM)
+	}
+	return cert, key, nil
+}
+
+// CredentialsExpiry returns the time at which the supplied credentials expire,
+// or nil if they don't expire or their expiry is unknown. The expiry of a
+// client certificate is read from the certificate. The expiry of a client
+// secret is read from the service principal's application using the supplied
+// AAD graph authorizer; the latest expiry of the application's secrets is
+// returned since it is impossible to tell which secret is in use.
+func CredentialsExpiry(ctx context.Context, creds map[string]string, graph autorest.Authorizer) (*time.Time, error) {
+	switch {
+	case creds[CredentialsKeyMSIEndpoint] != "", creds[CredentialsKeyFederatedTokenFile] != "":
+		return nil, nil
+	case creds[CredentialsKeyClientCertificate] != "" || creds[CredentialsKeyClientCertificatePath] != "":
+		cert, _, err := ClientCertificate(creds)
+		if err != nil {
+			return nil, err
+		}
+		return &cert.NotAfter, nil
+	}
+
+	c := graphrbac.NewApplicationsClientWithBaseURI(creds[CredentialsKeyActiveDirectoryGraphResourceID], creds[CredentialsKeyTenantID])
+	ConfigureClient(&c.Client, graph)
+	page, err := c.List(ctx, fmt.Sprintf("appId eq '%s'", creds[CredentialsKeyClientID]))
+	if err != nil {
+		return nil, errors.Wrap(err, errGetApplication)
+	}
+	var expiry *time.Time
+	for _, app := range page.Values() {
+		if app.PasswordCredentials == nil {
+			continue
+		}
+		for _, pc := range *app.PasswordCredentials {
+			if pc.EndDate == nil {
+				continue
+			}
+			if expiry == nil || pc.EndDate.After(*expiry) {
+				t := pc.EndDate.Time
+				expiry = &t
+			}
+		}
+	}
+	return expiry, nil
+}
+
+// NewAuthorizer returns an authorizer for Azure Resource Manager calls that
+// uses the supplied credentials.
+func NewAuthorizer(creds map[string]string) (autorest.Authorizer, error) {
+	t, err := NewServicePrincipalToken(creds, resourceManagerAudience(creds))
+	if err != nil {
+		return nil, errors.Wrap(err, errGetAuthorizer)
+	}
+	return autorest.NewBearerAuthorizer(t), nil
+}
+
+// resourceManagerAudience returns the audience of Azure Resource Manager
+// tokens, which is usually the Azure Resource Manager endpoint.
+func resourceManagerAudience(creds map[string]string) string {
+	if aud := creds[CredentialsKeyTokenAudience]; aud != "" {
+		return aud
+	}
+	return creds[CredentialsKeyResourceManagerEndpointURL]
+}
+
+// federatedTokenSecret authenticates using a Kubernetes service account token
+// that Azure AD trusts through a federated identity credential. The token is
+// read on every refresh since the kubelet rotates it.
+type federatedTokenSecret struct {
+	path string
+}
+
+// SetAuthenticationValues sets the service account token as the client
+// assertion of the token request.
+func (s *federatedTokenSecret) SetAuthenticationValues(_ *adal.ServicePrincipalToken, v *url.Values) error {
+	t, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return errors.Wrap(err, errReadFederatedToken)
+	}
+	v.Set("client_assertion_type", clientAssertionTypeJWTBearer)
+	v.Set("client_assertion", strings.TrimSpace(string(t)))
+	return nil
+}
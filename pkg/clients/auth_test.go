@@ -0,0 +1,394 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+const (
+	testResource    = "https://management.azure.com/"
+	testAccessToken = "cool-token"
+	testSAToken     = "cool-service-account-token"
+
+	// testdata/client.pfx was generated by openssl with this password and the
+	// common name below.
+	testPFXFile       = "testdata/client.pfx"
+	testPFXPassword   = "cool-password"
+	testPFXCommonName = "crossplane-test"
+)
+
+// tokenServer returns a server that records the token requests it receives
+// and responds with a token.
+func tokenServer(got *[]url.Values) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		*got = append(*got, r.Form)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": %q, "expires_in": "3600", "expires_on": "1893456000", "resource": %q, "token_type": "Bearer"}`, testAccessToken, testResource)
+	}))
+}
+
+func TestNewServicePrincipalToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // nolint:errcheck
+	saToken := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(saToken, []byte(testSAToken+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		form url.Values
+	}
+	cases := map[string]struct {
+		reason string
+		creds  func(endpoint string) map[string]string
+		want   want
+	}{
+		"SystemAssignedManagedIdentity": {
+			reason: "A token should be acquired from the MSI endpoint without a client ID.",
+			creds: func(endpoint string) map[string]string {
+				return map[string]string{CredentialsKeyMSIEndpoint: endpoint}
+			},
+			want: want{form: url.Values{
+				"api-version": {"2018-02-01"},
+				"resource":    {testResource},
+			}},
+		},
+		"UserAssignedManagedIdentity": {
+			reason: "A token should be acquired from the MSI endpoint for the supplied client ID.",
+			creds: func(endpoint string) map[string]string {
+				return map[string]string{CredentialsKeyMSIEndpoint: endpoint, CredentialsKeyClientID: "cool-identity"}
+			},
+			want: want{form: url.Values{
+				"api-version": {"2018-02-01"},
+				"resource":    {testResource},
+				"client_id":   {"cool-identity"},
+			}},
+		},
+		"WorkloadIdentity": {
+			reason: "The service account token should be exchanged as a client assertion.",
+			creds: func(endpoint string) map[string]string {
+				return map[string]string{
+					CredentialsKeyActiveDirectoryEndpointURL: endpoint,
+					CredentialsKeyTenantID:                   "cool-tenant",
+					CredentialsKeyClientID:                   "cool-app",
+					CredentialsKeyFederatedTokenFile:         saToken,
+				}
+			},
+			want: want{form: url.Values{
+				"api-version":           {"1.0"},
+				"client_id":             {"cool-app"},
+				"resource":              {testResource},
+				"grant_type":            {"client_credentials"},
+				"client_assertion_type": {clientAssertionTypeJWTBearer},
+				"client_assertion":      {testSAToken},
+			}},
+		},
+		"ClientSecret": {
+			reason: "The client secret should be sent with the token request.",
+			creds: func(endpoint string) map[string]string {
+				return map[string]string{
+					CredentialsKeyActiveDirectoryEndpointURL: endpoint,
+					CredentialsKeyTenantID:                   "cool-tenant",
+					CredentialsKeyClientID:                   "cool-app",
+					CredentialsKeyClientSecret:               "cool-secret",
+				}
+			},
+			want: want{form: url.Values{
+				"api-version":   {"1.0"},
+				"client_id":     {"cool-app"},
+				"resource":      {testResource},
+				"grant_type":    {"client_credentials"},
+				"client_secret": {"cool-secret"},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []url.Values{}
+			srv := tokenServer(&got)
+			defer srv.Close()
+
+			tk, err := NewServicePrincipalToken(tc.creds(srv.URL), testResource)
+			if err != nil {
+				t.Fatalf("\n%s\nNewServicePrincipalToken(...): %s", tc.reason, err)
+			}
+			if err := tk.Refresh(); err != nil {
+				t.Fatalf("\n%s\nRefresh(): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(testAccessToken, tk.OAuthToken()); diff != "" {
+				t.Errorf("\n%s\nOAuthToken(): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff([]url.Values{tc.want.form}, got); diff != "" {
+				t.Errorf("\n%s\nToken request: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewMultiTenantServicePrincipalToken(t *testing.T) {
+	type want struct {
+		tokens int
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		creds  map[string]string
+		want   want
+	}{
+		"NoAuxiliaryTenants": {
+			reason: "Credentials without auxiliary tenants cannot be used for multi-tenant tokens.",
+			creds: map[string]string{
+				CredentialsKeyClientSecret: "cool-secret",
+			},
+			want: want{err: errors.New(errNoAuxiliaryTenants)},
+		},
+		"NoClientSecret": {
+			reason: "Only service principal secrets can be used for multi-tenant tokens.",
+			creds: map[string]string{
+				CredentialsKeyAuxiliaryTenantIDs: "other-tenant",
+			},
+			want: want{err: errors.New(errAuxiliaryNeedsSecret)},
+		},
+		"AuxiliaryTenants": {
+			reason: "A token should be acquired for the primary tenant and each auxiliary tenant.",
+			creds: map[string]string{
+				CredentialsKeyTenantID:           "cool-tenant",
+				CredentialsKeyClientID:           "cool-app",
+				CredentialsKeyClientSecret:       "cool-secret",
+				CredentialsKeyAuxiliaryTenantIDs: "other-tenant, another-tenant",
+			},
+			want: want{tokens: 3},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []url.Values{}
+			srv := tokenServer(&got)
+			defer srv.Close()
+
+			tc.creds[CredentialsKeyActiveDirectoryEndpointURL] = srv.URL
+			tk, err := NewMultiTenantServicePrincipalToken(tc.creds, testResource)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nNewMultiTenantServicePrincipalToken(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if err := tk.RefreshWithContext(context.Background()); err != nil {
+				t.Fatalf("\n%s\nRefreshWithContext(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.tokens, len(got)); diff != "" {
+				t.Errorf("\n%s\nToken requests: -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff([]string{testAccessToken, testAccessToken}, tk.AuxiliaryOAuthTokens()); diff != "" {
+				t.Errorf("\n%s\nAuxiliaryOAuthTokens(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFederatedTokenSecretMissingFile(t *testing.T) {
+	s := &federatedTokenSecret{path: filepath.Join(os.TempDir(), "does-not-exist")}
+	if err := s.SetAuthenticationValues(nil, &url.Values{}); err == nil {
+		t.Errorf("SetAuthenticationValues(...): expected an error when the token file does not exist")
+	}
+}
+
+// selfSignedCertificate returns a PEM encoded certificate and its RSA private
+// key in PKCS #1 and PKCS #8 form.
+func selfSignedCertificate(t *testing.T, cn string) (cert, pkcs1, pkcs8 *pem.Block) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pem.Block{Type: pemBlockTypeCertificate, Bytes: der},
+		&pem.Block{Type: pemBlockTypeRSAPrivateKey, Bytes: x509.MarshalPKCS1PrivateKey(key)},
+		&pem.Block{Type: pemBlockTypePKCS8PrivateKey, Bytes: p8}
+}
+
+func TestClientCertificate(t *testing.T) {
+	cert, pkcs1, pkcs8 := selfSignedCertificate(t, "cool-cert")
+	encrypted, err := x509.EncryptPEMBlock(rand.Reader, pkcs1.Type, pkcs1.Bytes, []byte("cool-password"), x509.PEMCipherAES256) // nolint:staticcheck
+	if err != nil {
+		t.Fatal(err)
+	}
+	pfx, err := ioutil.ReadFile(testPFXFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemOf := func(blocks ...*pem.Block) string {
+		out := []byte{}
+		for _, b := range blocks {
+			out = append(out, pem.EncodeToMemory(b)...)
+		}
+		return string(out)
+	}
+
+	type want struct {
+		cn  string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		creds  map[string]string
+		want   want
+	}{
+		"PEMWithPKCS1Key": {
+			reason: "A PEM certificate with a PKCS #1 key should be decoded.",
+			creds:  map[string]string{CredentialsKeyClientCertificate: pemOf(pkcs1, cert)},
+			want:   want{cn: "cool-cert"},
+		},
+		"PEMWithPKCS8Key": {
+			reason: "A PEM certificate with a PKCS #8 key should be decoded.",
+			creds:  map[string]string{CredentialsKeyClientCertificate: pemOf(cert, pkcs8)},
+			want:   want{cn: "cool-cert"},
+		},
+		"PEMWithEncryptedKey": {
+			reason: "An encrypted PEM key should be decrypted using the password.",
+			creds: map[string]string{
+				CredentialsKeyClientCertificate:         pemOf(cert, encrypted),
+				CredentialsKeyClientCertificatePassword: "cool-password",
+			},
+			want: want{cn: "cool-cert"},
+		},
+		"PEMWithoutKey": {
+			reason: "A PEM certificate without a private key cannot be used.",
+			creds:  map[string]string{CredentialsKeyClientCertificate: pemOf(cert)},
+			want:   want{err: errors.New(errNoPrivateKeyInPEM)},
+		},
+		"PEMWithoutCertificate": {
+			reason: "A PEM private key without a certificate cannot be used.",
+			creds:  map[string]string{CredentialsKeyClientCertificate: pemOf(pkcs8)},
+			want:   want{err: errors.New(errNoCertificateInPEM)},
+		},
+		"Base64PFX": {
+			reason: "A base64 encoded PFX archive should be decoded using the password.",
+			creds: map[string]string{
+				CredentialsKeyClientCertificate:         base64.StdEncoding.EncodeToString(pfx),
+				CredentialsKeyClientCertificatePassword: testPFXPassword,
+			},
+			want: want{cn: testPFXCommonName},
+		},
+		"PFXPath": {
+			reason: "A PFX archive should be read from the supplied path.",
+			creds: map[string]string{
+				CredentialsKeyClientCertificatePath:     testPFXFile,
+				CredentialsKeyClientCertificatePassword: testPFXPassword,
+			},
+			want: want{cn: testPFXCommonName},
+		},
+		"PFXWrongPassword": {
+			reason: "A PFX archive cannot be decoded with the wrong password.",
+			creds: map[string]string{
+				CredentialsKeyClientCertificatePath:     testPFXFile,
+				CredentialsKeyClientCertificatePassword: "boring-password",
+			},
+			want: want{err: errors.Wrap(errors.New("pkcs12: decryption password incorrect"), errDecodePFX)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, k, err := ClientCertificate(tc.creds)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nClientCertificate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.cn, c.Subject.CommonName); diff != "" {
+				t.Errorf("\n%s\nClientCertificate(...): -want common name, +got common name:\n%s", tc.reason, diff)
+			}
+			if k == nil {
+				t.Errorf("\n%s\nClientCertificate(...): no private key was returned", tc.reason)
+			}
+		})
+	}
+}
+
+func TestNewServicePrincipalTokenFromCertificate(t *testing.T) {
+	got := []url.Values{}
+	srv := tokenServer(&got)
+	defer srv.Close()
+
+	cert, key, _ := selfSignedCertificate(t, "cool-cert")
+	creds := map[string]string{
+		CredentialsKeyActiveDirectoryEndpointURL: srv.URL,
+		CredentialsKeyTenantID:                   "cool-tenant",
+		CredentialsKeyClientID:                   "cool-app",
+		CredentialsKeyClientCertificate:          string(append(pem.EncodeToMemory(cert), pem.EncodeToMemory(key)...)),
+	}
+	tk, err := NewServicePrincipalToken(creds, testResource)
+	if err != nil {
+		t.Fatalf("NewServicePrincipalToken(...): %s", err)
+	}
+	if err := tk.Refresh(); err != nil {
+		t.Fatalf("Refresh(): %s", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Refresh(): want 1 token request, got %d", len(got))
+	}
+	if diff := cmp.Diff(clientAssertionTypeJWTBearer, got[0].Get("client_assertion_type")); diff != "" {
+		t.Errorf("Token request: -want client_assertion_type, +got client_assertion_type:\n%s", diff)
+	}
+	if got[0].Get("client_assertion") == "" {
+		t.Errorf("Token request: the client assertion signed by the certificate is missing")
+	}
+}
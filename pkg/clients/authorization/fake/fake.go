@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-09-01/locks"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-09-01/locks/locksapi"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+var _ locksapi.ManagementLocksClientAPI = &MockManagementLocksClient{}
+
+// MockManagementLocksClient is a fake implementation of locks.ManagementLocksClient.
+type MockManagementLocksClient struct {
+	locksapi.ManagementLocksClientAPI
+
+	MockCreateOrUpdateByScope func(ctx context.Context, scope string, lockName string, parameters locks.ManagementLockObject) (result locks.ManagementLockObject, err error)
+	MockDeleteByScope         func(ctx context.Context, scope string, lockName string) (result autorest.Response, err error)
+	MockGetByScope            func(ctx context.Context, scope string, lockName string) (result locks.ManagementLockObject, err error)
+}
+
+// CreateOrUpdateByScope calls the MockManagementLocksClient's MockCreateOrUpdateByScope method.
+func (c *MockManagementLocksClient) CreateOrUpdateByScope(ctx context.Context, scope string, lockName string, parameters locks.ManagementLockObject) (result locks.ManagementLockObject, err error) {
+	return c.MockCreateOrUpdateByScope(ctx, scope, lockName, parameters)
+}
+
+// DeleteByScope calls the MockManagementLocksClient's MockDeleteByScope method.
+func (c *MockManagementLocksClient) DeleteByScope(ctx context.Context, scope string, lockName string) (result autorest.Response, err error) {
+	return c.MockDeleteByScope(ctx, scope, lockName)
+}
+
+// GetByScope calls the MockManagementLocksClient's MockGetByScope method.
+func (c *MockManagementLocksClient) GetByScope(ctx context.Context, scope string, lockName string) (result locks.ManagementLockObject, err error) {
+	return c.MockGetByScope(ctx, scope, lockName)
+}
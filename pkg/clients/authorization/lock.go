@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package authorization contains helpers for Azure management locks.
+package authorization
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-09-01/locks"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-09-01/locks/locksapi"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/provider-azure/apis/authorization/v1alpha3"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+const errNoScope = "a management lock must specify or reference either a resource ID or a resource group"
+
+// A LocksClient handles CRUD operations for Azure management locks.
+type LocksClient locksapi.ManagementLocksClientAPI
+
+// Scope returns the ID of the resource group or Azure resource that the
+// supplied management lock applies to. Azure resources take precedence over
+// resource groups.
+func Scope(subscriptionID string, p v1alpha3.ManagementLockParameters) (string, error) {
+	if id := azure.ToString(p.ResourceID); id != "" {
+		return id, nil
+	}
+	if p.ResourceGroupName != "" {
+		return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionID, p.ResourceGroupName), nil
+	}
+	return "", errors.New(errNoScope)
+}
+
+// NewManagementLockObject returns management lock creation parameters
+// suitable for use with the Azure API.
+func NewManagementLockObject(p v1alpha3.ManagementLockParameters) locks.ManagementLockObject {
+	return locks.ManagementLockObject{
+		ManagementLockProperties: &locks.ManagementLockProperties{
+			Level: locks.LockLevel(p.Level),
+			Notes: p.Notes,
+		},
+	}
+}
+
+// UpdateManagementLockObservation produces the observed state of the supplied
+// Azure management lock.
+func UpdateManagementLockObservation(o *v1alpha3.ManagementLockObservation, in locks.ManagementLockObject) {
+	o.ID = azure.ToString(in.ID)
+	o.Name = azure.ToString(in.Name)
+	o.Type = azure.ToString(in.Type)
+}
+
+// IsUpToDate returns true if the supplied Azure management lock matches the
+// supplied parameters.
+func IsUpToDate(p v1alpha3.ManagementLockParameters, in locks.ManagementLockObject) bool {
+	if in.ManagementLockProperties == nil {
+		return false
+	}
+	return string(in.Level) == string(p.Level) && azure.ToString(in.Notes) == azure.ToString(p.Notes)
+}
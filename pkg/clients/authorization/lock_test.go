@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-09-01/locks"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-azure/apis/authorization/v1alpha3"
+)
+
+func TestScope(t *testing.T) {
+	id := "/subscriptions/cool-sub/resourceGroups/cool-rg/providers/Microsoft.Network/virtualNetworks/cool-vnet"
+
+	type want struct {
+		scope string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha3.ManagementLockParameters
+		want   want
+	}{
+		"Resource": {
+			reason: "Locks with a resource ID should apply to that resource, even if they specify a resource group.",
+			p:      v1alpha3.ManagementLockParameters{ResourceID: to.StringPtr(id), ResourceGroupName: "cool-rg"},
+			want:   want{scope: id},
+		},
+		"ResourceGroup": {
+			reason: "Locks without a resource ID should apply to their resource group.",
+			p:      v1alpha3.ManagementLockParameters{ResourceGroupName: "cool-rg"},
+			want:   want{scope: "/subscriptions/cool-sub/resourceGroups/cool-rg"},
+		},
+		"NoScope": {
+			reason: "Locks with neither a resource ID nor a resource group should return an error.",
+			p:      v1alpha3.ManagementLockParameters{},
+			want:   want{err: errors.New(errNoScope)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Scope("cool-sub", tc.p)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nScope(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.scope, got); diff != "" {
+				t.Errorf("\n%s\nScope(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsUpToDate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		p      v1alpha3.ManagementLockParameters
+		in     locks.ManagementLockObject
+		want   bool
+	}{
+		"UpToDate": {
+			reason: "Locks whose level and notes match should be up to date.",
+			p:      v1alpha3.ManagementLockParameters{Level: v1alpha3.LockLevelCanNotDelete, Notes: to.StringPtr("production")},
+			in:     NewManagementLockObject(v1alpha3.ManagementLockParameters{Level: v1alpha3.LockLevelCanNotDelete, Notes: to.StringPtr("production")}),
+			want:   true,
+		},
+		"LevelChanged": {
+			reason: "Locks whose level differs should not be up to date.",
+			p:      v1alpha3.ManagementLockParameters{Level: v1alpha3.LockLevelReadOnly},
+			in:     NewManagementLockObject(v1alpha3.ManagementLockParameters{Level: v1alpha3.LockLevelCanNotDelete}),
+			want:   false,
+		},
+		"NotesChanged": {
+			reason: "Locks whose notes differ should not be up to date.",
+			p:      v1alpha3.ManagementLockParameters{Level: v1alpha3.LockLevelCanNotDelete},
+			in:     NewManagementLockObject(v1alpha3.ManagementLockParameters{Level: v1alpha3.LockLevelCanNotDelete, Notes: to.StringPtr("production")}),
+			want:   false,
+		},
+		"NoProperties": {
+			reason: "Locks without properties should not be up to date.",
+			p:      v1alpha3.ManagementLockParameters{Level: v1alpha3.LockLevelCanNotDelete},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := IsUpToDate(tc.p, tc.in)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsUpToDate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
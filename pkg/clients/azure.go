@@ -20,21 +20,28 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-azure/apis/v1alpha3"
 	"github.com/crossplane/provider-azure/apis/v1beta1"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
 )
 
 const (
@@ -44,6 +51,14 @@ const (
 	// that indicates the operation is still ongoing.
 	AsyncOperationStatusInProgress = "InProgress"
 	asyncOperationPollingMethod    = "AsyncOperation"
+
+	// aadErrorSecretExpired is the Azure AD error code returned when a token
+	// is requested using an expired client secret.
+	aadErrorSecretExpired = "AADSTS7000222"
+
+	// unversionedCredentialsTTL is how long credentials that are not read
+	// from a Kubernetes object are cached.
+	unversionedCredentialsTTL = 5 * time.Minute
 )
 
 // Error strings.
@@ -54,6 +69,10 @@ const (
 	errNeitherPCNorPGiven        = "neither providerConfigRef nor providerRef was supplied"
 	errUnmarshalCredentialSecret = "cannot unmarshal the data in credentials secret"
 	errGetAuthorizer             = "cannot get authorizer from client credentials config"
+	errGetCredentials            = "cannot get credentials"
+	errGetMSIEndpoint            = "cannot get managed identity endpoint"
+	errGetEnvironment            = "cannot get Azure environment"
+	errSubscriptionNotAllowedFmt = "subscription %q is not allowed by ProviderConfig %q"
 )
 
 // A FieldOption determines how common Go types are translated to the types
@@ -73,6 +92,9 @@ const (
 const (
 	CredentialsKeyClientID                       = "clientId"
 	CredentialsKeyClientSecret                   = "clientSecret"
+	CredentialsKeyClientCertificate              = "clientCertificate"
+	CredentialsKeyClientCertificatePath          = "clientCertificatePath"
+	CredentialsKeyClientCertificatePassword      = "clientCertificatePassword"
 	CredentialsKeyTenantID                       = "tenantId"
 	CredentialsKeySubscriptionID                 = "subscriptionId"
 	CredentialsKeyActiveDirectoryEndpointURL     = "activeDirectoryEndpointUrl"
@@ -85,68 +107,389 @@ const (
 
 // GetAuthInfo figures out how to connect to Azure API and returns the necessary
 // information to be used for controllers to construct their specific clients.
+// Credentials and authorizers are cached until the referenced ProviderConfig
+// or Provider, or its credentials secret, changes. Credentials read from the
+// environment or the filesystem are also read again periodically.
+//
+// A ThrottledError is returned if Azure Resource Manager is throttling the
+// subscription the managed resource lives in, rather than making requests
+// that are sure to be rejected.
 func GetAuthInfo(ctx context.Context, c client.Client, mg resource.Managed) (content map[string]string, authorizer autorest.Authorizer, err error) {
+	return getAuthInfo(ctx, c, mg, (*AuthorizerCacheEntry).ResourceManagerAuthorizer)
+}
+
+// GetMultiTenantAuthInfo is like GetAuthInfo, except that its Azure Resource
+// Manager authorizer also supplies tokens for the auxiliary tenants of the
+// ProviderConfig referenced by the supplied managed resource, if it has any.
+// Clients that may operate on resources in other tenants, e.g. to link a
+// subnet that belongs to another tenant, use it in place of GetAuthInfo. The
+// subscription it returns honours the managed resource's subscription ID
+// annotation, as that of GetAuthInfo does.
+func GetMultiTenantAuthInfo(ctx context.Context, c client.Client, mg resource.Managed) (content map[string]string, authorizer autorest.Authorizer, err error) {
+	return getAuthInfo(ctx, c, mg, multiTenantAuthorizer)
+}
+
+// multiTenantAuthorizer returns the multi-tenant authorizer of the supplied
+// entry if its credentials have auxiliary tenants, and its Azure Resource
+// Manager authorizer otherwise.
+func multiTenantAuthorizer(e *AuthorizerCacheEntry) (autorest.Authorizer, error) {
+	if len(AuxiliaryTenantIDs(e.creds)) == 0 {
+		return e.ResourceManagerAuthorizer()
+	}
+	return e.MultiTenantAuthorizer()
+}
+
+// An authorizerFn returns an Azure Resource Manager authorizer derived from
+// the credentials of the supplied entry.
+type authorizerFn func(e *AuthorizerCacheEntry) (autorest.Authorizer, error)
+
+func getAuthInfo(ctx context.Context, c client.Client, mg resource.Managed, fn authorizerFn) (content map[string]string, authorizer autorest.Authorizer, err error) {
 	switch {
 	case mg.GetProviderConfigReference() != nil:
-		return UseProviderConfig(ctx, c, mg)
+		content, authorizer, err = useProviderConfig(ctx, c, mg, fn)
 	case mg.GetProviderReference() != nil:
-		return UseProvider(ctx, c, mg)
+		content, authorizer, err = useProvider(ctx, c, mg, fn)
 	default:
 		return nil, nil, errors.New(errNeitherPCNorPGiven)
 	}
+	if err != nil {
+		return nil, nil, err
+	}
+	sub := content[CredentialsKeySubscriptionID]
+	throttle.Track(ManagedKeyOf(mg), sub)
+	if d := throttle.RetryAfter(sub); d > 0 {
+		return nil, nil, &ThrottledError{Subscription: sub, RetryAfter: d}
+	}
+	return content, authorizer, nil
+}
+
+// ConfigureClient configures the supplied Azure SDK client to use the supplied
+// authorizer, to identify itself as Crossplane, to pace and observe its
+// requests according to Azure Resource Manager throttling, to record them as
+// metrics and spans, to make them conditional per WithIfMatch, to serve them
+// from the Azure Resource Graph cache when it is running, and to record rather
+// than send them per WithDryRun.
+func ConfigureClient(c *autorest.Client, a autorest.Authorizer) {
+	c.Authorizer = a
+	c.Sender = autorest.CreateSender(tracing.SendDecorator(), apiMetrics.SendDecorator(), throttle.SendDecorator(), IfMatchSendDecorator(), graphCache.SendDecorator(a), DryRunSendDecorator(a))
+	_ = c.AddToUserAgent(UserAgent)
+}
+
+// GetAuthorizer returns an authorizer for the supplied resource, e.g. the AAD
+// graph, using the credentials of the ProviderConfig or Provider referenced by
+// the supplied managed resource.
+func GetAuthorizer(ctx context.Context, c client.Client, mg resource.Managed, resourceID string) (autorest.Authorizer, error) {
+	e, err := getAuthorizers(ctx, c, mg)
+	if err != nil {
+		return nil, err
+	}
+	return e.Authorizer(resourceID)
+}
+
+func getAuthorizers(ctx context.Context, c client.Client, mg resource.Managed) (*AuthorizerCacheEntry, error) {
+	switch {
+	case mg.GetProviderConfigReference() != nil:
+		return providerConfigAuthorizers(ctx, c, mg)
+	case mg.GetProviderReference() != nil:
+		return providerAuthorizers(ctx, c, mg)
+	default:
+		return nil, errors.New(errNeitherPCNorPGiven)
+	}
 }
 
 // UseProvider to return the necessary information to construct an Azure client.
 // Deprecated: Use UseProviderConfig
 func UseProvider(ctx context.Context, c client.Client, mg resource.Managed) (content map[string]string, authorizer autorest.Authorizer, err error) {
+	return useProvider(ctx, c, mg, (*AuthorizerCacheEntry).ResourceManagerAuthorizer)
+}
+
+func useProvider(ctx context.Context, c client.Client, mg resource.Managed, fn authorizerFn) (content map[string]string, authorizer autorest.Authorizer, err error) {
+	e, err := providerAuthorizers(ctx, c, mg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return authInfo(e, fn)
+}
+
+// UseProviderConfig to return the necessary information to construct an Azure
+// client.
+// The subscription of the ProviderConfig is overridden by that of the
+// supplied managed resource's subscription ID annotation, if any.
+func UseProviderConfig(ctx context.Context, c client.Client, mg resource.Managed) (content map[string]string, authorizer autorest.Authorizer, err error) {
+	return useProviderConfig(ctx, c, mg, (*AuthorizerCacheEntry).ResourceManagerAuthorizer)
+}
+
+func useProviderConfig(ctx context.Context, c client.Client, mg resource.Managed, fn authorizerFn) (content map[string]string, authorizer autorest.Authorizer, err error) {
+	pc, err := getProviderConfig(ctx, c, mg)
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := ProviderConfigAuthorizers(ctx, c, pc)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, authorizer, err = authInfo(e, fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	sub, err := SubscriptionID(mg, pc, content[CredentialsKeySubscriptionID])
+	if err != nil {
+		return nil, nil, err
+	}
+	content[CredentialsKeySubscriptionID] = sub
+	return content, authorizer, nil
+}
+
+// SubscriptionID returns the subscription ID the supplied managed resource
+// should use. This is the supplied subscription ID of its ProviderConfig,
+// unless the managed resource overrides it using the subscription ID
+// annotation. Overrides must be allowed by the ProviderConfig.
+func SubscriptionID(mg resource.Managed, pc *v1beta1.ProviderConfig, sub string) (string, error) {
+	override := mg.GetAnnotations()[v1beta1.AnnotationKeySubscriptionID]
+	if override == "" || strings.EqualFold(override, sub) {
+		return sub, nil
+	}
+	for _, allowed := range pc.Spec.AllowedSubscriptionIDs {
+		if strings.EqualFold(override, allowed) {
+			return override, nil
+		}
+	}
+	return "", errors.Errorf(errSubscriptionNotAllowedFmt, override, pc.GetName())
+}
+
+// GetSubscriptionID returns the subscription ID the supplied managed resource
+// makes requests to, per the credentials of the ProviderConfig or Provider it
+// references and its subscription ID annotation. Unlike GetAuthInfo it does
+// not track the managed resource's usage of its ProviderConfig, and so may be
+// called when the managed resource is not being reconciled.
+func GetSubscriptionID(ctx context.Context, c client.Client, mg resource.Managed) (string, error) {
+	switch {
+	case mg.GetProviderConfigReference() != nil:
+		pc := &v1beta1.ProviderConfig{}
+		if err := c.Get(ctx, types.NamespacedName{Name: mg.GetProviderConfigReference().Name}, pc); err != nil {
+			return "", errors.Wrap(err, errGetProviderConfig)
+		}
+		e, err := ProviderConfigAuthorizers(ctx, c, pc)
+		if err != nil {
+			return "", err
+		}
+		return SubscriptionID(mg, pc, e.Credentials()[CredentialsKeySubscriptionID])
+	case mg.GetProviderReference() != nil:
+		e, err := providerAuthorizers(ctx, c, mg)
+		if err != nil {
+			return "", err
+		}
+		return e.Credentials()[CredentialsKeySubscriptionID], nil
+	default:
+		return "", errors.New(errNeitherPCNorPGiven)
+	}
+}
+
+func authInfo(e *AuthorizerCacheEntry, fn authorizerFn) (map[string]string, autorest.Authorizer, error) {
+	a, err := fn(e)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e.Credentials(), a, nil
+}
+
+func providerAuthorizers(ctx context.Context, c client.Client, mg resource.Managed) (*AuthorizerCacheEntry, error) {
 	p := &v1alpha3.Provider{}
 	if err := c.Get(ctx, types.NamespacedName{Name: mg.GetProviderReference().Name}, p); err != nil {
-		return nil, nil, errors.Wrap(err, errGetProvider)
+		return nil, errors.Wrap(err, errGetProvider)
 	}
 
 	ref := p.Spec.CredentialsSecretRef
 	s := &corev1.Secret{}
 	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}, s); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	m := map[string]string{}
-	if err := json.Unmarshal(s.Data[ref.Key], &m); err != nil {
-		return nil, nil, errors.Wrap(err, errUnmarshalCredentialSecret)
-	}
-	cfg := auth.NewClientCredentialsConfig(m[CredentialsKeyClientID], m[CredentialsKeyClientSecret], m[CredentialsKeyTenantID])
-	cfg.AADEndpoint = m[CredentialsKeyActiveDirectoryEndpointURL]
-	cfg.Resource = m[CredentialsKeyResourceManagerEndpointURL]
+	return authorizers.Get("Provider/"+p.GetName(), cacheVersion(p.GetResourceVersion(), s.GetResourceVersion()), 0, func() (map[string]string, error) {
+		m := map[string]string{}
+		if err := json.Unmarshal(s.Data[ref.Key], &m); err != nil {
+			return nil, errors.Wrap(err, errUnmarshalCredentialSecret)
+		}
+		SetDefaultEndpoints(m)
+		return m, nil
+	})
+}
 
-	a, err := cfg.Authorizer()
-	return m, a, errors.Wrap(err, errGetAuthorizer)
+func providerConfigAuthorizers(ctx context.Context, c client.Client, mg resource.Managed) (*AuthorizerCacheEntry, error) {
+	pc, err := getProviderConfig(ctx, c, mg)
+	if err != nil {
+		return nil, err
+	}
+	return ProviderConfigAuthorizers(ctx, c, pc)
 }
 
-// UseProviderConfig to return the necessary information to construct an Azure
-// client.
-func UseProviderConfig(ctx context.Context, c client.Client, mg resource.Managed) (content map[string]string, authorizer autorest.Authorizer, err error) {
+// getProviderConfig tracks the supplied managed resource's usage of its
+// ProviderConfig, and returns it.
+func getProviderConfig(ctx context.Context, c client.Client, mg resource.Managed) (*v1beta1.ProviderConfig, error) {
 	pc := &v1beta1.ProviderConfig{}
 	t := resource.NewProviderConfigUsageTracker(c, &v1beta1.ProviderConfigUsage{})
 	if err := t.Track(ctx, mg); err != nil {
-		return nil, nil, errors.Wrap(err, errTrackProviderConfigUsage)
+		return nil, errors.Wrap(err, errTrackProviderConfigUsage)
 	}
 	if err := c.Get(ctx, types.NamespacedName{Name: mg.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, nil, errors.Wrap(err, errGetProviderConfig)
+		if kerrors.IsNotFound(err) {
+			authorizers.Delete(providerConfigKey(mg.GetProviderConfigReference().Name))
+		}
+		return nil, errors.Wrap(err, errGetProviderConfig)
 	}
+	return pc, nil
+}
 
-	data, err := resource.CommonCredentialExtractor(ctx, pc.Spec.Credentials.Source, c, pc.Spec.Credentials.CommonCredentialSelectors)
-	if err != nil {
-		return nil, nil, errors.Wrap(err, "cannot get credentials")
+func providerConfigKey(name string) string {
+	return "ProviderConfig/" + name
+}
+
+// ProviderConfigAuthorizers returns the cached credentials and authorizers of
+// the supplied ProviderConfig.
+func ProviderConfigAuthorizers(ctx context.Context, c client.Client, pc *v1beta1.ProviderConfig) (*AuthorizerCacheEntry, error) {
+	// Only credentials read from a secret can be versioned. We don't return
+	// errors getting the secret here; they're returned when the credentials
+	// are extracted, which always happens when the version is unknown.
+	// Credentials read from the environment or a file may change at any
+	// time, e.g. when a file is remounted, so they are only cached briefly.
+	v := []string{pc.GetResourceVersion()}
+	var ttl time.Duration
+	switch pc.Spec.Credentials.Source { // nolint:exhaustive
+	case xpv1.CredentialsSourceSecret:
+		if ref := pc.Spec.Credentials.SecretRef; ref != nil {
+			s := &corev1.Secret{}
+			_ = c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s)
+			v = append(v, s.GetResourceVersion())
+		}
+	case xpv1.CredentialsSourceEnvironment, xpv1.CredentialsSourceFilesystem:
+		ttl = unversionedCredentialsTTL
 	}
+	return authorizers.Get(providerConfigKey(pc.GetName()), cacheVersion(v...), ttl, func() (map[string]string, error) {
+		return ProviderConfigCredentials(ctx, c, pc)
+	})
+}
+
+// cacheVersion returns the version of credentials read from objects at the
+// supplied resource versions. It returns an empty version if any resource
+// version is unknown.
+func cacheVersion(rv ...string) string {
+	for _, v := range rv {
+		if v == "" {
+			return ""
+		}
+	}
+	return strings.Join(rv, "/")
+}
+
+// ProviderConfigCredentials returns the credentials described by the supplied
+// ProviderConfig, using the keys of the Azure SDK credentials file format.
+// Credentials of identity based sources are not read from anywhere; instead
+// the returned map describes how to acquire a token for the identity. The
+// endpoints are those of the ProviderConfig's environment if it has one, and
+// otherwise default to those of the Azure public cloud.
+func ProviderConfigCredentials(ctx context.Context, c client.Client, pc *v1beta1.ProviderConfig) (map[string]string, error) {
 	m := map[string]string{}
-	if err := json.Unmarshal(data, &m); err != nil {
-		return nil, nil, errors.Wrap(err, errUnmarshalCredentialSecret)
+	src := pc.Spec.Credentials.Source
+	switch src { // nolint:exhaustive
+	case xpv1.CredentialsSourceInjectedIdentity:
+		ep, err := adal.GetMSIEndpoint()
+		if err != nil {
+			return nil, errors.Wrap(err, errGetMSIEndpoint)
+		}
+		m[CredentialsKeyMSIEndpoint] = ep
+		m[CredentialsKeyClientID] = ToString(pc.Spec.Credentials.ClientID)
+	case v1beta1.CredentialsSourceWorkloadIdentity:
+		m[CredentialsKeyClientID] = stringOrEnv(pc.Spec.Credentials.ClientID, envClientID)
+		m[CredentialsKeyTenantID] = stringOrEnv(pc.Spec.Credentials.TenantID, envTenantID)
+		m[CredentialsKeyFederatedTokenFile] = stringOrEnv(pc.Spec.Credentials.TokenFilePath, envFederatedTokenFile)
+		switch {
+		case m[CredentialsKeyClientID] == "":
+			return nil, errors.Errorf(errNoClientIDFmt, src)
+		case m[CredentialsKeyTenantID] == "":
+			return nil, errors.Errorf(errNoTenantIDFmt, src)
+		case m[CredentialsKeyFederatedTokenFile] == "":
+			return nil, errors.New(errNoFederatedTokenFile)
+		}
+	default:
+		data, err := resource.CommonCredentialExtractor(ctx, src, c, pc.Spec.Credentials.CommonCredentialSelectors)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetCredentials)
+		}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, errors.Wrap(err, errUnmarshalCredentialSecret)
+		}
 	}
-	cfg := auth.NewClientCredentialsConfig(m[CredentialsKeyClientID], m[CredentialsKeyClientSecret], m[CredentialsKeyTenantID])
-	cfg.AADEndpoint = m[CredentialsKeyActiveDirectoryEndpointURL]
-	cfg.Resource = m[CredentialsKeyResourceManagerEndpointURL]
+	if pc.Spec.SubscriptionID != nil {
+		m[CredentialsKeySubscriptionID] = *pc.Spec.SubscriptionID
+	}
+	if len(pc.Spec.AuxiliaryTenantIDs) > 0 {
+		m[CredentialsKeyAuxiliaryTenantIDs] = strings.Join(pc.Spec.AuxiliaryTenantIDs, ",")
+	}
+	if pc.Spec.Environment != nil {
+		env, err := NewEnvironment(pc.Spec.Environment)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range EnvironmentCredentials(env) {
+			m[k] = v
+		}
+	}
+	SetDefaultEndpoints(m)
+	if m[CredentialsKeySubscriptionID] == "" && (src == xpv1.CredentialsSourceInjectedIdentity || src == v1beta1.CredentialsSourceWorkloadIdentity) {
+		return nil, errors.Errorf(errNoSubscriptionIDFmt, src)
+	}
+	return m, nil
+}
 
-	a, err := cfg.Authorizer()
-	return m, a, errors.Wrap(err, errGetAuthorizer)
+// NewEnvironment returns the Azure environment described by the supplied
+// ProviderConfig environment. The endpoints of custom clouds are discovered by
+// fetching their metadata document.
+func NewEnvironment(e *v1beta1.Environment) (azure.Environment, error) {
+	switch {
+	case e == nil:
+		return azure.PublicCloud, nil
+	case e.MetadataURL != nil:
+		env, err := azure.EnvironmentFromURL(strings.TrimSuffix(*e.MetadataURL, "/"))
+		return env, errors.Wrap(err, errGetEnvironment)
+	case e.Name != nil:
+		env, err := azure.EnvironmentFromName(string(*e.Name))
+		return env, errors.Wrap(err, errGetEnvironment)
+	default:
+		return azure.PublicCloud, nil
+	}
+}
+
+// EnvironmentCredentials returns the credentials keys describing the
+// endpoints of the supplied Azure environment.
+func EnvironmentCredentials(env azure.Environment) map[string]string {
+	return map[string]string{
+		CredentialsKeyActiveDirectoryEndpointURL:     env.ActiveDirectoryEndpoint,
+		CredentialsKeyResourceManagerEndpointURL:     env.ResourceManagerEndpoint,
+		CredentialsKeyActiveDirectoryGraphResourceID: env.GraphEndpoint,
+		CredentialsKeyStorageEndpointSuffix:          env.StorageEndpointSuffix,
+		CredentialsKeyTokenAudience:                  env.TokenAudience,
+	}
+}
+
+// SetDefaultEndpoints sets any endpoints missing from the supplied credentials
+// to those of the Azure public cloud. The token audience is never defaulted;
+// tokens are requested for the Azure Resource Manager endpoint when it is
+// omitted, which is what clouds other than Azure Stack expect.
+func SetDefaultEndpoints(creds map[string]string) {
+	for k, v := range EnvironmentCredentials(azure.PublicCloud) {
+		if k == CredentialsKeyTokenAudience {
+			continue
+		}
+		if creds[k] == "" {
+			creds[k] = v
+		}
+	}
+}
+
+func stringOrEnv(s *string, env string) string {
+	if s != nil && *s != "" {
+		return *s
+	}
+	return os.Getenv(env)
 }
 
 // Client struct that represents the information needed to connect to the Azure services as a client
@@ -168,6 +511,15 @@ type Credentials struct {
 	ActiveDirectoryGraphResourceID string `json:"activeDirectoryGraphResourceId"`
 }
 
+// BaseURI returns the Azure Resource Manager endpoint of the credentials,
+// defaulting to that of the Azure public cloud.
+func (c Credentials) BaseURI() string {
+	if c.ResourceManagerEndpointURL == "" {
+		return azure.PublicCloud.ResourceManagerEndpoint
+	}
+	return c.ResourceManagerEndpointURL
+}
+
 // NewClient returns a client that can be used to connect to Azure services
 // using the supplied JSON credentials.
 func NewClient(credentials []byte) (*Client, error) {
@@ -194,28 +546,60 @@ func NewClient(credentials []byte) (*Client, error) {
 			ClientSecret:                   creds.ClientSecret,
 			TenantID:                       creds.TenantID,
 			ActiveDirectoryEndpointURL:     creds.ActiveDirectoryEndpointURL,
+			ResourceManagerEndpointURL:     creds.ResourceManagerEndpointURL,
 			ActiveDirectoryGraphResourceID: creds.ActiveDirectoryGraphResourceID,
 		},
 	}, nil
 }
 
 // ValidateClient verifies if the given client is valid by testing if it can make an Azure service API call
-// TODO: is there a better way to validate the Azure client?
 func ValidateClient(client *Client) error {
-	groupsClient := resources.NewGroupsClient(client.SubscriptionID)
-	groupsClient.Authorizer = client.Authorizer
-	groupsClient.AddToUserAgent(UserAgent)
+	return ValidateAuthorizer(context.TODO(), client.BaseURI(), client.SubscriptionID, client.Authorizer)
+}
 
-	_, err := groupsClient.ListComplete(context.TODO(), "", nil)
+// ValidateAuthorizer verifies that the supplied authorizer can acquire a token
+// and read the supplied subscription, by listing at most one of its resource
+// groups.
+func ValidateAuthorizer(ctx context.Context, baseURI, subscriptionID string, a autorest.Authorizer) error {
+	groupsClient := resources.NewGroupsClientWithBaseURI(baseURI, subscriptionID)
+	ConfigureClient(&groupsClient.Client, a)
+
+	_, err := groupsClient.List(ctx, "", to.Int32Ptr(1))
 	return err
 }
 
+// NewAsyncOperation returns an AsyncOperation that tracks the supplied
+// long-running operation, which was started by a request with the supplied
+// HTTP method.
+func NewAsyncOperation(method string, f azure.Future) v1alpha3.AsyncOperation {
+	as := v1alpha3.AsyncOperation{
+		Method:     method,
+		PollingURL: f.PollingURL(),
+	}
+	if pm := f.PollingMethod(); pm != azure.PollingUnknown {
+		as.PollingMethod = string(pm)
+	}
+	return as
+}
+
+// IsCreating returns true if the supplied operation is a creation that is
+// still in progress. Azure returns NotFound for GET calls on many resources
+// until their creation completes, so a resource that is being created should
+// be considered to exist in order to avoid creating it again.
+func IsCreating(as v1alpha3.AsyncOperation) bool {
+	return as.Method == http.MethodPut && as.Status == AsyncOperationStatusInProgress
+}
+
 // FetchAsyncOperation updates the given operation object with the most up-to-date
 // status retrieved from Azure API.
 func FetchAsyncOperation(ctx context.Context, client autorest.Sender, as *v1alpha3.AsyncOperation) error {
 	if as == nil || as.PollingURL == "" || as.Method == "" {
 		return nil
 	}
+	pm := asyncOperationPollingMethod
+	if as.PollingMethod != "" {
+		pm = as.PollingMethod
+	}
 	// NOTE(muvaf):There is NewFutureFromResponse method to construct Future
 	// object but that requires http.Request object. Even though we construct a
 	// fake http.Request object, the poll operation makes decisions based on the
@@ -223,7 +607,7 @@ func FetchAsyncOperation(ctx context.Context, client autorest.Sender, as *v1alph
 	// information and it's safer to cover all types of pollingTrackedBase objects.
 	futureJSON, err := json.Marshal(map[string]string{
 		"method":        as.Method,
-		"pollingMethod": asyncOperationPollingMethod,
+		"pollingMethod": pm,
 		"pollingURI":    as.PollingURL,
 	})
 	if err != nil {
@@ -262,6 +646,32 @@ func IsNotFound(err error) bool {
 	return statusCode == http.StatusNotFound
 }
 
+// IsUnauthorized returns a value indicating whether the given error represents
+// that Azure rejected the supplied credentials, either when acquiring a token
+// or when using it.
+func IsUnauthorized(err error) bool {
+	detailedError, ok := err.(autorest.DetailedError)
+	if !ok {
+		_, ok := err.(adal.TokenRefreshError)
+		return ok
+	}
+	if _, ok := detailedError.Original.(adal.TokenRefreshError); ok {
+		return true
+	}
+	statusCode, ok := detailedError.StatusCode.(int)
+	if !ok {
+		return false
+	}
+	return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// IsCredentialsExpired returns a value indicating whether the given error
+// represents that Azure AD refused to issue a token because the supplied
+// client secret has expired.
+func IsCredentialsExpired(err error) bool {
+	return IsUnauthorized(err) && strings.Contains(err.Error(), aadErrorSecretExpired)
+}
+
 // ToStringPtr converts the supplied string for use with the Azure Go SDK.
 func ToStringPtr(s string, o ...FieldOption) *string {
 	for _, fo := range o {
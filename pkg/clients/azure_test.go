@@ -21,17 +21,30 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/google/go-cmp/cmp"
 	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane/provider-azure/apis/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1beta1"
 )
 
 const (
@@ -129,6 +142,30 @@ func TestFetchAsyncOperation(t *testing.T) {
 				},
 			},
 		},
+		"LocationSucceeded": {
+			args: args{
+				as: &v1alpha3.AsyncOperation{
+					Method:        http.MethodDelete,
+					PollingURL:    pollingURL,
+					PollingMethod: string(azure.PollingLocation),
+				},
+				sender: autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						Request:    req,
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(strings.NewReader("")),
+					}, nil
+				}),
+			},
+			want: want{
+				op: &v1alpha3.AsyncOperation{
+					Method:        http.MethodDelete,
+					PollingURL:    pollingURL,
+					PollingMethod: string(azure.PollingLocation),
+					Status:        "Succeeded",
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -145,6 +182,571 @@ func TestFetchAsyncOperation(t *testing.T) {
 
 }
 
+func TestNewAsyncOperation(t *testing.T) {
+	f := azure.Future{}
+	if err := f.UnmarshalJSON([]byte(`{"method": "PUT", "pollingMethod": "Location", "pollingURI": "https://crossplane.io"}`)); err != nil {
+		t.Fatalf("UnmarshalJSON(...): %s", err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		method string
+		f      azure.Future
+		want   v1alpha3.AsyncOperation
+	}{
+		"NoOperation": {
+			reason: "A future that is not tracking an operation should produce an operation without a polling URL.",
+			method: http.MethodDelete,
+			want:   v1alpha3.AsyncOperation{Method: http.MethodDelete},
+		},
+		"Operation": {
+			reason: "The polling URL and method of the future should be recorded.",
+			method: http.MethodPut,
+			f:      f,
+			want: v1alpha3.AsyncOperation{
+				Method:        http.MethodPut,
+				PollingURL:    "https://crossplane.io",
+				PollingMethod: string(azure.PollingLocation),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := NewAsyncOperation(tc.method, tc.f)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nNewAsyncOperation(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsCreating(t *testing.T) {
+	cases := map[string]struct {
+		as   v1alpha3.AsyncOperation
+		want bool
+	}{
+		"NoOperation": {
+			want: false,
+		},
+		"CreationInProgress": {
+			as:   v1alpha3.AsyncOperation{Method: http.MethodPut, Status: AsyncOperationStatusInProgress},
+			want: true,
+		},
+		"CreationSucceeded": {
+			as:   v1alpha3.AsyncOperation{Method: http.MethodPut, Status: "Succeeded"},
+			want: false,
+		},
+		"DeletionInProgress": {
+			as:   v1alpha3.AsyncOperation{Method: http.MethodDelete, Status: AsyncOperationStatusInProgress},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, IsCreating(tc.as)); diff != "" {
+				t.Errorf("IsCreating(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProviderConfigCredentials(t *testing.T) {
+	errBoom := errors.New("boom")
+	sub := "cool-subscription"
+	clientID := "cool-client"
+	tenantID := "cool-tenant"
+	tokenFile := "/var/run/secrets/azure/tokens/azure-identity-token"
+	msi, _ := adal.GetMSIEndpoint()
+	usgov := v1beta1.EnvironmentAzureUSGovernmentCloud
+	empty := ""
+
+	stack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"graphEndpoint": "https://graph.local.azurestack.external/",
+			"authentication": {
+				"loginEndpoint": "https://adfs.local.azurestack.external/adfs",
+				"audiences": ["https://management.adfs.azurestack.local/cool-tenant"]
+			}
+		}`))
+	}))
+	defer stack.Close()
+
+	type args struct {
+		c  client.Client
+		pc *v1beta1.ProviderConfig
+	}
+	type want struct {
+		creds map[string]string
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SecretWithSubscriptionOverride": {
+			reason: "Credentials should be read from the secret, with the subscription taken from the ProviderConfig.",
+			args: args{
+				c: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					s := obj.(*corev1.Secret)
+					s.Data = map[string][]byte{"creds": []byte(`{"clientId": "cool-client", "subscriptionId": "boring-subscription"}`)}
+					return nil
+				}},
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{Key: "creds"},
+						},
+					},
+					SubscriptionID: &sub,
+				}},
+			},
+			want: want{creds: map[string]string{
+				CredentialsKeyActiveDirectoryEndpointURL:     azure.PublicCloud.ActiveDirectoryEndpoint,
+				CredentialsKeyResourceManagerEndpointURL:     azure.PublicCloud.ResourceManagerEndpoint,
+				CredentialsKeyActiveDirectoryGraphResourceID: azure.PublicCloud.GraphEndpoint,
+				CredentialsKeyStorageEndpointSuffix:          azure.PublicCloud.StorageEndpointSuffix,
+				CredentialsKeyClientID:                       clientID,
+				CredentialsKeySubscriptionID:                 sub,
+			}},
+		},
+		"SecretWithAuxiliaryTenants": {
+			reason: "The auxiliary tenants of the ProviderConfig should be added to the credentials.",
+			args: args{
+				c: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					s := obj.(*corev1.Secret)
+					s.Data = map[string][]byte{"creds": []byte(`{"clientId": "cool-client", "subscriptionId": "cool-subscription"}`)}
+					return nil
+				}},
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{Key: "creds"},
+						},
+					},
+					AuxiliaryTenantIDs: []string{"other-tenant", "another-tenant"},
+				}},
+			},
+			want: want{creds: map[string]string{
+				CredentialsKeyActiveDirectoryEndpointURL:     azure.PublicCloud.ActiveDirectoryEndpoint,
+				CredentialsKeyResourceManagerEndpointURL:     azure.PublicCloud.ResourceManagerEndpoint,
+				CredentialsKeyActiveDirectoryGraphResourceID: azure.PublicCloud.GraphEndpoint,
+				CredentialsKeyStorageEndpointSuffix:          azure.PublicCloud.StorageEndpointSuffix,
+				CredentialsKeyClientID:                       clientID,
+				CredentialsKeySubscriptionID:                 sub,
+				CredentialsKeyAuxiliaryTenantIDs:             "other-tenant,another-tenant",
+			}},
+		},
+		"SecretWithEndpoints": {
+			reason: "Endpoints supplied by the secret should be preserved when the ProviderConfig has no environment.",
+			args: args{
+				c: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					s := obj.(*corev1.Secret)
+					s.Data = map[string][]byte{"creds": []byte(`{"subscriptionId": "cool-subscription", "resourceManagerEndpointUrl": "https://management.chinacloudapi.cn/"}`)}
+					return nil
+				}},
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{Key: "creds"},
+						},
+					},
+				}},
+			},
+			want: want{creds: map[string]string{
+				CredentialsKeyActiveDirectoryEndpointURL:     azure.PublicCloud.ActiveDirectoryEndpoint,
+				CredentialsKeyResourceManagerEndpointURL:     azure.ChinaCloud.ResourceManagerEndpoint,
+				CredentialsKeyActiveDirectoryGraphResourceID: azure.PublicCloud.GraphEndpoint,
+				CredentialsKeyStorageEndpointSuffix:          azure.PublicCloud.StorageEndpointSuffix,
+				CredentialsKeySubscriptionID:                 sub,
+			}},
+		},
+		"NamedEnvironment": {
+			reason: "The endpoints of a named environment should take precedence over those of the secret.",
+			args: args{
+				c: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					s := obj.(*corev1.Secret)
+					s.Data = map[string][]byte{"creds": []byte(`{"subscriptionId": "cool-subscription", "resourceManagerEndpointUrl": "https://example.org/"}`)}
+					return nil
+				}},
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{Key: "creds"},
+						},
+					},
+					Environment: &v1beta1.Environment{Name: &usgov},
+				}},
+			},
+			want: want{creds: map[string]string{
+				CredentialsKeyActiveDirectoryEndpointURL:     azure.USGovernmentCloud.ActiveDirectoryEndpoint,
+				CredentialsKeyResourceManagerEndpointURL:     azure.USGovernmentCloud.ResourceManagerEndpoint,
+				CredentialsKeyActiveDirectoryGraphResourceID: azure.USGovernmentCloud.GraphEndpoint,
+				CredentialsKeyStorageEndpointSuffix:          azure.USGovernmentCloud.StorageEndpointSuffix,
+				CredentialsKeyTokenAudience:                  azure.USGovernmentCloud.TokenAudience,
+				CredentialsKeySubscriptionID:                 sub,
+			}},
+		},
+		"CustomEnvironment": {
+			reason: "The endpoints of a custom environment should be discovered from its metadata.",
+			args: args{
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source:   xpv1.CredentialsSourceInjectedIdentity,
+						ClientID: &clientID,
+					},
+					SubscriptionID: &sub,
+					Environment:    &v1beta1.Environment{MetadataURL: &stack.URL},
+				}},
+			},
+			want: want{creds: map[string]string{
+				CredentialsKeyActiveDirectoryEndpointURL:     "https://adfs.local.azurestack.external/adfs",
+				CredentialsKeyResourceManagerEndpointURL:     stack.URL,
+				CredentialsKeyActiveDirectoryGraphResourceID: "https://graph.local.azurestack.external/",
+				CredentialsKeyStorageEndpointSuffix:          strings.TrimPrefix(stack.URL, "http://127."), // Everything after the first label.,
+				CredentialsKeyTokenAudience:                  "https://management.adfs.azurestack.local/cool-tenant",
+				CredentialsKeyMSIEndpoint:                    msi,
+				CredentialsKeyClientID:                       clientID,
+				CredentialsKeySubscriptionID:                 sub,
+			}},
+		},
+		"CustomEnvironmentError": {
+			reason: "Errors discovering the endpoints of a custom environment should be returned.",
+			args: args{
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceInjectedIdentity,
+					},
+					SubscriptionID: &sub,
+					Environment:    &v1beta1.Environment{MetadataURL: &empty},
+				}},
+			},
+			want: want{err: errors.Wrap(errors.New("Metadata resource manager endpoint is empty"), errGetEnvironment)},
+		},
+		"SecretGetError": {
+			reason: "Errors reading the credentials secret should be returned.",
+			args: args{
+				c: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{Key: "creds"},
+						},
+					},
+				}},
+			},
+			want: want{err: errors.Wrap(errors.Wrap(errBoom, "cannot get credentials secret"), errGetCredentials)},
+		},
+		"InjectedIdentity": {
+			reason: "A user-assigned managed identity should be acquired from the MSI endpoint.",
+			args: args{
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source:   xpv1.CredentialsSourceInjectedIdentity,
+						ClientID: &clientID,
+					},
+					SubscriptionID: &sub,
+				}},
+			},
+			want: want{creds: map[string]string{
+				CredentialsKeyActiveDirectoryEndpointURL:     azure.PublicCloud.ActiveDirectoryEndpoint,
+				CredentialsKeyResourceManagerEndpointURL:     azure.PublicCloud.ResourceManagerEndpoint,
+				CredentialsKeyActiveDirectoryGraphResourceID: azure.PublicCloud.GraphEndpoint,
+				CredentialsKeyStorageEndpointSuffix:          azure.PublicCloud.StorageEndpointSuffix,
+				CredentialsKeyMSIEndpoint:                    msi,
+				CredentialsKeyClientID:                       clientID,
+				CredentialsKeySubscriptionID:                 sub,
+			}},
+		},
+		"InjectedIdentityNoSubscription": {
+			reason: "A subscription is required since there is no secret to read it from.",
+			args: args{
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{Source: xpv1.CredentialsSourceInjectedIdentity},
+				}},
+			},
+			want: want{err: errors.Errorf(errNoSubscriptionIDFmt, xpv1.CredentialsSourceInjectedIdentity)},
+		},
+		"WorkloadIdentity": {
+			reason: "The federated token file should be passed along with the client and tenant.",
+			args: args{
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source:        v1beta1.CredentialsSourceWorkloadIdentity,
+						ClientID:      &clientID,
+						TenantID:      &tenantID,
+						TokenFilePath: &tokenFile,
+					},
+					SubscriptionID: &sub,
+				}},
+			},
+			want: want{creds: map[string]string{
+				CredentialsKeyActiveDirectoryEndpointURL:     azure.PublicCloud.ActiveDirectoryEndpoint,
+				CredentialsKeyResourceManagerEndpointURL:     azure.PublicCloud.ResourceManagerEndpoint,
+				CredentialsKeyActiveDirectoryGraphResourceID: azure.PublicCloud.GraphEndpoint,
+				CredentialsKeyStorageEndpointSuffix:          azure.PublicCloud.StorageEndpointSuffix,
+				CredentialsKeyFederatedTokenFile:             tokenFile,
+				CredentialsKeyClientID:                       clientID,
+				CredentialsKeyTenantID:                       tenantID,
+				CredentialsKeySubscriptionID:                 sub,
+			}},
+		},
+		"WorkloadIdentityNoTenant": {
+			reason: "Workload identity cannot be used without a tenant.",
+			args: args{
+				pc: &v1beta1.ProviderConfig{Spec: v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source:        v1beta1.CredentialsSourceWorkloadIdentity,
+						ClientID:      &clientID,
+						TokenFilePath: &tokenFile,
+					},
+					SubscriptionID: &sub,
+				}},
+			},
+			want: want{err: errors.Errorf(errNoTenantIDFmt, v1beta1.CredentialsSourceWorkloadIdentity)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			creds, err := ProviderConfigCredentials(context.Background(), tc.args.c, tc.args.pc)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nProviderConfigCredentials(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.creds, creds); diff != "" {
+				t.Errorf("\n%s\nProviderConfigCredentials(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSubscriptionID(t *testing.T) {
+	sub := "bf1b0e59-93da-42e0-82c6-5a1d94227911"
+	other := "0b1f6471-1bf0-4dda-aec3-cb9272f09590"
+
+	pc := &v1beta1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+		Spec:       v1beta1.ProviderConfigSpec{AllowedSubscriptionIDs: []string{other}},
+	}
+	annotated := func(sub string) resource.Managed {
+		mg := &fake.Managed{}
+		mg.SetAnnotations(map[string]string{v1beta1.AnnotationKeySubscriptionID: sub})
+		return mg
+	}
+
+	type want struct {
+		sub string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		want   want
+	}{
+		"NoOverride": {
+			reason: "The ProviderConfig's subscription should be used if the managed resource doesn't override it.",
+			mg:     &fake.Managed{},
+			want:   want{sub: sub},
+		},
+		"SameSubscription": {
+			reason: "Overriding the subscription with that of the ProviderConfig should always be allowed.",
+			mg:     annotated(strings.ToUpper(sub)),
+			want:   want{sub: sub},
+		},
+		"AllowedOverride": {
+			reason: "A subscription allowed by the ProviderConfig should override its subscription.",
+			mg:     annotated(other),
+			want:   want{sub: other},
+		},
+		"DisallowedOverride": {
+			reason: "A subscription not allowed by the ProviderConfig should return an error.",
+			mg:     annotated("d7a0bf5e-0c1b-4b0a-9b0e-4e0a4f1e1f9c"),
+			want:   want{err: errors.Errorf(errSubscriptionNotAllowedFmt, "d7a0bf5e-0c1b-4b0a-9b0e-4e0a4f1e1f9c", "cool")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := SubscriptionID(tc.mg, pc, sub)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSubscriptionID(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sub, got); diff != "" {
+				t.Errorf("\n%s\nSubscriptionID(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetMultiTenantAuthInfo(t *testing.T) {
+	sub := "bf1b0e59-93da-42e0-82c6-5a1d94227911"
+	other := "0b1f6471-1bf0-4dda-aec3-cb9272f09590"
+
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			switch o := obj.(type) {
+			case *v1beta1.ProviderConfig:
+				o.SetName(key.Name)
+				o.Spec = v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{Key: "creds"},
+						},
+					},
+					AllowedSubscriptionIDs: []string{other},
+				}
+				if key.Name == "cool-multi-tenant" {
+					o.Spec.AuxiliaryTenantIDs = []string{"72f988bf-86f1-41af-91ab-2d7cd011db47"}
+				}
+			case *corev1.Secret:
+				o.Data = map[string][]byte{"creds": []byte(authData)}
+			case *v1beta1.ProviderConfigUsage:
+				return kerrors.NewNotFound(schema.GroupResource{}, "")
+			}
+			return nil
+		},
+		MockCreate: test.NewMockCreateFn(nil),
+	}
+	managed := func(pc, sub string) resource.Managed {
+		mg := &fake.Managed{}
+		mg.SetName("cool-managed")
+		mg.SetProviderConfigReference(&xpv1.Reference{Name: pc})
+		if sub != "" {
+			mg.SetAnnotations(map[string]string{v1beta1.AnnotationKeySubscriptionID: sub})
+		}
+		return mg
+	}
+
+	type want struct {
+		sub         string
+		multiTenant bool
+		err         error
+	}
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		want   want
+	}{
+		"NoOverride": {
+			reason: "The ProviderConfig's subscription should be used if the managed resource doesn't override it.",
+			mg:     managed("cool-multi-tenant", ""),
+			want:   want{sub: sub, multiTenant: true},
+		},
+		"AllowedOverride": {
+			reason: "A subscription allowed by the ProviderConfig should override its subscription.",
+			mg:     managed("cool-multi-tenant", other),
+			want:   want{sub: other, multiTenant: true},
+		},
+		"DisallowedOverride": {
+			reason: "A subscription not allowed by the ProviderConfig should return an error.",
+			mg:     managed("cool-multi-tenant", "d7a0bf5e-0c1b-4b0a-9b0e-4e0a4f1e1f9c"),
+			want:   want{err: errors.Errorf(errSubscriptionNotAllowedFmt, "d7a0bf5e-0c1b-4b0a-9b0e-4e0a4f1e1f9c", "cool-multi-tenant")},
+		},
+		"NoAuxiliaryTenants": {
+			reason: "The Azure Resource Manager authorizer should be used if the ProviderConfig has no auxiliary tenants.",
+			mg:     managed("cool-single-tenant", ""),
+			want:   want{sub: sub},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			content, a, err := GetMultiTenantAuthInfo(context.Background(), kube, tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetMultiTenantAuthInfo(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sub, content[CredentialsKeySubscriptionID]); diff != "" {
+				t.Errorf("\n%s\nGetMultiTenantAuthInfo(...): -want subscription, +got subscription:\n%s", tc.reason, diff)
+			}
+			if _, bearer := a.(*autorest.BearerAuthorizer); tc.want.err == nil && bearer == tc.want.multiTenant {
+				t.Errorf("\n%s\nGetMultiTenantAuthInfo(...): want multi-tenant authorizer %t, got %T", tc.reason, tc.want.multiTenant, a)
+			}
+		})
+	}
+}
+
+func TestGetSubscriptionID(t *testing.T) {
+	sub := "bf1b0e59-93da-42e0-82c6-5a1d94227911"
+	other := "0b1f6471-1bf0-4dda-aec3-cb9272f09590"
+
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			switch o := obj.(type) {
+			case *v1beta1.ProviderConfig:
+				o.SetName("cool-subscription")
+				o.Spec = v1beta1.ProviderConfigSpec{
+					Credentials: v1beta1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{Key: "creds"},
+						},
+					},
+					AllowedSubscriptionIDs: []string{other},
+				}
+			case *corev1.Secret:
+				o.Data = map[string][]byte{"creds": []byte(authData)}
+			default:
+				return errors.Errorf("unexpected %T", obj)
+			}
+			return nil
+		},
+	}
+	managed := func(sub string) resource.Managed {
+		mg := &fake.Managed{}
+		mg.SetProviderConfigReference(&xpv1.Reference{Name: "cool-subscription"})
+		if sub != "" {
+			mg.SetAnnotations(map[string]string{v1beta1.AnnotationKeySubscriptionID: sub})
+		}
+		return mg
+	}
+
+	type want struct {
+		sub string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		want   want
+	}{
+		"NoOverride": {
+			reason: "The ProviderConfig's subscription should be returned if the managed resource doesn't override it.",
+			mg:     managed(""),
+			want:   want{sub: sub},
+		},
+		"AllowedOverride": {
+			reason: "A subscription allowed by the ProviderConfig should override its subscription.",
+			mg:     managed(other),
+			want:   want{sub: other},
+		},
+		"NoReference": {
+			reason: "An error should be returned if the managed resource references neither a ProviderConfig nor a Provider.",
+			mg:     &fake.Managed{},
+			want:   want{err: errors.New(errNeitherPCNorPGiven)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GetSubscriptionID(context.Background(), kube, tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetSubscriptionID(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sub, got); diff != "" {
+				t.Errorf("\n%s\nGetSubscriptionID(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestIsNotFound(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
 
@@ -163,6 +765,45 @@ func TestIsNotFound(t *testing.T) {
 	}
 }
 
+func TestIsUnauthorized(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("boom"), false},
+		{autorest.DetailedError{StatusCode: http.StatusNotFound}, false},
+		{autorest.DetailedError{StatusCode: http.StatusUnauthorized}, true},
+		{autorest.DetailedError{StatusCode: http.StatusForbidden}, true},
+	}
+
+	for _, tt := range cases {
+		actual := IsUnauthorized(tt.err)
+		g.Expect(actual).To(gomega.Equal(tt.expected))
+	}
+}
+
+func TestIsCredentialsExpired(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New(aadErrorSecretExpired), false},
+		{autorest.DetailedError{StatusCode: http.StatusUnauthorized, Original: errors.New("AADSTS7000215: Invalid client secret provided.")}, false},
+		{autorest.DetailedError{StatusCode: http.StatusUnauthorized, Original: errors.New("AADSTS7000222: The provided client secret keys are expired.")}, true},
+	}
+
+	for _, tt := range cases {
+		actual := IsCredentialsExpired(tt.err)
+		g.Expect(actual).To(gomega.Equal(tt.expected))
+	}
+}
+
 func TestStringHelpers(t *testing.T) {
 	t.Run("ToStringMap", func(t *testing.T) {
 		original := make(map[string]*string)
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azureerrors
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// TypeAzureAPI indicates whether the most recent requests made to Azure for
+// a managed resource succeeded. Its reason is the category of the most recent
+// error when they did not.
+const TypeAzureAPI xpv1.ConditionType = "AzureAPI"
+
+// ReasonSucceeded indicates the most recent requests made to Azure for a
+// managed resource succeeded.
+const ReasonSucceeded xpv1.ConditionReason = "Succeeded"
+
+// Failed returns a condition that indicates a request made to Azure for a
+// managed resource failed with the supplied error.
+func Failed(e *Error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAzureAPI,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             xpv1.ConditionReason(e.Category),
+		Message:            e.Error(),
+	}
+}
+
+// Succeeded returns a condition that indicates the requests made to Azure for
+// a managed resource succeeded.
+func Succeeded() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAzureAPI,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSucceeded,
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azureerrors
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+// Errors that won't resolve themselves until the managed resource, its
+// credentials, or its subscription change are retried slowly, so that we
+// don't hot-loop making requests that are sure to fail. A change to the
+// managed resource triggers a reconcile regardless. Concurrent modifications
+// are retried promptly, so that the modified resource is observed again.
+const (
+	conflictBackoff = 30 * time.Second
+	blockedBackoff  = 5 * time.Minute
+)
+
+var categoryBackoffs = map[Category]time.Duration{
+	CategoryConflict:      conflictBackoff,
+	CategoryUnauthorized:  blockedBackoff,
+	CategoryForbidden:     blockedBackoff,
+	CategoryQuotaExceeded: blockedBackoff,
+	CategoryInvalidSKU:    blockedBackoff,
+	CategoryBadRequest:    blockedBackoff,
+}
+
+// backoffs tracks the category of the most recent error of the managed
+// resources reconciled by all controllers.
+var backoffs = NewBackoff()
+
+// A Backoff tracks the category of the most recent error of each managed
+// resource, and how long to wait before retrying it.
+type Backoff struct {
+	mu    sync.Mutex
+	items map[azureclients.ManagedKey]Category
+}
+
+// NewBackoff returns a Backoff that tracks no managed resources.
+func NewBackoff() *Backoff {
+	return &Backoff{items: map[azureclients.ManagedKey]Category{}}
+}
+
+// Record that the supplied managed resource most recently failed with an
+// error of the supplied category.
+func (b *Backoff) Record(k azureclients.ManagedKey, c Category) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items[k] = c
+}
+
+// Forget the most recent error of the supplied managed resource.
+func (b *Backoff) Forget(k azureclients.ManagedKey) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.items, k)
+}
+
+// Delay returns how long to wait before retrying the supplied managed
+// resource, given the category of its most recent error.
+func (b *Backoff) Delay(k azureclients.ManagedKey) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return categoryBackoffs[b.items[k]]
+}
+
+// NewRateLimiter returns a workqueue rate limiter that delays managed
+// resources of the supplied kind according to the category of their most
+// recent error, or at least as long as the supplied rate limiter would. Like
+// azure.NewRateLimiter, it must only be used by the controller of that kind.
+func NewRateLimiter(rl workqueue.RateLimiter, kind schema.GroupKind) workqueue.RateLimiter {
+	return &backoffRateLimiter{RateLimiter: rl, kind: kind, backoff: backoffs}
+}
+
+type backoffRateLimiter struct {
+	workqueue.RateLimiter
+	kind    schema.GroupKind
+	backoff *Backoff
+}
+
+func (l *backoffRateLimiter) When(item interface{}) time.Duration {
+	d := l.RateLimiter.When(item)
+	r, ok := item.(reconcile.Request)
+	if !ok {
+		return d
+	}
+	if bd := l.backoff.Delay(azureclients.ManagedKey{Kind: l.kind, Name: r.Name}); bd > d {
+		return bd
+	}
+	return d
+}
+
+// Forget the supplied item. Controllers forget an item once it has been
+// reconciled successfully, including when its managed resource has been
+// deleted.
+func (l *backoffRateLimiter) Forget(item interface{}) {
+	l.RateLimiter.Forget(item)
+	if r, ok := item.(reconcile.Request); ok {
+		l.backoff.Forget(azureclients.ManagedKey{Kind: l.kind, Name: r.Name})
+	}
+}
+
+// A Connecter classifies the errors returned by the ExternalConnecter it
+// wraps, and the ExternalClients it produces. Azure errors are returned with
+// their category and request IDs, reported using the AzureAPI condition, and
+// retried according to their category.
+type Connecter struct {
+	connecter managed.ExternalConnecter
+	backoff   *Backoff
+}
+
+// NewConnecter returns a Connecter that classifies the errors of the supplied
+// ExternalConnecter.
+func NewConnecter(c managed.ExternalConnecter) *Connecter {
+	return &Connecter{connecter: c, backoff: backoffs}
+}
+
+// Connect to Azure using the wrapped ExternalConnecter.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ec, err := c.connecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, c.handle(mg, err)
+	}
+	return &external{client: ec, handle: c.handle, forget: c.forget}, nil
+}
+
+// forget the most recent error of the supplied managed resource, e.g. because
+// its external resource no longer exists.
+func (c *Connecter) forget(mg resource.Managed) {
+	c.backoff.Forget(azureclients.ManagedKeyOf(mg))
+}
+
+// handle the supplied error, which may be nil, returned by a request made for
+// the supplied managed resource.
+func (c *Connecter) handle(mg resource.Managed, err error) error {
+	if err == nil {
+		c.forget(mg)
+		// Only resources that have encountered an error report success, to
+		// avoid adding a condition to every managed resource.
+		if mg.GetCondition(TypeAzureAPI).Status == corev1.ConditionFalse {
+			mg.SetConditions(Succeeded())
+		}
+		return nil
+	}
+	ce := Classify(err)
+	if ce.Category == CategoryUnknown {
+		c.forget(mg)
+		return err
+	}
+	c.backoff.Record(azureclients.ManagedKeyOf(mg), ce.Category)
+	mg.SetConditions(Failed(ce))
+	return ce
+}
+
+type external struct {
+	client managed.ExternalClient
+	handle func(mg resource.Managed, err error) error
+	forget func(mg resource.Managed)
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.client.Observe(ctx, mg)
+	if err == nil && !o.ResourceExists {
+		e.forget(mg)
+	}
+	return o, e.handle(mg, err)
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := e.client.Create(ctx, mg)
+	return c, e.handle(mg, err)
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := e.client.Update(ctx, mg)
+	return u, e.handle(mg, err)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	err := e.client.Delete(ctx, mg)
+	if err == nil {
+		e.forget(mg)
+	}
+	return e.handle(mg, err)
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azureerrors
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+func TestConnecter(t *testing.T) {
+	errBoom := errors.New("boom")
+	errConflict := requestError(http.StatusConflict, "AnotherOperationInProgress", "busy")
+	errModified := requestError(http.StatusPreconditionFailed, "PreconditionFailed", "etag mismatch")
+
+	failed := func() *fake.Managed {
+		mg := &fake.Managed{}
+		mg.SetName("cool")
+		mg.SetConditions(Failed(Classify(errConflict)))
+		return mg
+	}
+
+	type want struct {
+		err       error
+		condition *xpv1.ConditionReason
+		status    corev1.ConditionStatus
+		delay     time.Duration
+	}
+	conflict := xpv1.ConditionReason(CategoryConflict)
+	modified := xpv1.ConditionReason(CategoryConcurrentModification)
+	succeeded := ReasonSucceeded
+
+	cases := map[string]struct {
+		reason    string
+		mg        *fake.Managed
+		connecter managed.ExternalConnecter
+		want      want
+	}{
+		"ConnectError": {
+			reason: "Azure errors returned when connecting should be classified and reported.",
+			mg:     &fake.Managed{},
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return nil, errConflict
+			}),
+			want: want{err: Classify(errConflict), condition: &conflict, status: corev1.ConditionFalse, delay: conflictBackoff},
+		},
+		"ObserveError": {
+			reason: "Azure errors returned when observing should be classified and reported.",
+			mg:     &fake.Managed{},
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, errors.Wrap(errConflict, "cannot observe")
+				}}, nil
+			}),
+			want: want{err: Classify(errors.Wrap(errConflict, "cannot observe")), condition: &conflict, status: corev1.ConditionFalse, delay: conflictBackoff},
+		},
+		"ConcurrentModification": {
+			reason: "Conditional requests rejected because the resource was modified should be reported, and retried promptly.",
+			mg:     &fake.Managed{},
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, errors.Wrap(errModified, "cannot update")
+				}}, nil
+			}),
+			want: want{err: Classify(errors.Wrap(errModified, "cannot update")), condition: &modified, status: corev1.ConditionFalse},
+		},
+		"UnknownError": {
+			reason: "Errors that did not originate from Azure should be returned unchanged.",
+			mg:     &fake.Managed{},
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return nil, errBoom
+			}),
+			want: want{err: errBoom, status: corev1.ConditionUnknown},
+		},
+		"Recovered": {
+			reason: "Resources that previously failed should report success once requests succeed.",
+			mg:     failed(),
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, nil
+				}}, nil
+			}),
+			want: want{condition: &succeeded, status: corev1.ConditionTrue},
+		},
+		"NeverFailed": {
+			reason: "Resources that never failed should not report success.",
+			mg:     &fake.Managed{},
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, nil
+				}}, nil
+			}),
+			want: want{status: corev1.ConditionUnknown},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := NewBackoff()
+			c := &Connecter{connecter: tc.connecter, backoff: b}
+			ec, err := c.Connect(context.Background(), tc.mg)
+			if err == nil {
+				_, err = ec.Observe(context.Background(), tc.mg)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nConnect(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			got := tc.mg.GetCondition(TypeAzureAPI)
+			if diff := cmp.Diff(tc.want.status, got.Status); diff != "" {
+				t.Errorf("\n%s\nConnect(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			if tc.want.condition != nil {
+				if diff := cmp.Diff(*tc.want.condition, got.Reason); diff != "" {
+					t.Errorf("\n%s\nConnect(...): -want reason, +got reason:\n%s", tc.reason, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.want.delay, b.Delay(azureclients.ManagedKeyOf(tc.mg))); diff != "" {
+				t.Errorf("\n%s\nConnect(...): -want backoff, +got backoff:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnecterForget(t *testing.T) {
+	errConflict := requestError(http.StatusConflict, "AnotherOperationInProgress", "busy")
+
+	cases := map[string]struct {
+		reason string
+		client managed.ExternalClient
+		call   func(ctx context.Context, ec managed.ExternalClient, mg resource.Managed) error
+	}{
+		"ObserveNotExists": {
+			reason: "The most recent error should be forgotten once the external resource no longer exists.",
+			client: &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+				return managed.ExternalObservation{ResourceExists: false}, nil
+			}},
+			call: func(ctx context.Context, ec managed.ExternalClient, mg resource.Managed) error {
+				_, err := ec.Observe(ctx, mg)
+				return err
+			},
+		},
+		"Deleted": {
+			reason: "The most recent error should be forgotten once the external resource is deleted.",
+			client: &managed.ExternalClientFns{DeleteFn: func(_ context.Context, _ resource.Managed) error {
+				return nil
+			}},
+			call: func(ctx context.Context, ec managed.ExternalClient, mg resource.Managed) error {
+				return ec.Delete(ctx, mg)
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mg := &fake.Managed{}
+			mg.SetName("cool")
+			b := NewBackoff()
+			b.Record(azureclients.ManagedKeyOf(mg), Classify(errConflict).Category)
+			c := &Connecter{connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return tc.client, nil
+			}), backoff: b}
+			ec, err := c.Connect(context.Background(), mg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.call(context.Background(), ec, mg); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(time.Duration(0), b.Delay(azureclients.ManagedKeyOf(mg))); diff != "" {
+				t.Errorf("\n%s\nDelay(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBackoffRateLimiter(t *testing.T) {
+	redis := schema.GroupKind{Group: "cache.azure.crossplane.io", Kind: "Redis"}
+	vnet := schema.GroupKind{Group: "network.azure.crossplane.io", Kind: "VirtualNetwork"}
+	cool := reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool"}}
+
+	b := NewBackoff()
+	b.Record(azureclients.ManagedKey{Kind: redis, Name: "cool"}, CategoryQuotaExceeded)
+	rl := &backoffRateLimiter{RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Second, time.Minute), kind: redis, backoff: b}
+
+	if diff := cmp.Diff(blockedBackoff, rl.When(cool)); diff != "" {
+		t.Errorf("When(...): -want, +got:\n%s", diff)
+	}
+	b.Forget(azureclients.ManagedKey{Kind: redis, Name: "cool"})
+	if diff := cmp.Diff(2*time.Second, rl.When(cool)); diff != "" {
+		t.Errorf("When(...): -want, +got:\n%s", diff)
+	}
+
+	b.Record(azureclients.ManagedKey{Kind: redis, Name: "cool"}, CategoryQuotaExceeded)
+	other := &backoffRateLimiter{RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Second, time.Minute), kind: vnet, backoff: b}
+	if diff := cmp.Diff(time.Second, other.When(cool)); diff != "" {
+		t.Errorf("When(...): managed resources of another kind with the same name should not back off: -want, +got:\n%s", diff)
+	}
+
+	rl.Forget(cool)
+	if diff := cmp.Diff(time.Duration(0), b.Delay(azureclients.ManagedKey{Kind: redis, Name: "cool"})); diff != "" {
+		t.Errorf("Forget(...): managed resources should not back off once forgotten: -want, +got:\n%s", diff)
+	}
+}
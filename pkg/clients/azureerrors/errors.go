@@ -0,0 +1,317 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package azureerrors classifies the errors returned by Azure, and reports
+// them on the managed resources they occurred for.
+package azureerrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+
+	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+// A Category of Azure error. Errors of the same category are reported and
+// retried the same way.
+type Category string
+
+// Error categories.
+const (
+	// CategoryUnknown errors could not be classified, typically because
+	// they did not originate from Azure.
+	CategoryUnknown Category = "Unknown"
+
+	// CategoryNotFound errors indicate the requested resource does not exist.
+	CategoryNotFound Category = "NotFound"
+
+	// CategoryUnauthorized errors indicate Azure rejected the credentials.
+	CategoryUnauthorized Category = "Unauthorized"
+
+	// CategoryForbidden errors indicate the credentials are valid, but lack
+	// the role assignments required to perform the operation.
+	CategoryForbidden Category = "Forbidden"
+
+	// CategoryConflict errors indicate the resource is busy, e.g. because
+	// another operation is in progress.
+	CategoryConflict Category = "Conflict"
+
+	// CategoryConcurrentModification errors indicate a conditional request
+	// was rejected because the resource was modified since it was last
+	// observed, e.g. using the Azure portal or another tool.
+	CategoryConcurrentModification Category = "ConcurrentModification"
+
+	// CategoryThrottled errors indicate Azure Resource Manager is throttling
+	// requests to the subscription.
+	CategoryThrottled Category = "Throttled"
+
+	// CategoryQuotaExceeded errors indicate the operation would exceed a
+	// quota of the subscription.
+	CategoryQuotaExceeded Category = "QuotaExceeded"
+
+	// CategoryInvalidSKU errors indicate the requested SKU is invalid, or is
+	// not available in the requested location.
+	CategoryInvalidSKU Category = "InvalidSKU"
+
+	// CategoryBadRequest errors indicate Azure rejected the request, e.g.
+	// because the managed resource's spec is invalid.
+	CategoryBadRequest Category = "BadRequest"
+
+	// CategoryTransient errors indicate a failure of Azure or the network
+	// that is likely to resolve itself.
+	CategoryTransient Category = "Transient"
+)
+
+// Response headers that identify a request to Azure Resource Manager.
+const (
+	HeaderRequestID            = "x-ms-request-id"
+	HeaderCorrelationRequestID = "x-ms-correlation-request-id"
+)
+
+// Azure error codes, which take precedence over the HTTP status code when
+// classifying an error.
+var codeCategories = map[string]Category{
+	"ResourceNotFound":              CategoryNotFound,
+	"ResourceGroupNotFound":         CategoryNotFound,
+	"InvalidAuthenticationToken":    CategoryUnauthorized,
+	"ExpiredAuthenticationToken":    CategoryUnauthorized,
+	"AuthorizationFailed":           CategoryForbidden,
+	"LinkedAuthorizationFailed":     CategoryForbidden,
+	"AnotherOperationInProgress":    CategoryConflict,
+	"OperationNotAllowed":           CategoryBadRequest,
+	"Conflict":                      CategoryConflict,
+	"PreconditionFailed":            CategoryConcurrentModification,
+	"TooManyRequests":               CategoryThrottled,
+	"SubscriptionRequestsThrottled": CategoryThrottled,
+	"QuotaExceeded":                 CategoryQuotaExceeded,
+	"SkuNotAvailable":               CategoryInvalidSKU,
+	"SkuNotSupported":               CategoryInvalidSKU,
+	"InvalidSku":                    CategoryInvalidSKU,
+}
+
+// An Error returned by Azure, classified by category.
+type Error struct {
+	// Category of the error.
+	Category Category
+
+	// StatusCode of the HTTP response, if any.
+	StatusCode int
+
+	// Code, Message, and Target of the Azure service error, if any.
+	Code    string
+	Message string
+	Target  string
+
+	// RequestID and CorrelationID identify the failed request to Azure
+	// support.
+	RequestID     string
+	CorrelationID string
+
+	err error
+}
+
+// Error returns the original error, prefixed by its category and the IDs
+// that identify the failed request.
+func (e *Error) Error() string {
+	ids := []string{}
+	if e.RequestID != "" {
+		ids = append(ids, "request ID "+e.RequestID)
+	}
+	if e.CorrelationID != "" {
+		ids = append(ids, "correlation ID "+e.CorrelationID)
+	}
+	if len(ids) == 0 {
+		return fmt.Sprintf("%s: %s", e.Category, e.err)
+	}
+	return fmt.Sprintf("%s (%s): %s", e.Category, strings.Join(ids, ", "), e.err)
+}
+
+// Cause returns the original error.
+func (e *Error) Cause() error {
+	return e.err
+}
+
+// Unwrap returns the original error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Classify the supplied error. It returns nil if the supplied error is nil.
+// Errors that were wrapped, e.g. using errors.Wrap, are classified by their
+// cause.
+func Classify(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	if ce := find(err); ce != nil {
+		return ce
+	}
+	e := &Error{err: err}
+	e.inspect(errors.Cause(err))
+	e.Category = e.categorize(errors.Cause(err))
+	return e
+}
+
+// find the first classified error in the supplied chain of wrapped errors.
+func find(err error) *Error {
+	for err != nil {
+		if ce, ok := err.(*Error); ok {
+			return ce
+		}
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			return nil
+		}
+		err = c.Cause()
+	}
+	return nil
+}
+
+// Is returns true if the supplied error is of the supplied category.
+func Is(err error, c Category) bool {
+	ce := Classify(err)
+	return ce != nil && ce.Category == c
+}
+
+// inspect the supplied error, and any errors it wraps, for the details of the
+// Azure service error and the request that caused it.
+func (e *Error) inspect(err error) { // nolint:gocyclo
+	switch t := err.(type) {
+	case autorest.DetailedError:
+		if sc, ok := t.StatusCode.(int); ok && e.StatusCode == 0 {
+			e.StatusCode = sc
+		}
+		if t.Response != nil {
+			e.setIDs(t.Response.Header)
+		}
+		if len(t.ServiceError) > 0 && e.Code == "" {
+			body := struct {
+				Error *azure.ServiceError `json:"error"`
+			}{}
+			if json.Unmarshal(t.ServiceError, &body) == nil && body.Error != nil {
+				e.setServiceError(body.Error)
+			}
+		}
+		if t.Original != nil {
+			e.inspect(t.Original)
+		}
+	case *autorest.DetailedError:
+		if t != nil {
+			e.inspect(*t)
+		}
+	case *azure.RequestError:
+		if t == nil {
+			return
+		}
+		if t.RequestID != "" {
+			e.RequestID = t.RequestID
+		}
+		if t.ServiceError != nil {
+			e.setServiceError(t.ServiceError)
+		}
+		e.inspect(t.DetailedError)
+	case azure.RequestError:
+		e.inspect(&t)
+	case *azure.ServiceError:
+		if t != nil {
+			e.setServiceError(t)
+		}
+	case azure.ServiceError:
+		e.setServiceError(&t)
+	}
+}
+
+func (e *Error) setIDs(h http.Header) {
+	if id := h.Get(HeaderRequestID); id != "" && e.RequestID == "" {
+		e.RequestID = id
+	}
+	if id := h.Get(HeaderCorrelationRequestID); id != "" && e.CorrelationID == "" {
+		e.CorrelationID = id
+	}
+}
+
+func (e *Error) setServiceError(se *azure.ServiceError) {
+	if se.Code == "" || e.Code != "" {
+		return
+	}
+	e.Code = se.Code
+	e.Message = se.Message
+	if se.Target != nil {
+		e.Target = *se.Target
+	}
+}
+
+// categorize the error by its Azure error code if it has one, and by its HTTP
+// status code otherwise.
+func (e *Error) categorize(cause error) Category { // nolint:gocyclo
+	if azureclients.IsThrottled(cause) {
+		return CategoryThrottled
+	}
+	if _, ok := cause.(adal.TokenRefreshError); ok {
+		return CategoryUnauthorized
+	}
+	if de, ok := cause.(autorest.DetailedError); ok {
+		if _, ok := de.Original.(adal.TokenRefreshError); ok {
+			return CategoryUnauthorized
+		}
+	}
+
+	code := strings.ToLower(e.Code)
+	switch {
+	case strings.Contains(code, "quota"):
+		return CategoryQuotaExceeded
+	case strings.Contains(code, "sku"):
+		return CategoryInvalidSKU
+	case e.Code == "OperationNotAllowed" && strings.Contains(strings.ToLower(e.Message), "quota"):
+		return CategoryQuotaExceeded
+	}
+	if c, ok := codeCategories[e.Code]; ok {
+		return c
+	}
+
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return CategoryNotFound
+	case e.StatusCode == http.StatusUnauthorized:
+		return CategoryUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return CategoryForbidden
+	case e.StatusCode == http.StatusConflict:
+		return CategoryConflict
+	case e.StatusCode == http.StatusPreconditionFailed:
+		return CategoryConcurrentModification
+	case e.StatusCode == http.StatusTooManyRequests:
+		return CategoryThrottled
+	case e.StatusCode >= http.StatusInternalServerError:
+		return CategoryTransient
+	case e.StatusCode >= http.StatusBadRequest:
+		return CategoryBadRequest
+	}
+
+	var ne net.Error
+	if errors.As(cause, &ne) && ne.Timeout() {
+		return CategoryTransient
+	}
+	return CategoryUnknown
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azureerrors
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+
+	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+// requestError returns an error like those returned by the Azure SDK when
+// Azure responds with an error.
+func requestError(status int, code, message string) error {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	resp.Header.Set(HeaderRequestID, "cool-request")
+	resp.Header.Set(HeaderCorrelationRequestID, "cool-correlation")
+	target := "cool-target"
+	return autorest.DetailedError{
+		StatusCode: status,
+		Response:   resp,
+		Original: &azure.RequestError{
+			DetailedError: autorest.DetailedError{StatusCode: status, Response: resp},
+			ServiceError:  &azure.ServiceError{Code: code, Message: message, Target: &target},
+			RequestID:     "cool-request",
+		},
+	}
+}
+
+func TestClassify(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   *Error
+	}{
+		"Nil": {
+			reason: "Nil errors should not be classified.",
+		},
+		"Unknown": {
+			reason: "Errors that did not originate from Azure should be unknown.",
+			err:    errBoom,
+			want:   &Error{Category: CategoryUnknown},
+		},
+		"AnotherOperationInProgress": {
+			reason: "The Azure error code and request details should be extracted from wrapped errors.",
+			err:    errors.Wrap(requestError(http.StatusConflict, "AnotherOperationInProgress", "busy"), "cannot update"),
+			want: &Error{
+				Category:      CategoryConflict,
+				StatusCode:    http.StatusConflict,
+				Code:          "AnotherOperationInProgress",
+				Message:       "busy",
+				Target:        "cool-target",
+				RequestID:     "cool-request",
+				CorrelationID: "cool-correlation",
+			},
+		},
+		"QuotaExceeded": {
+			reason: "Quota errors should be classified by their code rather than their status.",
+			err:    requestError(http.StatusBadRequest, "OperationNotAllowed", "Operation results in exceeding quota limits of Core."),
+			want: &Error{
+				Category:      CategoryQuotaExceeded,
+				StatusCode:    http.StatusBadRequest,
+				Code:          "OperationNotAllowed",
+				Message:       "Operation results in exceeding quota limits of Core.",
+				Target:        "cool-target",
+				RequestID:     "cool-request",
+				CorrelationID: "cool-correlation",
+			},
+		},
+		"InvalidSKU": {
+			reason: "SKUs that are unavailable in a location should be classified as invalid.",
+			err:    requestError(http.StatusConflict, "SkuNotAvailable", "not here"),
+			want: &Error{
+				Category:      CategoryInvalidSKU,
+				StatusCode:    http.StatusConflict,
+				Code:          "SkuNotAvailable",
+				Message:       "not here",
+				Target:        "cool-target",
+				RequestID:     "cool-request",
+				CorrelationID: "cool-correlation",
+			},
+		},
+		"PreconditionFailed": {
+			reason: "Conditional requests rejected because the resource was modified should be classified as concurrent modifications.",
+			err:    errors.Wrap(requestError(http.StatusPreconditionFailed, "PreconditionFailed", "etag mismatch"), "cannot update"),
+			want: &Error{
+				Category:      CategoryConcurrentModification,
+				StatusCode:    http.StatusPreconditionFailed,
+				Code:          "PreconditionFailed",
+				Message:       "etag mismatch",
+				Target:        "cool-target",
+				RequestID:     "cool-request",
+				CorrelationID: "cool-correlation",
+			},
+		},
+		"Forbidden": {
+			reason: "Errors without a known code should be classified by their status.",
+			err:    autorest.DetailedError{StatusCode: http.StatusForbidden},
+			want:   &Error{Category: CategoryForbidden, StatusCode: http.StatusForbidden},
+		},
+		"ServiceErrorBody": {
+			reason: "The Azure error code should be extracted from the body of a detailed error.",
+			err:    autorest.DetailedError{StatusCode: http.StatusBadRequest, ServiceError: []byte(`{"error": {"code": "InvalidParameter", "message": "bad"}}`)},
+			want:   &Error{Category: CategoryBadRequest, StatusCode: http.StatusBadRequest, Code: "InvalidParameter", Message: "bad"},
+		},
+		"AsyncOperationFailed": {
+			reason: "Service errors returned by long running operations should be classified by their code.",
+			err:    autorest.DetailedError{Original: &azure.ServiceError{Code: "QuotaExceeded", Message: "no more"}},
+			want:   &Error{Category: CategoryQuotaExceeded, Code: "QuotaExceeded", Message: "no more"},
+		},
+		"Transient": {
+			reason: "Server errors should be transient.",
+			err:    autorest.DetailedError{StatusCode: http.StatusServiceUnavailable},
+			want:   &Error{Category: CategoryTransient, StatusCode: http.StatusServiceUnavailable},
+		},
+		"Throttled": {
+			reason: "Requests withheld because their subscription is throttled should be throttled.",
+			err:    &azureclients.ThrottledError{Subscription: "cool", RetryAfter: time.Minute},
+			want:   &Error{Category: CategoryThrottled},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Classify(tc.err)
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(Error{})); diff != "" {
+				t.Errorf("\n%s\nClassify(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if got != nil && got.Cause().Error() != tc.err.Error() {
+				t.Errorf("\n%s\nClassify(...): classified error should wrap the original error", tc.reason)
+			}
+		})
+	}
+}
+
+func TestClassifyClassified(t *testing.T) {
+	ce := Classify(requestError(http.StatusConflict, "AnotherOperationInProgress", "busy"))
+	if got := Classify(errors.Wrap(ce, "cannot observe")); got != ce {
+		t.Errorf("Classify(...): classifying a wrapped classified error should return it, got %#v", got)
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	err := requestError(http.StatusConflict, "AnotherOperationInProgress", "busy")
+	want := "Conflict (request ID cool-request, correlation ID cool-correlation): " + err.Error()
+	if diff := cmp.Diff(want, Classify(err).Error()); diff != "" {
+		t.Errorf("Error(): -want, +got:\n%s", diff)
+	}
+}
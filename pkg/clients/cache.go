@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
+)
+
+// authorizers caches the credentials and authorizers of the ProviderConfigs
+// and Providers referenced by managed resources, across all controllers.
+var authorizers = NewAuthorizerCache()
+
+// An AuthorizerCache caches credentials, and the authorizers derived from
+// them. Caching authorizers allows their tokens to be reused until they
+// expire, rather than acquiring a new token every time a managed resource is
+// reconciled.
+//
+// Entries are keyed by the object their credentials were read from, and are
+// versioned by the resource versions of that object and any secret it
+// references. An entry is replaced when it is requested at a new version,
+// i.e. when the ProviderConfig or its secret changes. Credentials that are
+// not read from an object, e.g. from an environment variable or a file, may
+// change without their version changing, so their entries also expire.
+//
+// Azure SDK clients are deliberately not cached. They hold no state but their
+// authorizer, which is cached, and are cheap to construct per reconcile.
+type AuthorizerCache struct {
+	mu      sync.Mutex
+	entries map[string]*AuthorizerCacheEntry
+	now     func() time.Time
+}
+
+// NewAuthorizerCache returns an empty AuthorizerCache.
+func NewAuthorizerCache() *AuthorizerCache {
+	return &AuthorizerCache{entries: map[string]*AuthorizerCacheEntry{}, now: time.Now}
+}
+
+// Get the entry for the supplied key at the supplied version. The entry's
+// credentials are produced by the supplied function if they are not cached
+// at that version, or if they were cached longer ago than the supplied TTL.
+// Entries never expire if the TTL is zero. Entries with an empty version are
+// never cached, since it is impossible to tell whether they have changed.
+func (c *AuthorizerCache) Get(key, version string, ttl time.Duration, fn func() (map[string]string, error)) (*AuthorizerCacheEntry, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	now := c.now()
+	c.mu.Unlock()
+	if ok && version != "" && e.version == version && (e.expires.IsZero() || now.Before(e.expires)) {
+		return e, nil
+	}
+
+	creds, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	e = &AuthorizerCacheEntry{version: version, creds: creds, authorizers: map[string]autorest.Authorizer{}}
+	if version == "" {
+		return e, nil
+	}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = e
+	return e, nil
+}
+
+// Delete the entry for the supplied key, if any.
+func (c *AuthorizerCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// An AuthorizerCacheEntry holds credentials and the authorizers derived from
+// them.
+type AuthorizerCacheEntry struct {
+	version string
+	expires time.Time
+	creds   map[string]string
+
+	mu          sync.Mutex
+	authorizers map[string]autorest.Authorizer
+	multiTenant autorest.Authorizer
+}
+
+// Credentials returns a copy of the entry's credentials.
+func (e *AuthorizerCacheEntry) Credentials() map[string]string {
+	m := make(map[string]string, len(e.creds))
+	for k, v := range e.creds {
+		m[k] = v
+	}
+	return m
+}
+
+// ResourceManagerAuthorizer returns an authorizer for Azure Resource Manager.
+func (e *AuthorizerCacheEntry) ResourceManagerAuthorizer() (autorest.Authorizer, error) {
+	return e.Authorizer(resourceManagerAudience(e.creds))
+}
+
+// Authorizer returns an authorizer for the supplied resource, e.g. Azure
+// Resource Manager or the AAD graph. Authorizers are created on first use,
+// and refresh their token when it is about to expire.
+func (e *AuthorizerCacheEntry) Authorizer(resource string) (autorest.Authorizer, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if a, ok := e.authorizers[resource]; ok {
+		return a, nil
+	}
+	t, err := NewServicePrincipalToken(e.creds, resource)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetAuthorizer)
+	}
+	a := autorest.NewBearerAuthorizer(t)
+	e.authorizers[resource] = a
+	return a, nil
+}
+
+// MultiTenantAuthorizer returns an authorizer for Azure Resource Manager that
+// also supplies tokens for the auxiliary tenants of the entry's credentials.
+func (e *AuthorizerCacheEntry) MultiTenantAuthorizer() (autorest.Authorizer, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.multiTenant != nil {
+		return e.multiTenant, nil
+	}
+	t, err := NewMultiTenantServicePrincipalToken(e.creds, resourceManagerAudience(e.creds))
+	if err != nil {
+		return nil, errors.Wrap(err, errGetAuthorizer)
+	}
+	e.multiTenant = autorest.NewMultiTenantServicePrincipalTokenAuthorizer(t)
+	return e.multiTenant, nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestAuthorizerCacheGet(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type call struct {
+		key     string
+		version string
+		ttl     time.Duration
+		err     error
+
+		// elapsed is how long after the previous call this call is made.
+		elapsed time.Duration
+	}
+	type want struct {
+		calls int
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		calls  []call
+		want   want
+	}{
+		"SameVersion": {
+			reason: "Credentials should only be produced once per key and version.",
+			calls: []call{
+				{key: "a", version: "1"},
+				{key: "a", version: "1"},
+			},
+			want: want{calls: 1},
+		},
+		"NewVersion": {
+			reason: "Credentials should be produced again when the version changes.",
+			calls: []call{
+				{key: "a", version: "1"},
+				{key: "a", version: "2"},
+				{key: "a", version: "2"},
+			},
+			want: want{calls: 2},
+		},
+		"DifferentKeys": {
+			reason: "Credentials should be cached per key.",
+			calls: []call{
+				{key: "a", version: "1"},
+				{key: "b", version: "1"},
+				{key: "a", version: "1"},
+			},
+			want: want{calls: 2},
+		},
+		"UnknownVersion": {
+			reason: "Credentials of an unknown version should never be cached.",
+			calls: []call{
+				{key: "a"},
+				{key: "a"},
+			},
+			want: want{calls: 2},
+		},
+		"NotExpired": {
+			reason: "Credentials should be cached until their TTL has elapsed.",
+			calls: []call{
+				{key: "a", version: "1", ttl: time.Minute},
+				{key: "a", version: "1", ttl: time.Minute, elapsed: 30 * time.Second},
+			},
+			want: want{calls: 1},
+		},
+		"Expired": {
+			reason: "Credentials should be produced again once their TTL has elapsed, even if their version is unchanged.",
+			calls: []call{
+				{key: "a", version: "1", ttl: time.Minute},
+				{key: "a", version: "1", ttl: time.Minute, elapsed: time.Minute},
+				{key: "a", version: "1", ttl: time.Minute, elapsed: 30 * time.Second},
+			},
+			want: want{calls: 2},
+		},
+		"NoTTL": {
+			reason: "Credentials without a TTL should be cached until their version changes.",
+			calls: []call{
+				{key: "a", version: "1"},
+				{key: "a", version: "1", elapsed: 24 * time.Hour},
+			},
+			want: want{calls: 1},
+		},
+		"Error": {
+			reason: "Errors producing credentials should be returned, and not cached.",
+			calls: []call{
+				{key: "a", version: "1", err: errBoom},
+				{key: "a", version: "1", err: errBoom},
+			},
+			want: want{calls: 2, err: errBoom},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewAuthorizerCache()
+			now := time.Now()
+			c.now = func() time.Time { return now }
+			calls := 0
+			var err error
+			for _, cl := range tc.calls {
+				cl := cl
+				now = now.Add(cl.elapsed)
+				_, err = c.Get(cl.key, cl.version, cl.ttl, func() (map[string]string, error) {
+					calls++
+					return map[string]string{}, cl.err
+				})
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAuthorizerCacheEntry(t *testing.T) {
+	c := NewAuthorizerCache()
+	creds := map[string]string{
+		CredentialsKeyActiveDirectoryEndpointURL: "https://login.microsoftonline.com/",
+		CredentialsKeyTenantID:                   "cool-tenant",
+		CredentialsKeyClientID:                   "cool-client",
+		CredentialsKeyClientSecret:               "cool-secret",
+	}
+	e, err := c.Get("a", "1", 0, func() (map[string]string, error) { return creds, nil })
+	if err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+
+	got := e.Credentials()
+	got[CredentialsKeyClientID] = "boring-client"
+	if diff := cmp.Diff(creds, e.Credentials()); diff != "" {
+		t.Errorf("Credentials(): modifying the returned credentials should not modify the entry: -want, +got:\n%s", diff)
+	}
+
+	a1, err := e.Authorizer("https://management.azure.com/")
+	if err != nil {
+		t.Fatalf("Authorizer(...): %v", err)
+	}
+	a2, err := e.Authorizer("https://management.azure.com/")
+	if err != nil {
+		t.Fatalf("Authorizer(...): %v", err)
+	}
+	if a1 != a2 {
+		t.Errorf("Authorizer(...): expected the authorizer for a resource to be reused")
+	}
+	g, err := e.Authorizer("https://graph.windows.net/")
+	if err != nil {
+		t.Fatalf("Authorizer(...): %v", err)
+	}
+	if g == a1 {
+		t.Errorf("Authorizer(...): expected a distinct authorizer for each resource")
+	}
+}
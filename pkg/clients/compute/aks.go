@@ -19,6 +19,7 @@ package compute
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/authorization/mgmt/2015-07-01/authorization"
@@ -26,7 +27,6 @@ import (
 	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2018-03-31/containerservice"
 	"github.com/Azure/azure-sdk-for-go/services/graphrbac/1.6/graphrbac"
 	"github.com/Azure/go-autorest/autorest"
-	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/date"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/uuid"
@@ -58,6 +58,7 @@ type AKSClient interface {
 	EnsureManagedCluster(ctx context.Context, ac *v1alpha3.AKSCluster, secret string) error
 	DeleteManagedCluster(ctx context.Context, ac *v1alpha3.AKSCluster) error
 	GetKubeConfig(ctx context.Context, ac *v1alpha3.AKSCluster) ([]byte, error)
+	GetRESTClient() autorest.Sender
 }
 
 // An AggregateClient aggregates the various clients used by the AKS controller.
@@ -69,47 +70,27 @@ type AggregateClient struct {
 }
 
 // NewAggregateClient produces the various clients used by the AKS controller.
-func NewAggregateClient(creds map[string]string, auth autorest.Authorizer) (AKSClient, error) {
-	mcc := containerservice.NewManagedClustersClient(creds[azure.CredentialsKeySubscriptionID])
-	mcc.Authorizer = auth
-	_ = mcc.AddToUserAgent(azure.UserAgent)
+// The supplied graph authorizer is used to manage the cluster's service
+// principal, and the other authorizer to manage everything else.
+func NewAggregateClient(creds map[string]string, auth, graph autorest.Authorizer) AKSClient {
+	mcc := containerservice.NewManagedClustersClientWithBaseURI(creds[azure.CredentialsKeyResourceManagerEndpointURL], creds[azure.CredentialsKeySubscriptionID])
+	azure.ConfigureClient(&mcc.Client, auth)
 
-	rac := authorization.NewRoleAssignmentsClient(creds[azure.CredentialsKeySubscriptionID])
-	rac.Authorizer = auth
-	_ = rac.AddToUserAgent(azure.UserAgent)
+	rac := authorization.NewRoleAssignmentsClientWithBaseURI(creds[azure.CredentialsKeyResourceManagerEndpointURL], creds[azure.CredentialsKeySubscriptionID])
+	azure.ConfigureClient(&rac.Client, auth)
 
-	cfg, err := adal.NewOAuthConfig(creds[azure.CredentialsKeyActiveDirectoryEndpointURL], creds[azure.CredentialsKeyTenantID])
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot create OAuth configuration")
-	}
-
-	token, err := adal.NewServicePrincipalToken(*cfg,
-		creds[azure.CredentialsKeyClientID],
-		creds[azure.CredentialsKeyClientSecret],
-		creds[azure.CredentialsKeyActiveDirectoryGraphResourceID])
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot create service principal token")
-	}
-	if err := token.Refresh(); err != nil {
-		return nil, errors.Wrap(err, "cannot refresh service principal token")
-	}
+	ac := graphrbac.NewApplicationsClientWithBaseURI(creds[azure.CredentialsKeyActiveDirectoryGraphResourceID], creds[azure.CredentialsKeyTenantID])
+	azure.ConfigureClient(&ac.Client, graph)
 
-	ta := autorest.NewBearerAuthorizer(token)
-
-	ac := graphrbac.NewApplicationsClient(creds[azure.CredentialsKeyTenantID])
-	ac.Authorizer = ta
-	_ = ac.AddToUserAgent(azure.UserAgent)
-
-	spc := graphrbac.NewServicePrincipalsClient(creds[azure.CredentialsKeyTenantID])
-	spc.Authorizer = ta
-	_ = spc.AddToUserAgent(azure.UserAgent)
+	spc := graphrbac.NewServicePrincipalsClientWithBaseURI(creds[azure.CredentialsKeyActiveDirectoryGraphResourceID], creds[azure.CredentialsKeyTenantID])
+	azure.ConfigureClient(&spc.Client, graph)
 
 	return AggregateClient{
 		ManagedClusters:   mcc,
 		Applications:      ac,
 		ServicePrincipals: spc,
 		RoleAssignments:   rac,
-	}, nil
+	}
 }
 
 // GetManagedCluster returns the requested Azure managed cluster.
@@ -135,8 +116,12 @@ func (c AggregateClient) EnsureManagedCluster(ctx context.Context, ac *v1alpha3.
 	}
 
 	mc := newManagedCluster(ac, to.String(app.AppID), secret)
-	_, err = c.ManagedClusters.CreateOrUpdate(ctx, ac.Spec.ResourceGroupName, meta.GetExternalName(ac), mc)
-	return err
+	op, err := c.ManagedClusters.CreateOrUpdate(ctx, ac.Spec.ResourceGroupName, meta.GetExternalName(ac), mc)
+	if err != nil {
+		return err
+	}
+	ac.Status.LastOperation = azure.NewAsyncOperation(http.MethodPut, op.Future)
+	return nil
 }
 
 // DeleteManagedCluster deletes the supplied AKS cluster, including its service
@@ -145,8 +130,18 @@ func (c AggregateClient) DeleteManagedCluster(ctx context.Context, ac *v1alpha3.
 	if err := c.deleteApplication(ctx, meta.GetExternalName(ac)); err != nil {
 		return err
 	}
-	_, err := c.ManagedClusters.Delete(ctx, ac.Spec.ResourceGroupName, meta.GetExternalName(ac))
-	return err
+	op, err := c.ManagedClusters.Delete(ctx, ac.Spec.ResourceGroupName, meta.GetExternalName(ac))
+	if err != nil {
+		return err
+	}
+	ac.Status.LastOperation = azure.NewAsyncOperation(http.MethodDelete, op.Future)
+	return nil
+}
+
+// GetRESTClient returns the underlying REST client used to manage AKS
+// clusters.
+func (c AggregateClient) GetRESTClient() autorest.Sender {
+	return c.ManagedClusters.Client
 }
 
 // GetKubeConfig produces a kubeconfig file that configures access to the
@@ -273,6 +268,7 @@ func newManagedCluster(c *v1alpha3.AKSCluster, appID, secret string) containerse
 	p := containerservice.ManagedCluster{
 		Name:     to.StringPtr(meta.GetExternalName(c)),
 		Location: to.StringPtr(c.Spec.Location),
+		Tags:     azure.AddOwnerTags(nil, c),
 		ManagedClusterProperties: &containerservice.ManagedClusterProperties{
 			KubernetesVersion: to.StringPtr(c.Spec.Version),
 			DNSPrefix:         to.StringPtr(c.Spec.DNSNamePrefix),
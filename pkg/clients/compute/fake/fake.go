@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2018-03-31/containerservice"
+	"github.com/Azure/go-autorest/autorest"
 
 	"github.com/crossplane/provider-azure/apis/compute/v1alpha3"
 )
@@ -30,6 +31,7 @@ type AKSClient struct {
 	MockEnsureManagedCluster func(ctx context.Context, ac *v1alpha3.AKSCluster, secret string) error
 	MockDeleteManagedCluster func(ctx context.Context, ac *v1alpha3.AKSCluster) error
 	MockGetKubeConfig        func(ctx context.Context, ac *v1alpha3.AKSCluster) ([]byte, error)
+	MockGetRESTClient        func() autorest.Sender
 }
 
 // GetManagedCluster calls MockGetManagedCluster.
@@ -51,3 +53,8 @@ func (c AKSClient) DeleteManagedCluster(ctx context.Context, ac *v1alpha3.AKSClu
 func (c AKSClient) GetKubeConfig(ctx context.Context, ac *v1alpha3.AKSCluster) ([]byte, error) {
 	return c.MockGetKubeConfig(ctx, ac)
 }
+
+// GetRESTClient calls MockGetRESTClient.
+func (c AKSClient) GetRESTClient() autorest.Sender {
+	return c.MockGetRESTClient()
+}
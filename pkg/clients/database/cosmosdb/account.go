@@ -51,12 +51,8 @@ func NewDatabaseAccountClient(credentials []byte) (AccountClient, error) {
 		return nil, errors.Wrap(err, "failed to get authorizer from config")
 	}
 
-	client := documentdb.NewDatabaseAccountsClient(creds.SubscriptionID)
-	client.Authorizer = authorizer
-
-	if err := client.AddToUserAgent(azure.UserAgent); err != nil {
-		return nil, errors.Wrap(err, "cannot add to Azure client user agent")
-	}
+	client := documentdb.NewDatabaseAccountsClientWithBaseURI(creds.BaseURI(), creds.SubscriptionID)
+	azure.ConfigureClient(&client.Client, authorizer)
 
 	return client, nil
 }
@@ -99,7 +95,9 @@ func toDatabaseProperties(a *v1alpha3.CosmosDBAccountProperties) *documentdb.Dat
 	}
 }
 
-func fromDatabaseProperties(a *documentdb.DatabaseAccountProperties) v1alpha3.CosmosDBAccountProperties {
+// FromDatabaseProperties returns the CosmosDBAccountProperties of the supplied
+// documentdb.DatabaseAccountProperties.
+func FromDatabaseProperties(a *documentdb.DatabaseAccountProperties) v1alpha3.CosmosDBAccountProperties {
 	if a == nil {
 		return v1alpha3.CosmosDBAccountProperties{}
 	}
@@ -119,7 +117,7 @@ func fromDatabaseProperties(a *documentdb.DatabaseAccountProperties) v1alpha3.Co
 // CheckEqualDatabaseProperties compares the observed state with the desired
 // spec.
 func CheckEqualDatabaseProperties(p v1alpha3.CosmosDBAccountProperties, a documentdb.DatabaseAccount) bool {
-	o := fromDatabaseProperties(a.DatabaseAccountProperties)
+	o := FromDatabaseProperties(a.DatabaseAccountProperties)
 
 	// asouza: only keep attributes that can be modified in the comparison.
 	return (equalConsistencyPolicyIfNotNull(p.ConsistencyPolicy, o.ConsistencyPolicy) &&
@@ -128,6 +126,12 @@ func CheckEqualDatabaseProperties(p v1alpha3.CosmosDBAccountProperties, a docume
 		equalBoolIfNotNull(p.EnableMultipleWriteLocations, o.EnableMultipleWriteLocations))
 }
 
+// CheckEqualTags returns true if the supplied tags are those of the supplied
+// account, ignoring its owner tags.
+func CheckEqualTags(tags map[string]string, a documentdb.DatabaseAccount) bool {
+	return cmp.Equal(azure.ToStringPtrMap(tags), azure.RemoveOwnerTags(a.Tags), cmpopts.EquateEmpty())
+}
+
 func equalConsistencyPolicyIfNotNull(spec, current *v1alpha3.CosmosDBAccountConsistencyPolicy) bool {
 	if spec != nil {
 		return (spec == current) || (*spec == *current)
@@ -207,3 +207,61 @@ func TestCheckEqualDatabaseProperties(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckEqualTags(t *testing.T) {
+	type args struct {
+		tags map[string]string
+		a    documentdb.DatabaseAccount
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   bool
+	}{
+		"Equal": {
+			reason: "Tags that are those of the account should be equal.",
+			args: args{
+				tags: map[string]string{"cool": "tag"},
+				a:    documentdb.DatabaseAccount{Tags: map[string]*string{"cool": azure.ToStringPtr("tag")}},
+			},
+			want: true,
+		},
+		"EqualIgnoringOwnerTags": {
+			reason: "The owner tags of the account should not be compared.",
+			args: args{
+				tags: map[string]string{"cool": "tag"},
+				a: documentdb.DatabaseAccount{Tags: map[string]*string{
+					"cool":                azure.ToStringPtr("tag"),
+					azure.TagKeyManagedBy: azure.ToStringPtr(azure.TagValueManagedBy),
+					azure.TagKeyOwnerName: azure.ToStringPtr("cool-account"),
+				}},
+			},
+			want: true,
+		},
+		"EqualOnlyOwnerTags": {
+			reason: "An account with only owner tags should equal no tags.",
+			args: args{
+				a: documentdb.DatabaseAccount{Tags: map[string]*string{
+					azure.TagKeyManagedBy: azure.ToStringPtr(azure.TagValueManagedBy),
+				}},
+			},
+			want: true,
+		},
+		"NotEqual": {
+			reason: "Tags that differ from those of the account should not be equal.",
+			args: args{
+				tags: map[string]string{"cool": "tag"},
+				a:    documentdb.DatabaseAccount{Tags: map[string]*string{"cool": azure.ToStringPtr("other")}},
+			},
+			want: false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := CheckEqualTags(tc.args.tags, tc.args.a)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nCheckEqualTags(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -500,6 +500,21 @@ func TestIsPostgreSQLUpToDate(t *testing.T) {
 			},
 			want: true,
 		},
+		"IsUpToDateWithOwnerTags": {
+			args: args{
+				p: v1beta1.SQLServerParameters{},
+				in: postgresql.Server{
+					Tags: map[string]*string{
+						azure.TagKeyOwnerUID: azure.ToStringPtr("definitely-a-uuid"),
+					},
+					Sku: &postgresql.Sku{},
+					ServerProperties: &postgresql.ServerProperties{
+						StorageProfile: &postgresql.StorageProfile{},
+					},
+				},
+			},
+			want: true,
+		},
 		"IsNotUpToDate": {
 			args: args{
 				p: v1beta1.SQLServerParameters{
@@ -543,9 +558,13 @@ func TestLateInitializePostgreSQL(t *testing.T) {
 		p  *v1beta1.SQLServerParameters
 		in postgresql.Server
 	}
+	type want struct {
+		p       *v1beta1.SQLServerParameters
+		changed bool
+	}
 	cases := map[string]struct {
 		args
-		want *v1beta1.SQLServerParameters
+		want
 	}{
 		"PublicNetworkAccessLateInitialize": {
 			args: args{
@@ -557,17 +576,44 @@ func TestLateInitializePostgreSQL(t *testing.T) {
 					},
 				},
 			},
-			want: &v1beta1.SQLServerParameters{
-				PublicNetworkAccess: azure.ToStringPtr("Enabled"),
+			want: want{
+				p: &v1beta1.SQLServerParameters{
+					PublicNetworkAccess: azure.ToStringPtr("Enabled"),
+				},
+				changed: true,
+			},
+		},
+		"AlreadyLateInitialized": {
+			args: args{
+				p: &v1beta1.SQLServerParameters{
+					PublicNetworkAccess: azure.ToStringPtr("Disabled"),
+				},
+				in: postgresql.Server{
+					Sku:  &postgresql.Sku{},
+					Tags: map[string]*string{},
+					ServerProperties: &postgresql.ServerProperties{
+						PublicNetworkAccess: postgresql.PublicNetworkAccessEnumEnabled,
+					},
+				},
+			},
+			want: want{
+				p: &v1beta1.SQLServerParameters{
+					PublicNetworkAccess: azure.ToStringPtr("Disabled"),
+					Tags:                map[string]string{},
+				},
+				changed: false,
 			},
 		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			LateInitializePostgreSQL(tc.args.p, tc.args.in)
-			if diff := cmp.Diff(tc.want, tc.args.p); diff != "" {
+			changed := LateInitializePostgreSQL(tc.args.p, tc.args.in)
+			if diff := cmp.Diff(tc.want.p, tc.args.p); diff != "" {
 				t.Errorf("LateInitializePostgreSQL(...): -want, +got\n%s", diff)
 			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("LateInitializePostgreSQL(...): -want changed, +got changed\n%s", diff)
+			}
 		})
 	}
 }
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/apis/v1beta1"
+)
+
+const errUpdateDefaults = "cannot update managed resource with ProviderConfig defaults"
+
+// A DefaultsFn sets the fields of the supplied managed resource that it omits
+// to the supplied defaults.
+type DefaultsFn func(mg resource.Managed, d *v1beta1.ResourceDefaults)
+
+// A DefaultsInitializer sets the fields of a managed resource that it omits to
+// the defaults of its ProviderConfig. Defaults are written to the managed
+// resource's spec, so that they are visible and do not change when the
+// ProviderConfig's defaults do. Mandatory tags are the exception; they're
+// merged into the managed resource's tags every time it is reconciled.
+type DefaultsInitializer struct {
+	client client.Client
+	fn     DefaultsFn
+}
+
+// NewDefaultsInitializer returns a DefaultsInitializer that uses the supplied
+// function to apply defaults.
+func NewDefaultsInitializer(c client.Client, fn DefaultsFn) *DefaultsInitializer {
+	return &DefaultsInitializer{client: c, fn: fn}
+}
+
+// Initialize the supplied managed resource with the defaults of its
+// ProviderConfig. Managed resources that reference a Provider rather than a
+// ProviderConfig are not defaulted.
+func (i *DefaultsInitializer) Initialize(ctx context.Context, mg resource.Managed) error {
+	ref := mg.GetProviderConfigReference()
+	if ref == nil {
+		return nil
+	}
+	pc := &v1beta1.ProviderConfig{}
+	if err := i.client.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+		return errors.Wrap(err, errGetProviderConfig)
+	}
+	if pc.Spec.Defaults == nil {
+		return nil
+	}
+	existing := mg.DeepCopyObject()
+	i.fn(mg, pc.Spec.Defaults)
+	if equality.Semantic.DeepEqual(existing, mg) {
+		return nil
+	}
+	return errors.Wrap(i.client.Update(ctx, mg), errUpdateDefaults)
+}
+
+// DefaultLocation sets the supplied location to the default location, if it
+// is empty.
+func DefaultLocation(location *string, d *v1beta1.ResourceDefaults) {
+	if *location == "" && d.Location != nil {
+		*location = *d.Location
+	}
+}
+
+// DefaultResourceGroupName sets the supplied resource group name to the
+// default resource group name, if it is empty and would not otherwise be
+// resolved from the supplied reference or selector.
+func DefaultResourceGroupName(name *string, ref *xpv1.Reference, sel *xpv1.Selector, d *v1beta1.ResourceDefaults) {
+	if *name == "" && ref == nil && sel == nil && d.ResourceGroupName != nil {
+		*name = *d.ResourceGroupName
+	}
+}
+
+// MergeTags merges the default tags into the supplied tags, overwriting any
+// tags of the same key.
+func MergeTags(tags *map[string]string, d *v1beta1.ResourceDefaults) {
+	if len(d.Tags) == 0 {
+		return
+	}
+	if *tags == nil {
+		*tags = make(map[string]string, len(d.Tags))
+	}
+	for k, v := range d.Tags {
+		(*tags)[k] = v
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	networkv1alpha3 "github.com/crossplane/provider-azure/apis/network/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1beta1"
+)
+
+func TestDefaultsInitializer(t *testing.T) {
+	errBoom := errors.New("boom")
+	location := "westeurope"
+
+	withDefaults := func(d *v1beta1.ResourceDefaults) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			obj.(*v1beta1.ProviderConfig).Spec.Defaults = d
+			return nil
+		}
+	}
+	setLocation := func(mg resource.Managed, d *v1beta1.ResourceDefaults) {
+		DefaultLocation(&mg.(*networkv1alpha3.VirtualNetwork).Spec.Location, d)
+	}
+	vnet := func(location string) *networkv1alpha3.VirtualNetwork {
+		v := &networkv1alpha3.VirtualNetwork{}
+		v.SetProviderConfigReference(&xpv1.Reference{Name: "cool"})
+		v.Spec.Location = location
+		return v
+	}
+
+	type args struct {
+		kube client.Client
+		mg   resource.Managed
+	}
+	type want struct {
+		err error
+		mg  resource.Managed
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoProviderConfig": {
+			reason: "Managed resources that don't use a ProviderConfig should not be defaulted.",
+			args: args{
+				mg: &networkv1alpha3.VirtualNetwork{},
+			},
+			want: want{mg: &networkv1alpha3.VirtualNetwork{}},
+		},
+		"GetProviderConfigError": {
+			reason: "Errors getting the ProviderConfig should be returned.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				mg:   vnet(""),
+			},
+			want: want{err: errors.Wrap(errBoom, errGetProviderConfig), mg: vnet("")},
+		},
+		"NoDefaults": {
+			reason: "Managed resources should not be updated if their ProviderConfig has no defaults.",
+			args: args{
+				kube: &test.MockClient{MockGet: withDefaults(nil)},
+				mg:   vnet(""),
+			},
+			want: want{mg: vnet("")},
+		},
+		"Unchanged": {
+			reason: "Managed resources should not be updated if applying defaults changes nothing.",
+			args: args{
+				kube: &test.MockClient{MockGet: withDefaults(&v1beta1.ResourceDefaults{Location: &location})},
+				mg:   vnet("northeurope"),
+			},
+			want: want{mg: vnet("northeurope")},
+		},
+		"Defaulted": {
+			reason: "Managed resources should be updated with their defaults.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet:    withDefaults(&v1beta1.ResourceDefaults{Location: &location}),
+					MockUpdate: test.NewMockUpdateFn(nil),
+				},
+				mg: vnet(""),
+			},
+			want: want{mg: vnet(location)},
+		},
+		"UpdateError": {
+			reason: "Errors updating the managed resource should be returned.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet:    withDefaults(&v1beta1.ResourceDefaults{Location: &location}),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				},
+				mg: vnet(""),
+			},
+			want: want{err: errors.Wrap(errBoom, errUpdateDefaults), mg: vnet(location)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NewDefaultsInitializer(tc.args.kube, setLocation).Initialize(context.Background(), tc.args.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nInitialize(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.mg, tc.args.mg); diff != "" {
+				t.Errorf("\n%s\nInitialize(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefaultResourceGroupName(t *testing.T) {
+	rg := "cool-group"
+	d := &v1beta1.ResourceDefaults{ResourceGroupName: &rg}
+
+	cases := map[string]struct {
+		reason string
+		name   string
+		ref    *xpv1.Reference
+		sel    *xpv1.Selector
+		want   string
+	}{
+		"Unset": {
+			reason: "An unset resource group name should be defaulted.",
+			want:   rg,
+		},
+		"Set": {
+			reason: "A set resource group name should not be overridden.",
+			name:   "other-group",
+			want:   "other-group",
+		},
+		"Referenced": {
+			reason: "A referenced resource group name should be resolved, not defaulted.",
+			ref:    &xpv1.Reference{Name: "other-group"},
+		},
+		"Selected": {
+			reason: "A selected resource group name should be resolved, not defaulted.",
+			sel:    &xpv1.Selector{MatchLabels: map[string]string{"cool": "true"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.name
+			DefaultResourceGroupName(&got, tc.ref, tc.sel, d)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDefaultResourceGroupName(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		tags   map[string]string
+		d      *v1beta1.ResourceDefaults
+		want   map[string]string
+	}{
+		"NoDefaultTags": {
+			reason: "Tags should be unchanged if there are no default tags.",
+			d:      &v1beta1.ResourceDefaults{},
+		},
+		"NoTags": {
+			reason: "Default tags should be added to a resource with no tags.",
+			d:      &v1beta1.ResourceDefaults{Tags: map[string]string{"cost-center": "cool"}},
+			want:   map[string]string{"cost-center": "cool"},
+		},
+		"Merged": {
+			reason: "Default tags should take precedence over a resource's tags.",
+			tags:   map[string]string{"cost-center": "boring", "team": "cool"},
+			d:      &v1beta1.ResourceDefaults{Tags: map[string]string{"cost-center": "cool"}},
+			want:   map[string]string{"cost-center": "cool", "team": "cool"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			MergeTags(&tc.tags, tc.d)
+			if diff := cmp.Diff(tc.want, tc.tags); diff != "" {
+				t.Errorf("\n%s\nMergeTags(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// The response to requests that are not sent. Its status code is not one the
+// Azure SDK retries, so that each request is recorded, and would be reported,
+// only once.
+const (
+	dryRunStatusCode = http.StatusNotImplemented
+	dryRunBody       = `{"error":{"code":"DryRun","message":"request not sent to Azure in dry-run mode"}}`
+)
+
+// A DryRunRequest is a request that was not sent to Azure because it would
+// have modified an Azure resource.
+type DryRunRequest struct {
+	Method string
+	URL    *url.URL
+	Body   []byte
+
+	// Authorizer the request would have been sent with.
+	Authorizer autorest.Authorizer
+}
+
+// A DryRun records the requests that would have modified Azure resources,
+// rather than sending them.
+type DryRun struct {
+	mu       sync.Mutex
+	requests []DryRunRequest
+}
+
+// Requests returns the requests that were not sent, in the order they would
+// have been sent.
+func (d *DryRun) Requests() []DryRunRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DryRunRequest(nil), d.requests...)
+}
+
+func (d *DryRun) record(r DryRunRequest) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, r)
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context that records the requests sent using it that
+// would modify an Azure resource in the supplied DryRun, rather than sending
+// them. Such requests fail with a 501 Not Implemented response.
+func WithDryRun(ctx context.Context, d *DryRun) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, d)
+}
+
+// DryRunOf returns the DryRun of the supplied context, if any.
+func DryRunOf(ctx context.Context) *DryRun {
+	d, _ := ctx.Value(dryRunKey{}).(*DryRun)
+	return d
+}
+
+// DryRunSendDecorator returns an autorest SendDecorator that records all but
+// the GET and HEAD requests whose context was returned by WithDryRun, rather
+// than sending them, along with the supplied authorizer. It should be the
+// outermost decorator of a client's Sender, so that requests that are not
+// sent are not recorded as requests to Azure.
+func DryRunSendDecorator(a autorest.Authorizer) autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			d := DryRunOf(r.Context())
+			if d == nil || r.Method == http.MethodGet || r.Method == http.MethodHead {
+				return s.Do(r)
+			}
+			var body []byte
+			if r.Body != nil {
+				b, err := ioutil.ReadAll(r.Body)
+				_ = r.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+				body = b
+			}
+			d.record(DryRunRequest{Method: r.Method, URL: r.URL, Body: body, Authorizer: a})
+			return &http.Response{
+				Status:        http.StatusText(dryRunStatusCode),
+				StatusCode:    dryRunStatusCode,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{"Content-Type": []string{"application/json"}},
+				Body:          ioutil.NopCloser(strings.NewReader(dryRunBody)),
+				ContentLength: int64(len(dryRunBody)),
+				Request:       r,
+			}, nil
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDryRunSendDecorator(t *testing.T) {
+	type want struct {
+		sent     bool
+		recorded []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		dryRun bool
+		method string
+		want   want
+	}{
+		"NotDryRun": {
+			reason: "Requests should be sent if their context has no DryRun.",
+			method: http.MethodPut,
+			want:   want{sent: true},
+		},
+		"Get": {
+			reason: "GET requests should be sent in dry-run mode.",
+			dryRun: true,
+			method: http.MethodGet,
+			want:   want{sent: true},
+		},
+		"Put": {
+			reason: "PUT requests should be recorded rather than sent in dry-run mode.",
+			dryRun: true,
+			method: http.MethodPut,
+			want:   want{recorded: []string{`PUT {"cool":true}`}},
+		},
+		"Delete": {
+			reason: "DELETE requests should be recorded rather than sent in dry-run mode.",
+			dryRun: true,
+			method: http.MethodDelete,
+			want:   want{recorded: []string{`DELETE {"cool":true}`}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sent := false
+			s := autorest.DecorateSender(autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+				sent = true
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}), DryRunSendDecorator(nil))
+
+			ctx := context.Background()
+			d := &DryRun{}
+			if tc.dryRun {
+				ctx = WithDryRun(ctx, d)
+			}
+			req, _ := http.NewRequestWithContext(ctx, tc.method, "https://management.azure.com/", strings.NewReader(`{"cool":true}`))
+			rsp, err := s.Do(req)
+			if err != nil {
+				t.Fatalf("\n%s\nDryRunSendDecorator(): %s", tc.reason, err)
+			}
+
+			if diff := cmp.Diff(tc.want.sent, sent); diff != "" {
+				t.Errorf("\n%s\nDryRunSendDecorator(): -want sent, +got sent:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sent, rsp.StatusCode == http.StatusOK); diff != "" {
+				t.Errorf("\n%s\nDryRunSendDecorator(): -want success, +got success:\n%s", tc.reason, diff)
+			}
+			var recorded []string
+			for _, r := range d.Requests() {
+				recorded = append(recorded, r.Method+" "+string(r.Body))
+			}
+			if diff := cmp.Diff(tc.want.recorded, recorded); diff != "" {
+				t.Errorf("\n%s\nDryRunSendDecorator(): -want recorded, +got recorded:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDryRunSDKClient(t *testing.T) {
+	cl := network.NewVirtualNetworksClientWithBaseURI("https://management.azure.com", "cool-sub")
+	ConfigureClient(&cl.Client, autorest.NullAuthorizer{})
+	// Keep the test fast should the SDK retry requests that were not sent.
+	cl.RetryDuration = time.Millisecond
+
+	d := &DryRun{}
+	ctx := WithDryRun(context.Background(), d)
+	if _, err := cl.CreateOrUpdate(ctx, "coolRG", "coolVNet", network.VirtualNetwork{Location: to.StringPtr("westus")}); err == nil {
+		t.Errorf("CreateOrUpdate(...): requests that are not sent should fail")
+	}
+
+	var recorded []string
+	for _, r := range d.Requests() {
+		recorded = append(recorded, r.Method+" "+r.URL.Path)
+	}
+	want := []string{"PUT /subscriptions/cool-sub/resourceGroups/coolRG/providers/Microsoft.Network/virtualNetworks/coolVNet"}
+	if diff := cmp.Diff(want, recorded); diff != "" {
+		t.Errorf("CreateOrUpdate(...): each request that is not sent should be recorded once: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// HeaderIfMatch makes a request to Azure Resource Manager conditional on the
+// etag of the resource it modifies.
+const HeaderIfMatch = "If-Match"
+
+type ifMatchKey struct{}
+
+// WithIfMatch returns a context that makes the PUT and PATCH requests sent
+// using it conditional on the supplied etag, i.e. Azure rejects them with 412
+// Precondition Failed if the resource was modified since the etag was
+// observed. Requests are unconditional if the etag is empty.
+//
+// Only virtual networks and subnets are updated conditionally. They are the
+// only resources managed by the provider whose API versions return an etag;
+// e.g. Redis caches, storage accounts, and MySQL and PostgreSQL servers and
+// their firewall and virtual network rules don't. Other resources should be
+// updated using WithIfMatch if they begin to return one.
+func WithIfMatch(ctx context.Context, etag string) context.Context {
+	return context.WithValue(ctx, ifMatchKey{}, etag)
+}
+
+// IfMatch returns the etag the supplied context makes requests conditional
+// on, if any.
+func IfMatch(ctx context.Context) string {
+	etag, _ := ctx.Value(ifMatchKey{}).(string)
+	return etag
+}
+
+// IfMatchSendDecorator returns an autorest SendDecorator that adds an If-Match
+// header to the PUT and PATCH requests whose context was returned by
+// WithIfMatch.
+func IfMatchSendDecorator() autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			etag := IfMatch(r.Context())
+			if etag == "" || (r.Method != http.MethodPut && r.Method != http.MethodPatch) || r.Header.Get(HeaderIfMatch) != "" {
+				return s.Do(r)
+			}
+			r = r.Clone(r.Context())
+			r.Header.Set(HeaderIfMatch, etag)
+			return s.Do(r)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIfMatchSendDecorator(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		ctx    context.Context
+		method string
+		want   string
+	}{
+		"Unconditional": {
+			reason: "Requests should be unconditional if their context has no etag.",
+			ctx:    context.Background(),
+			method: http.MethodPut,
+		},
+		"EmptyEtag": {
+			reason: "Requests should be unconditional if their context has an empty etag.",
+			ctx:    WithIfMatch(context.Background(), ""),
+			method: http.MethodPut,
+		},
+		"Put": {
+			reason: "PUT requests should be conditional on the etag of their context.",
+			ctx:    WithIfMatch(context.Background(), `W/"cool"`),
+			method: http.MethodPut,
+			want:   `W/"cool"`,
+		},
+		"Patch": {
+			reason: "PATCH requests should be conditional on the etag of their context.",
+			ctx:    WithIfMatch(context.Background(), `W/"cool"`),
+			method: http.MethodPatch,
+			want:   `W/"cool"`,
+		},
+		"Get": {
+			reason: "GET requests should never be conditional.",
+			ctx:    WithIfMatch(context.Background(), `W/"cool"`),
+			method: http.MethodGet,
+		},
+		"Delete": {
+			reason: "DELETE requests should never be conditional.",
+			ctx:    WithIfMatch(context.Background(), `W/"cool"`),
+			method: http.MethodDelete,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got string
+			s := autorest.DecorateSender(autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+				got = r.Header.Get(HeaderIfMatch)
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}), IfMatchSendDecorator())
+			req, _ := http.NewRequestWithContext(tc.ctx, tc.method, "https://management.azure.com/", nil)
+			if _, err := s.Do(req); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIfMatchSendDecorator(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
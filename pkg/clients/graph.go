@@ -0,0 +1,489 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resourcegraph/mgmt/2019-04-01/resourcegraph"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+)
+
+// HeaderGraphCache is set on responses served from the GraphCache, to tell
+// them apart from responses served by Azure Resource Manager.
+const HeaderGraphCache = "x-crossplane-graph-cache"
+
+const (
+	// graphPageSize is the number of resources queried from Resource Graph
+	// per request. It is the most Resource Graph allows.
+	graphPageSize = 1000
+
+	// writeGrace is how long resources are read directly from Azure Resource
+	// Manager after they're written. Resource Graph takes a while to reflect
+	// writes, and long-running operations take a while to complete.
+	writeGrace = 10 * time.Minute
+
+	// maxSnapshotAge is how many refresh intervals old a snapshot may be
+	// before it is no longer served, e.g. because refreshing it failed.
+	maxSnapshotAge = 3
+
+	// untrackAfter is how long a resource is queried for after it was last
+	// requested. Resources stop being requested when, for example, their
+	// managed resource is deleted without deleting them.
+	untrackAfter = time.Hour
+
+	// graphQueryIDs is the number of resource IDs queried per query, which
+	// keeps queries well below the length Resource Graph allows.
+	graphQueryIDs = 200
+)
+
+const errQueryGraph = "cannot query Azure Resource Graph"
+
+// graphCacheTypes are the types of Azure resource served from Resource Graph.
+// Resource Graph returns a resource's properties as a GET request would, but
+// not all of its fields; e.g. it omits the etags virtual networks and subnets
+// are conditionally updated using. Only top-level resources are served.
+var graphCacheTypes = []string{
+	"microsoft.cache/redis",
+	"microsoft.containerservice/managedclusters",
+	"microsoft.dbformysql/servers",
+	"microsoft.dbforpostgresql/servers",
+	"microsoft.documentdb/databaseaccounts",
+	"microsoft.storage/storageaccounts",
+}
+
+// graphCache serves the GET requests of the clients configured by
+// ConfigureClient once it is started.
+var graphCache = NewGraphCache()
+
+var graphCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "crossplane",
+	Subsystem: "azure_api",
+	Name:      "graph_cache_requests_total",
+	Help:      "Number of GET requests to Azure Resource Manager served from the Azure Resource Graph cache, or missed.",
+}, []string{LabelService, LabelOperation, "result"})
+
+func init() {
+	metrics.Registry.MustRegister(graphCacheRequests)
+}
+
+// A GraphQueryFn queries Resource Graph for the resources of the supplied
+// subscription with the supplied IDs, using the supplied authorizer and Azure
+// Resource Manager endpoint. It returns their JSON representations by their
+// IDs.
+type GraphQueryFn func(ctx context.Context, sub, endpoint string, a autorest.Authorizer, ids []string) (map[string]json.RawMessage, error)
+
+// A graphKey identifies a subscription the GraphCache queries, and the
+// authorizer it queries it with. Authorizers are cached per ProviderConfig, so
+// resources are queried, and served, separately for each ProviderConfig that
+// requests them; a ProviderConfig is never served a resource it could not
+// read itself.
+type graphKey struct {
+	sub        string
+	authorizer autorest.Authorizer
+}
+
+// graphSubscription is a subscription the GraphCache queries, and the IDs of
+// the resources it queries by when they were last requested.
+type graphSubscription struct {
+	endpoint string
+	ids      map[string]time.Time
+}
+
+// A GraphCache periodically queries Azure Resource Graph for the Azure
+// resources the provider reconciles, and serves GET requests for them from
+// its most recent snapshot rather than Azure Resource Manager. A resource is
+// queried once it has been requested, i.e. once a managed resource that
+// represents it has been observed, until it is deleted or no longer requested.
+// Requests are sent to Azure Resource Manager if the GraphCache has not been
+// started, if its snapshot is too old, if the resource is not in it, or if the
+// resource was written recently.
+//
+// Only writes made by the provider bypass the snapshot. A resource that was
+// changed out of band is served as it was when the snapshot was taken, which
+// may be up to maxSnapshotAge refresh intervals ago. This includes reconciles
+// triggered by an Event Grid notification of such a change; they observe the
+// change once the snapshot is refreshed.
+type GraphCache struct {
+	mu        sync.RWMutex
+	interval  time.Duration
+	at        time.Time
+	resources map[graphKey]map[string]json.RawMessage
+	writes    map[string]time.Time
+	subs      map[graphKey]*graphSubscription
+
+	query GraphQueryFn
+	log   logging.Logger
+	now   func() time.Time
+}
+
+// A GraphCacheOption configures a GraphCache.
+type GraphCacheOption func(*GraphCache)
+
+// WithGraphQueryFn specifies how a GraphCache should query Resource Graph.
+func WithGraphQueryFn(fn GraphQueryFn) GraphCacheOption {
+	return func(c *GraphCache) {
+		c.query = fn
+	}
+}
+
+// NewGraphCache returns a GraphCache that serves no requests until it is
+// started.
+func NewGraphCache(o ...GraphCacheOption) *GraphCache {
+	c := &GraphCache{
+		resources: map[graphKey]map[string]json.RawMessage{},
+		writes:    map[string]time.Time{},
+		subs:      map[graphKey]*graphSubscription{},
+		query:     QueryGraph,
+		log:       logging.NewNopLogger(),
+		now:       time.Now,
+	}
+	for _, fn := range o {
+		fn(c)
+	}
+	return c
+}
+
+// NewGraphCacheRunnable returns a Runnable that refreshes the GraphCache used
+// by the clients configured by ConfigureClient at the supplied interval.
+func NewGraphCacheRunnable(l logging.Logger, interval time.Duration) manager.Runnable {
+	return manager.RunnableFunc(func(ctx context.Context) error {
+		graphCache.log = l
+		return graphCache.Run(ctx, interval)
+	})
+}
+
+// Run the GraphCache, refreshing its snapshot at the supplied interval until
+// the supplied context is done.
+func (c *GraphCache) Run(ctx context.Context, interval time.Duration) error {
+	c.mu.Lock()
+	c.interval = interval
+	c.mu.Unlock()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		c.Refresh(ctx)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Refresh the GraphCache's snapshot of the resources that requests have been
+// made for. Subscriptions that cannot be queried are omitted from the
+// snapshot, and thus read directly from Azure Resource Manager.
+func (c *GraphCache) Refresh(ctx context.Context) {
+	at := c.now()
+	subs := c.untrack(at)
+
+	resources := map[graphKey]map[string]json.RawMessage{}
+	n := 0
+	for k, gs := range subs {
+		rs, err := c.query(ctx, k.sub, gs.endpoint, k.authorizer, gs.list())
+		if err != nil {
+			c.log.Info("Cannot refresh Azure Resource Graph cache", "subscription", k.sub, "error", err)
+			continue
+		}
+		resources[k] = make(map[string]json.RawMessage, len(rs))
+		for id, r := range rs {
+			resources[k][strings.ToLower(strings.Trim(id, "/"))] = r
+		}
+		n += len(rs)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.at = at
+	c.resources = resources
+	for p, w := range c.writes {
+		if at.Sub(w) > writeGrace {
+			delete(c.writes, p)
+		}
+	}
+	c.log.Debug("Refreshed Azure Resource Graph cache", "subscriptions", len(subs), "resources", n)
+}
+
+// untrack the resources that have not been requested recently, and return a
+// copy of the subscriptions that still have resources to query.
+func (c *GraphCache) untrack(at time.Time) map[graphKey]graphSubscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	subs := make(map[graphKey]graphSubscription, len(c.subs))
+	for k, gs := range c.subs {
+		ids := make(map[string]time.Time, len(gs.ids))
+		for id, t := range gs.ids {
+			if at.Sub(t) > untrackAfter {
+				delete(gs.ids, id)
+				continue
+			}
+			ids[id] = t
+		}
+		if len(ids) == 0 {
+			delete(c.subs, k)
+			continue
+		}
+		subs[k] = graphSubscription{endpoint: gs.endpoint, ids: ids}
+	}
+	return subs
+}
+
+// list the IDs of the subscription's resources, in order.
+func (gs graphSubscription) list() []string {
+	ids := make([]string, 0, len(gs.ids))
+	for id := range gs.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SendDecorator returns an autorest SendDecorator that serves GET requests
+// made using the supplied authorizer from the GraphCache when it can, and
+// tracks them so that they may be. It should be the outermost decorator of a
+// client's Sender, so that cached responses are not recorded as requests to
+// Azure. Requests are neither tracked nor served until the GraphCache is
+// started.
+func (c *GraphCache) SendDecorator(a autorest.Authorizer) autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			if !c.started() {
+				return s.Do(r)
+			}
+			path := strings.ToLower(strings.Trim(r.URL.Path, "/"))
+			switch r.Method {
+			case http.MethodPut, http.MethodPatch:
+				c.written(path)
+				return s.Do(r)
+			case http.MethodDelete:
+				c.written(path)
+				c.untrackPath(path)
+				return s.Do(r)
+			case http.MethodGet:
+				c.track(path, r.URL.Scheme+"://"+r.URL.Host, a)
+			default:
+				// POST requests are typically actions that don't modify
+				// the resource, e.g. listing its keys.
+				return s.Do(r)
+			}
+			body, ok := c.get(path, a, r)
+			service, operation := Operation(r)
+			if !ok {
+				graphCacheRequests.WithLabelValues(service, operation, "miss").Inc()
+				return s.Do(r)
+			}
+			graphCacheRequests.WithLabelValues(service, operation, "hit").Inc()
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", http.StatusOK, http.StatusText(http.StatusOK)),
+				StatusCode:    http.StatusOK,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        http.Header{"Content-Type": {"application/json; charset=utf-8"}, HeaderGraphCache: {"hit"}},
+				Body:          ioutil.NopCloser(bytes.NewReader(body)),
+				ContentLength: int64(len(body)),
+				Request:       r,
+			}, nil
+		})
+	}
+}
+
+// started returns true if the GraphCache has been started.
+func (c *GraphCache) started() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.interval > 0
+}
+
+// track the resource at the supplied path, so that it is queried using the
+// supplied authorizer when the GraphCache is next refreshed, if it is of a
+// type that is cached.
+func (c *GraphCache) track(path, endpoint string, a autorest.Authorizer) {
+	if !cacheable(path) {
+		return
+	}
+	k := graphKey{sub: SubscriptionFromPath(path), authorizer: a}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	gs, ok := c.subs[k]
+	if !ok {
+		gs = &graphSubscription{ids: map[string]time.Time{}}
+		c.subs[k] = gs
+	}
+	gs.endpoint = endpoint
+	gs.ids["/"+path] = c.now()
+}
+
+// untrackPath stops querying the resource at the supplied path, whichever
+// authorizer it was requested with.
+func (c *GraphCache) untrackPath(path string) {
+	sub := SubscriptionFromPath(path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, gs := range c.subs {
+		if k.sub == sub {
+			delete(gs.ids, "/"+path)
+		}
+	}
+}
+
+func (c *GraphCache) written(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writes[path] = c.now()
+}
+
+// get the cached representation of the resource at the supplied path, as
+// queried using the supplied authorizer.
+func (c *GraphCache) get(path string, a autorest.Authorizer, r *http.Request) ([]byte, bool) {
+	// Requests that expand or filter a resource can't be served from its
+	// representation in Resource Graph.
+	for k := range r.URL.Query() {
+		if k != "api-version" {
+			return nil, false
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.interval == 0 || c.now().Sub(c.at) > maxSnapshotAge*c.interval {
+		return nil, false
+	}
+	body, ok := c.resources[graphKey{sub: SubscriptionFromPath(path), authorizer: a}][path]
+	if !ok {
+		return nil, false
+	}
+	for p, w := range c.writes {
+		if related(p, path) && c.now().Sub(w) < writeGrace {
+			return nil, false
+		}
+	}
+	return body, true
+}
+
+// cacheable returns true if the supplied lower case path is that of a
+// top-level resource of a type that is served from Resource Graph, i.e.
+// subscriptions/<sub>/resourcegroups/<group>/providers/<namespace>/<type>/<name>.
+func cacheable(path string) bool {
+	p := strings.Split(path, "/")
+	if len(p) != 8 || p[0] != "subscriptions" || p[2] != "resourcegroups" || p[4] != "providers" {
+		return false
+	}
+	t := p[5] + "/" + p[6]
+	for _, ct := range graphCacheTypes {
+		if t == ct {
+			return true
+		}
+	}
+	return false
+}
+
+// related returns true if either path is the other, or a path within it,
+// e.g. a child resource.
+func related(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// QueryGraph queries Resource Graph for the resources of the supplied
+// subscription with the supplied IDs.
+func QueryGraph(ctx context.Context, sub, endpoint string, a autorest.Authorizer, ids []string) (map[string]json.RawMessage, error) {
+	resources := map[string]json.RawMessage{}
+	for len(ids) > 0 {
+		n := graphQueryIDs
+		if len(ids) < n {
+			n = len(ids)
+		}
+		quoted := make([]string, n)
+		for i, id := range ids[:n] {
+			quoted[i] = strconv.Quote(id)
+		}
+		q := fmt.Sprintf("Resources | where id in~ (%s)", strings.Join(quoted, ", "))
+		rs, err := QueryGraphResources(ctx, sub, endpoint, a, q)
+		if err != nil {
+			return nil, err
+		}
+		for id, r := range rs {
+			resources[id] = r
+		}
+		ids = ids[n:]
+	}
+	return resources, nil
+}
+
+// QueryGraphResources runs the supplied Resource Graph query against the
+// supplied subscription. It returns the JSON representations of the resulting
+// resources by their IDs; results without an ID are omitted.
+func QueryGraphResources(ctx context.Context, sub, endpoint string, a autorest.Authorizer, q string) (map[string]json.RawMessage, error) {
+	cl := resourcegraph.NewWithBaseURI(endpoint)
+	cl.Authorizer = a
+	cl.Sender = autorest.CreateSender(tracing.SendDecorator(), apiMetrics.SendDecorator())
+	_ = cl.AddToUserAgent(UserAgent)
+
+	req := resourcegraph.QueryRequest{
+		Subscriptions: &[]string{sub},
+		Query:         &q,
+		Options: &resourcegraph.QueryRequestOptions{
+			Top:          ToInt32Ptr(graphPageSize),
+			ResultFormat: resourcegraph.ResultFormatObjectArray,
+		},
+	}
+
+	resources := map[string]json.RawMessage{}
+	for {
+		rsp, err := cl.Resources(ctx, req)
+		if err != nil {
+			return nil, errors.Wrap(err, errQueryGraph)
+		}
+		rows, _ := rsp.Data.([]interface{})
+		for _, row := range rows {
+			m, ok := row.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id, _ := m["id"].(string)
+			b, err := json.Marshal(m)
+			if err != nil || id == "" {
+				continue
+			}
+			resources[id] = b
+		}
+		if rsp.SkipToken == nil || *rsp.SkipToken == "" {
+			return resources, nil
+		}
+		req.Options.SkipToken = rsp.SkipToken
+	}
+}
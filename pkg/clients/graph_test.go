@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestGraphCacheSendDecorator(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := "https://management.azure.com/subscriptions/" + testSubscription + "/resourceGroups/coolRG/providers/Microsoft.Cache/Redis/"
+	cached := `{"id":"/subscriptions/` + testSubscription + `/resourceGroups/coolRG/providers/Microsoft.Cache/Redis/cool"}`
+
+	tracked := autorest.NewAPIKeyAuthorizerWithHeaders(map[string]interface{}{"tracked": "true"})
+	other := autorest.NewAPIKeyAuthorizerWithHeaders(map[string]interface{}{"other": "true"})
+
+	query := func(_ context.Context, sub, endpoint string, a autorest.Authorizer, ids []string) (map[string]json.RawMessage, error) {
+		if sub != testSubscription || endpoint != "https://management.azure.com" {
+			return nil, errors.New("unexpected subscription")
+		}
+		if a != tracked {
+			return nil, errors.New("unexpected authorizer")
+		}
+		rs := map[string]json.RawMessage{}
+		for _, id := range ids {
+			if id == "/subscriptions/"+testSubscription+"/resourcegroups/coolrg/providers/microsoft.cache/redis/cool" {
+				rs["/subscriptions/"+testSubscription+"/resourceGroups/coolRG/providers/Microsoft.Cache/Redis/cool"] = json.RawMessage(cached)
+			}
+		}
+		return rs, nil
+	}
+
+	type args struct {
+		started bool
+		writes  []string
+		deletes []string
+		idle    time.Duration
+		elapsed time.Duration
+		url     string
+
+		// authorizer of the final request; the tracked authorizer if nil.
+		authorizer autorest.Authorizer
+	}
+
+	type want struct {
+		body    string
+		tracked int
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotStarted": {
+			reason: "Requests should be sent to Azure, and not tracked, if the cache has not been started.",
+			args:   args{url: base + "cool?api-version=2018-03-01"},
+			want:   want{body: "azure"},
+		},
+		"Hit": {
+			reason: "Requests for cached resources should be served from the cache.",
+			args:   args{started: true, url: base + "cool?api-version=2018-03-01"},
+			want:   want{body: cached, tracked: 1},
+		},
+		"NotCached": {
+			reason: "Requests for resources that are not cached should be sent to Azure.",
+			args:   args{started: true, url: base + "other?api-version=2018-03-01"},
+			want:   want{body: "azure", tracked: 2},
+		},
+		"Expanded": {
+			reason: "Requests that expand a resource should be sent to Azure.",
+			args:   args{started: true, url: base + "cool?api-version=2018-03-01&$expand=keys"},
+			want:   want{body: "azure", tracked: 1},
+		},
+		"RecentlyWritten": {
+			reason: "Requests for resources that were recently written should be sent to Azure.",
+			args:   args{started: true, writes: []string{base + "cool"}, url: base + "cool?api-version=2018-03-01"},
+			want:   want{body: "azure", tracked: 1},
+		},
+		"ChildWritten": {
+			reason: "Requests for resources whose children were recently written should be sent to Azure.",
+			args:   args{started: true, writes: []string{base + "cool/firewallRules/rule"}, url: base + "cool?api-version=2018-03-01"},
+			want:   want{body: "azure", tracked: 1},
+		},
+		"Deleted": {
+			reason: "Requests for resources that were deleted should be sent to Azure, even once they were written a while ago.",
+			args:   args{started: true, deletes: []string{base + "cool"}, url: base + "cool?api-version=2018-03-01"},
+			want:   want{body: "azure", tracked: 1},
+		},
+		"NoLongerRequested": {
+			reason: "Requests for resources that have not been requested for a while should be sent to Azure.",
+			args:   args{started: true, idle: untrackAfter + time.Minute, url: base + "cool?api-version=2018-03-01"},
+			want:   want{body: "azure", tracked: 1},
+		},
+		"OtherAuthorizer": {
+			reason: "Requests made using another authorizer, e.g. that of another ProviderConfig, should not be served resources queried using the tracked authorizer.",
+			args:   args{started: true, authorizer: other, url: base + "cool?api-version=2018-03-01"},
+			want:   want{body: "azure", tracked: 2},
+		},
+		"Stale": {
+			reason: "Requests should be sent to Azure if the cache has not been refreshed recently.",
+			args:   args{started: true, elapsed: 10 * time.Minute, url: base + "cool?api-version=2018-03-01"},
+			want:   want{body: "azure", tracked: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewGraphCache(WithGraphQueryFn(query))
+			at := now
+			c.now = func() time.Time { return at }
+			if tc.args.started {
+				c.interval = time.Minute
+			}
+
+			azure := autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("azure"))}, nil
+			})
+			do := func(method, url string, a autorest.Authorizer) string {
+				s := autorest.DecorateSender(azure, c.SendDecorator(a))
+				req, _ := http.NewRequest(method, url, nil)
+				resp, err := s.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, _ := ioutil.ReadAll(resp.Body)
+				return string(b)
+			}
+
+			// The first request tracks the resource to query.
+			do(http.MethodGet, base+"cool?api-version=2018-03-01", tracked)
+			at = at.Add(tc.args.idle)
+			c.Refresh(context.Background())
+			for _, w := range tc.args.writes {
+				do(http.MethodPut, w+"?api-version=2018-03-01", tracked)
+			}
+			for _, d := range tc.args.deletes {
+				do(http.MethodDelete, d+"?api-version=2018-03-01", tracked)
+				at = at.Add(writeGrace + time.Minute)
+				c.Refresh(context.Background())
+			}
+			at = at.Add(tc.args.elapsed)
+
+			a := tc.args.authorizer
+			if a == nil {
+				a = tracked
+			}
+			got := do(http.MethodGet, tc.args.url, a)
+			if diff := cmp.Diff(tc.want.body, got); diff != "" {
+				t.Errorf("\n%s\nSendDecorator(): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			n := 0
+			for _, gs := range c.subs {
+				n += len(gs.ids)
+			}
+			if diff := cmp.Diff(tc.want.tracked, n); diff != "" {
+				t.Errorf("\n%s\nSendDecorator(): -want tracked, +got tracked:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+)
+
+// Labels of the Azure API metrics.
+const (
+	LabelService      = "service"
+	LabelOperation    = "operation"
+	LabelSubscription = "subscription"
+	LabelCode         = "code"
+	LabelLimit        = "limit"
+)
+
+// Values of the Azure API metric labels.
+const (
+	// CodeError is the code of requests that failed without a response.
+	CodeError = "error"
+
+	// ServiceBlob is the service of requests made to Azure Blob Storage.
+	ServiceBlob = "Microsoft.Storage/blob"
+
+	// ServiceResources is the service of requests made to Azure Resource
+	// Manager that are not scoped to a resource provider, e.g. requests to
+	// manage resource groups.
+	ServiceResources = "Microsoft.Resources"
+
+	LimitReads  = "reads"
+	LimitWrites = "writes"
+)
+
+const blobHostInfix = ".blob."
+
+// apiMetrics records the requests made to Azure by all controllers. It is
+// registered with the controller-runtime metrics registry, and thus exposed
+// by the metrics endpoint of the controller manager.
+var apiMetrics = NewMetrics()
+
+func init() {
+	metrics.Registry.MustRegister(apiMetrics)
+}
+
+// Metrics records the count, latency, and response status code of requests
+// made to Azure, and the remaining Azure Resource Manager request quota of
+// each subscription.
+type Metrics struct {
+	requests  *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	remaining *prometheus.GaugeVec
+}
+
+// NewMetrics returns Metrics that have recorded no requests.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "crossplane",
+			Subsystem: "azure_api",
+			Name:      "requests_total",
+			Help:      "Number of requests made to Azure, by response status code.",
+		}, []string{LabelService, LabelOperation, LabelSubscription, LabelCode}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "crossplane",
+			Subsystem: "azure_api",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests made to Azure.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+		}, []string{LabelService, LabelOperation, LabelSubscription}),
+		remaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "crossplane",
+			Subsystem: "azure_api",
+			Name:      "ratelimit_remaining",
+			Help:      "Remaining Azure Resource Manager reads or writes of a subscription, as of its most recent response.",
+		}, []string{LabelSubscription, LabelLimit}),
+	}
+}
+
+// Describe the Azure API metrics.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.latency.Describe(ch)
+	m.remaining.Describe(ch)
+}
+
+// Collect the Azure API metrics.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.latency.Collect(ch)
+	m.remaining.Collect(ch)
+}
+
+// SendDecorator returns an autorest SendDecorator that records the requests
+// made by the Sender it decorates. It should decorate the Sender of a client,
+// so that it records every attempt of a retried request.
+func (m *Metrics) SendDecorator() autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := s.Do(r)
+			m.Observe(r, resp, time.Since(start))
+			return resp, err
+		})
+	}
+}
+
+// PipelineSender returns an Azure pipeline Factory that sends requests using
+// the supplied Sender, and records them. It is intended to be supplied as the
+// HTTPSender of Azure Storage pipelines.
+func (m *Metrics) PipelineSender(s autorest.Sender) pipeline.Factory {
+	return pipeline.FactoryFunc(func(_ pipeline.Policy, _ *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, r pipeline.Request) (pipeline.Response, error) {
+			req := r.WithContext(ctx)
+			start := time.Now()
+			resp, err := s.Do(req)
+			m.Observe(req, resp, time.Since(start))
+			if err != nil {
+				return nil, pipeline.NewError(err, "HTTP request failed")
+			}
+			return pipeline.NewHTTPResponse(resp), nil
+		}
+	})
+}
+
+// Observe a request made to Azure, its response (which may be nil if the
+// request failed), and how long it took.
+func (m *Metrics) Observe(r *http.Request, resp *http.Response, d time.Duration) {
+	service, operation := Operation(r)
+	sub := SubscriptionFromPath(r.URL.Path)
+
+	code := CodeError
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	m.requests.WithLabelValues(service, operation, sub, code).Inc()
+	m.latency.WithLabelValues(service, operation, sub).Observe(d.Seconds())
+
+	if resp == nil || sub == "" {
+		return
+	}
+	if v, err := strconv.Atoi(resp.Header.Get(HeaderRemainingSubscriptionReads)); err == nil {
+		m.remaining.WithLabelValues(sub, LimitReads).Set(float64(v))
+	}
+	if v, err := strconv.Atoi(resp.Header.Get(HeaderRemainingSubscriptionWrites)); err == nil {
+		m.remaining.WithLabelValues(sub, LimitWrites).Set(float64(v))
+	}
+}
+
+// Operation returns the service and operation of the supplied request. The
+// service of an Azure Resource Manager request is the resource provider it
+// was made to, and its operation is its HTTP method and the type of resource
+// it was made to, e.g. 'PUT virtualNetworks/subnets'. Names, which would make
+// the metrics unbounded, are omitted. The service of other requests is the
+// host they were made to.
+func Operation(r *http.Request) (service, operation string) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case strings.Contains(r.URL.Host, blobHostInfix):
+		// Blob Storage operations are identified by their query.
+		types := []string{}
+		for _, k := range []string{"restype", "comp"} {
+			if v := r.URL.Query().Get(k); v != "" {
+				types = append(types, v)
+			}
+		}
+		if len(types) == 0 {
+			types = append(types, "blob")
+		}
+		return ServiceBlob, r.Method + " " + strings.Join(types, "/")
+	case len(parts) >= 2 && strings.EqualFold(parts[0], "subscriptions"):
+		service, parts = ServiceResources, parts[2:]
+		if len(parts) == 0 {
+			parts = []string{"subscriptions"}
+		}
+		// Requests to nested resource providers are attributed to the
+		// innermost provider, e.g. role assignments scoped to a subnet.
+		for i := len(parts) - 2; i >= 0; i-- {
+			if strings.EqualFold(parts[i], "providers") {
+				service, parts = parts[i+1], parts[i+2:]
+				break
+			}
+		}
+	default:
+		// Other APIs, e.g. Azure AD Graph, are scoped to a tenant.
+		service = r.URL.Host
+		if len(parts) > 0 {
+			parts = parts[1:]
+		}
+	}
+
+	// Resource types and names alternate, optionally followed by an action.
+	types := make([]string, 0, (len(parts)+1)/2)
+	for i := 0; i < len(parts); i += 2 {
+		types = append(types, parts[i])
+	}
+	return service, strings.TrimSpace(r.Method + " " + strings.Join(types, "/"))
+}
+
+// NewPipelineSender returns an Azure pipeline Factory that sends requests
+// using the same transport as Azure SDK clients, and records them alongside
+// the requests made by those clients.
+func NewPipelineSender() pipeline.Factory {
+	return apiMetrics.PipelineSender(autorest.CreateSender(tracing.SendDecorator()))
+}
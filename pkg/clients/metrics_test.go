@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestOperation(t *testing.T) {
+	type want struct {
+		service   string
+		operation string
+	}
+
+	cases := map[string]struct {
+		reason string
+		method string
+		url    string
+		want   want
+	}{
+		"ResourceGroup": {
+			reason: "Requests not scoped to a resource provider should be attributed to Azure Resource Manager.",
+			method: http.MethodPut,
+			url:    "https://management.azure.com/subscriptions/" + testSubscription + "/resourcegroups/cool?api-version=2019-05-01",
+			want:   want{service: ServiceResources, operation: "PUT resourcegroups"},
+		},
+		"NestedResource": {
+			reason: "Names should be omitted from the operation of nested resources.",
+			method: http.MethodGet,
+			url:    "https://management.azure.com/subscriptions/" + testSubscription + "/resourceGroups/cool/providers/Microsoft.Network/virtualNetworks/coolnet/subnets/coolsub",
+			want:   want{service: "Microsoft.Network", operation: "GET virtualNetworks/subnets"},
+		},
+		"Action": {
+			reason: "Actions should be included in the operation.",
+			method: http.MethodPost,
+			url:    "https://management.azure.com/subscriptions/" + testSubscription + "/resourceGroups/cool/providers/Microsoft.Cache/Redis/coolcache/listKeys",
+			want:   want{service: "Microsoft.Cache", operation: "POST Redis/listKeys"},
+		},
+		"NestedProvider": {
+			reason: "Requests should be attributed to the innermost resource provider.",
+			method: http.MethodPut,
+			url:    "https://management.azure.com/subscriptions/" + testSubscription + "/resourceGroups/cool/providers/Microsoft.Network/virtualNetworks/coolnet/providers/Microsoft.Authorization/roleAssignments/coolrole",
+			want:   want{service: "Microsoft.Authorization", operation: "PUT roleAssignments"},
+		},
+		"Graph": {
+			reason: "Requests to other APIs should be attributed to their host.",
+			method: http.MethodPost,
+			url:    "https://graph.windows.net/cool-tenant/applications?api-version=1.6",
+			want:   want{service: "graph.windows.net", operation: "POST applications"},
+		},
+		"Blob": {
+			reason: "Blob Storage operations should be identified by their query.",
+			method: http.MethodPut,
+			url:    "https://coolaccount.blob.core.windows.net/coolcontainer?restype=container&comp=metadata",
+			want:   want{service: ServiceBlob, operation: "PUT container/metadata"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, tc.url, nil)
+			service, operation := Operation(req)
+			if diff := cmp.Diff(tc.want, want{service: service, operation: operation}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nOperation(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMetricsSendDecorator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderRemainingSubscriptionReads, "11999")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	m := NewMetrics()
+	s := autorest.DecorateSender(&http.Client{}, m.SendDecorator())
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/subscriptions/"+testSubscription+"/resourcegroups/cool", nil)
+	resp, err := s.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues(ServiceResources, "GET resourcegroups", testSubscription, "200")); got != 1 {
+		t.Errorf("SendDecorator(): want 1 request, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.remaining.WithLabelValues(testSubscription, LimitReads)); got != 11999 {
+		t.Errorf("SendDecorator(): want 11999 remaining reads, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.remaining); got != 1 {
+		t.Errorf("SendDecorator(): remaining writes should not be recorded without a header, got %d series", got)
+	}
+}
+
+func TestMetricsPipelineSender(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	m := NewMetrics()
+	p := pipeline.NewPipeline(nil, pipeline.Options{HTTPSender: m.PipelineSender(&http.Client{})})
+	u, _ := url.Parse(srv.URL + "/coolcontainer?restype=container")
+	req, _ := pipeline.NewRequest(http.MethodGet, *u, nil)
+	resp, err := p.Do(context.Background(), nil, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Response().Body.Close()
+
+	// The test server is not a Blob Storage host, so the request is
+	// attributed to the host it was made to.
+	if got := testutil.CollectAndCount(m.requests); got != 1 {
+		t.Errorf("PipelineSender(): want 1 series, got %d", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues(req.URL.Host, "GET", "", "404")); got != 1 {
+		t.Errorf("PipelineSender(): want 1 request, got %v", got)
+	}
+}
@@ -29,7 +29,7 @@ import (
 func NewVirtualNetworkParameters(v *v1alpha3.VirtualNetwork) networkmgmt.VirtualNetwork {
 	return networkmgmt.VirtualNetwork{
 		Location: azure.ToStringPtr(v.Spec.Location),
-		Tags:     azure.ToStringPtrMap(v.Spec.Tags),
+		Tags:     azure.AddOwnerTags(azure.ToStringPtrMap(v.Spec.Tags), v),
 		VirtualNetworkPropertiesFormat: &networkmgmt.VirtualNetworkPropertiesFormat{
 			EnableDdosProtection: azure.ToBoolPtr(v.Spec.VirtualNetworkPropertiesFormat.EnableDDOSProtection, azure.FieldRequired),
 			EnableVMProtection:   azure.ToBoolPtr(v.Spec.VirtualNetworkPropertiesFormat.EnableVMProtection),
@@ -51,7 +51,7 @@ func VirtualNetworkNeedsUpdate(kube *v1alpha3.VirtualNetwork, az networkmgmt.Vir
 		return true
 	case !reflect.DeepEqual(up.VirtualNetworkPropertiesFormat.EnableVMProtection, az.VirtualNetworkPropertiesFormat.EnableVMProtection):
 		return true
-	case !reflect.DeepEqual(up.Tags, az.Tags):
+	case !reflect.DeepEqual(azure.RemoveOwnerTags(up.Tags), azure.RemoveOwnerTags(az.Tags)):
 		return true
 	}
 
@@ -45,6 +45,14 @@ var (
 	etag         = "a-very-cool-etag"
 	resourceType = "resource-type"
 	purpose      = "cool-purpose"
+
+	ownerTags = map[string]*string{
+		azure.TagKeyManagedBy:       to.StringPtr(azure.TagValueManagedBy),
+		azure.TagKeyOwnerKind:       to.StringPtr("VirtualNetwork.network.azure.crossplane.io"),
+		azure.TagKeyOwnerAPIVersion: to.StringPtr("network.azure.crossplane.io/v1alpha3"),
+		azure.TagKeyOwnerName:       to.StringPtr(""),
+		azure.TagKeyOwnerUID:        to.StringPtr(string(uid)),
+	}
 )
 
 func TestNewVirtualNetworkParameters(t *testing.T) {
@@ -70,7 +78,7 @@ func TestNewVirtualNetworkParameters(t *testing.T) {
 			},
 			want: networkmgmt.VirtualNetwork{
 				Location: azure.ToStringPtr(location),
-				Tags:     azure.ToStringPtrMap(nil),
+				Tags:     ownerTags,
 				VirtualNetworkPropertiesFormat: &networkmgmt.VirtualNetworkPropertiesFormat{
 					EnableDdosProtection: to.BoolPtr(enableDDOSProtection),
 					EnableVMProtection:   to.BoolPtr(enableVMProtection),
@@ -96,7 +104,7 @@ func TestNewVirtualNetworkParameters(t *testing.T) {
 			},
 			want: networkmgmt.VirtualNetwork{
 				Location: azure.ToStringPtr(location),
-				Tags:     azure.ToStringPtrMap(nil),
+				Tags:     ownerTags,
 				VirtualNetworkPropertiesFormat: &networkmgmt.VirtualNetworkPropertiesFormat{
 					EnableDdosProtection: to.BoolPtr(enableDDOSProtection),
 					EnableVMProtection:   nil,
@@ -255,6 +263,38 @@ func TestVirtualNetworkNeedsUpdate(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "NoUpdateOwnerTags",
+			kube: &v1alpha3.VirtualNetwork{
+				Spec: v1alpha3.VirtualNetworkSpec{
+					VirtualNetworkPropertiesFormat: v1alpha3.VirtualNetworkPropertiesFormat{
+						AddressSpace: v1alpha3.AddressSpace{
+							AddressPrefixes: addressPrefixes,
+						},
+						EnableDDOSProtection: enableDDOSProtection,
+						EnableVMProtection:   enableVMProtection,
+					},
+					Tags: tags,
+				},
+			},
+			az: networkmgmt.VirtualNetwork{
+				VirtualNetworkPropertiesFormat: &networkmgmt.VirtualNetworkPropertiesFormat{
+					AddressSpace: &networkmgmt.AddressSpace{
+						AddressPrefixes: &addressPrefixes,
+					},
+					EnableDdosProtection: to.BoolPtr(enableDDOSProtection),
+					EnableVMProtection:   to.BoolPtr(enableVMProtection),
+				},
+				Tags: func() map[string]*string {
+					t := azure.ToStringPtrMap(tags)
+					for k, v := range ownerTags {
+						t[k] = v
+					}
+					return t
+				}(),
+			},
+			want: false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"github.com/Azure/go-autorest/autorest/to"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Tags of the Azure resources managed by the provider.
+const (
+	TagKeyManagedBy   = "managed-by"
+	TagValueManagedBy = "crossplane"
+)
+
+// Tags that identify the managed resource that owns an Azure resource. Every
+// taggable Azure resource is tagged with them, along with TagKeyManagedBy.
+const (
+	// TagKeyOwnerKind is the kind and API group of the owner, e.g.
+	// Redis.cache.azure.crossplane.io.
+	TagKeyOwnerKind = "crossplane-kind"
+
+	// TagKeyOwnerAPIVersion is the API version of the owner, e.g.
+	// cache.azure.crossplane.io/v1beta1.
+	TagKeyOwnerAPIVersion = "crossplane-api-version"
+
+	TagKeyOwnerName = "crossplane-name"
+	TagKeyOwnerUID  = "crossplane-uid"
+
+	// The claim tags are only set if the owner is bound to a claim.
+	TagKeyClaimNamespace = "crossplane-claim-namespace"
+	TagKeyClaimName      = "crossplane-claim-name"
+)
+
+// Labels Crossplane sets on the managed resources of claims.
+const (
+	LabelKeyClaimNamespace = "crossplane.io/claim-namespace"
+	LabelKeyClaimName      = "crossplane.io/claim-name"
+)
+
+// An Owner identifies the managed resource that owns an Azure resource.
+type Owner struct {
+	Kind schema.GroupKind
+	Name string
+	UID  types.UID
+}
+
+// OwnerOf returns the owner the supplied Azure tags identify. It returns false
+// if the tags don't identify an owner.
+func OwnerOf(tags map[string]string) (Owner, bool) {
+	o := Owner{
+		Kind: schema.ParseGroupKind(tags[TagKeyOwnerKind]),
+		Name: tags[TagKeyOwnerName],
+		UID:  types.UID(tags[TagKeyOwnerUID]),
+	}
+	if o.Kind.Kind == "" || o.Name == "" || o.UID == "" {
+		return Owner{}, false
+	}
+	return o, true
+}
+
+// OwnerTags returns the tags that identify the supplied managed resource, and
+// any claim it is bound to, as the owner of an Azure resource. Managed
+// resources that have not been persisted, and thus have no UID, have no owner
+// tags.
+func OwnerTags(mg resource.Managed) map[string]string {
+	if mg.GetUID() == "" {
+		return nil
+	}
+	gvk := gvkOf(mg)
+	tags := map[string]string{
+		TagKeyManagedBy:       TagValueManagedBy,
+		TagKeyOwnerKind:       gvk.GroupKind().String(),
+		TagKeyOwnerAPIVersion: gvk.GroupVersion().String(),
+		TagKeyOwnerName:       mg.GetName(),
+		TagKeyOwnerUID:        string(mg.GetUID()),
+	}
+	l := mg.GetLabels()
+	if ns, name := l[LabelKeyClaimNamespace], l[LabelKeyClaimName]; ns != "" && name != "" {
+		tags[TagKeyClaimNamespace] = ns
+		tags[TagKeyClaimName] = name
+	}
+	return tags
+}
+
+// IsOwnerTag returns true if the supplied tag key is that of an owner tag.
+func IsOwnerTag(key string) bool {
+	switch key {
+	case TagKeyManagedBy, TagKeyOwnerKind, TagKeyOwnerAPIVersion, TagKeyOwnerName, TagKeyOwnerUID, TagKeyClaimNamespace, TagKeyClaimName:
+		return true
+	}
+	return false
+}
+
+// AddOwnerTags returns the supplied Azure tags with the owner tags of the
+// supplied managed resource added. Owner tags take precedence over any
+// supplied tags of the same key.
+func AddOwnerTags(tags map[string]*string, mg resource.Managed) map[string]*string {
+	owner := OwnerTags(mg)
+	if len(owner) == 0 {
+		return tags
+	}
+	out := make(map[string]*string, len(tags)+len(owner))
+	for k, v := range tags {
+		out[k] = v
+	}
+	for k, v := range owner {
+		out[k] = to.StringPtr(v)
+	}
+	return out
+}
+
+// RemoveOwnerTags returns the supplied Azure tags without any owner tags, so
+// that they may be compared to, or late initialize, the tags of a managed
+// resource. It returns nil if only owner tags were supplied.
+func RemoveOwnerTags(tags map[string]*string) map[string]*string {
+	if tags == nil {
+		return nil
+	}
+	out := make(map[string]*string, len(tags))
+	for k, v := range tags {
+		if !IsOwnerTag(k) {
+			out[k] = v
+		}
+	}
+	if len(out) == 0 && len(tags) > 0 {
+		return nil
+	}
+	return out
+}
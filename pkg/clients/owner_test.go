@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/apis/v1alpha3"
+)
+
+func TestOwnerOf(t *testing.T) {
+	type want struct {
+		o  Owner
+		ok bool
+	}
+	cases := map[string]struct {
+		reason string
+		tags   map[string]string
+		want   want
+	}{
+		"NoTags": {
+			reason: "Azure resources without tags should have no owner.",
+		},
+		"Incomplete": {
+			reason: "Azure resources whose tags don't identify an owner's kind, name, and UID should have no owner.",
+			tags: map[string]string{
+				TagKeyOwnerKind: "Redis.cache.azure.crossplane.io",
+				TagKeyOwnerName: "cool",
+			},
+		},
+		"Owned": {
+			reason: "The owner's kind, name, and UID should be read from its tags.",
+			tags: map[string]string{
+				"env":           "dev",
+				TagKeyOwnerKind: "Redis.cache.azure.crossplane.io",
+				TagKeyOwnerName: "cool",
+				TagKeyOwnerUID:  "cool-uid",
+			},
+			want: want{
+				o: Owner{
+					Kind: schema.GroupKind{Group: "cache.azure.crossplane.io", Kind: "Redis"},
+					Name: "cool",
+					UID:  "cool-uid",
+				},
+				ok: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o, ok := OwnerOf(tc.tags)
+			if diff := cmp.Diff(tc.want, want{o: o, ok: ok}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nOwnerOf(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAddOwnerTags(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		tags   map[string]*string
+		mg     resource.Managed
+		want   map[string]*string
+	}{
+		"NotPersisted": {
+			reason: "Managed resources without a UID should not add owner tags.",
+			tags:   map[string]*string{"env": to.StringPtr("dev")},
+			mg:     &v1alpha3.ResourceGroup{ObjectMeta: metav1.ObjectMeta{Name: "cool"}},
+			want:   map[string]*string{"env": to.StringPtr("dev")},
+		},
+		"Persisted": {
+			reason: "Owner tags should identify the managed resource by its kind, name, and UID, and take precedence over supplied tags.",
+			tags: map[string]*string{
+				"env":           to.StringPtr("dev"),
+				TagKeyOwnerName: to.StringPtr("spoofed"),
+			},
+			mg: &v1alpha3.ResourceGroup{ObjectMeta: metav1.ObjectMeta{Name: "cool", UID: "cool-uid"}},
+			want: map[string]*string{
+				"env":                 to.StringPtr("dev"),
+				TagKeyManagedBy:       to.StringPtr(TagValueManagedBy),
+				TagKeyOwnerKind:       to.StringPtr("ResourceGroup.azure.crossplane.io"),
+				TagKeyOwnerAPIVersion: to.StringPtr("azure.crossplane.io/v1alpha3"),
+				TagKeyOwnerName:       to.StringPtr("cool"),
+				TagKeyOwnerUID:        to.StringPtr("cool-uid"),
+			},
+		},
+		"Claimed": {
+			reason: "Owner tags should identify the claim the managed resource is bound to.",
+			mg: &v1alpha3.ResourceGroup{ObjectMeta: metav1.ObjectMeta{Name: "cool", UID: "cool-uid", Labels: map[string]string{
+				LabelKeyClaimNamespace: "default",
+				LabelKeyClaimName:      "cool-claim",
+			}}},
+			want: map[string]*string{
+				TagKeyManagedBy:       to.StringPtr(TagValueManagedBy),
+				TagKeyOwnerKind:       to.StringPtr("ResourceGroup.azure.crossplane.io"),
+				TagKeyOwnerAPIVersion: to.StringPtr("azure.crossplane.io/v1alpha3"),
+				TagKeyOwnerName:       to.StringPtr("cool"),
+				TagKeyOwnerUID:        to.StringPtr("cool-uid"),
+				TagKeyClaimNamespace:  to.StringPtr("default"),
+				TagKeyClaimName:       to.StringPtr("cool-claim"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := AddOwnerTags(tc.tags, tc.mg)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nAddOwnerTags(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRemoveOwnerTags(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		tags   map[string]*string
+		want   map[string]*string
+	}{
+		"Nil": {
+			reason: "Nil tags should remain nil.",
+		},
+		"OnlyOwner": {
+			reason: "Tags that are all owner tags should become nil, as if the resource had no tags.",
+			tags:   map[string]*string{TagKeyOwnerUID: to.StringPtr("cool-uid")},
+		},
+		"Mixed": {
+			reason: "Only owner tags should be removed.",
+			tags: map[string]*string{
+				"env":           to.StringPtr("dev"),
+				TagKeyManagedBy: to.StringPtr(TagValueManagedBy),
+				TagKeyOwnerUID:  to.StringPtr("cool-uid"),
+			},
+			want: map[string]*string{"env": to.StringPtr("dev")},
+		},
+		"Empty": {
+			reason: "Empty tags should remain empty.",
+			tags:   map[string]*string{},
+			want:   map[string]*string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := RemoveOwnerTags(tc.tags)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nRemoveOwnerTags(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy determines which operations are performed on the Azure
+// resources of managed resources, per their management policy and deletion
+// protection.
+package policy
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// AnnotationKeyManagementPolicy is the management policy of a managed
+// resource. Its Azure resource is fully managed unless it is annotated with a
+// known policy.
+const AnnotationKeyManagementPolicy = "azure.crossplane.io/management-policy"
+
+// ManagementPolicyObserveOnly is the policy of managed resources whose Azure
+// resources are observed, but never created, updated, or deleted. It allows
+// Azure resources created by other tools to be adopted gradually.
+const ManagementPolicyObserveOnly = "ObserveOnly"
+
+// AnnotationKeyDeletionProtection protects the Azure resource of a managed
+// resource from deletion while it is set to "true".
+const AnnotationKeyDeletionProtection = "azure.crossplane.io/deletion-protection"
+
+// Error strings.
+const (
+	errNotExist    = "external resource does not exist, and observe-only managed resources are not created"
+	errObserveOnly = "external resources of observe-only managed resources are not created, updated, or deleted"
+	errProtected   = "external resources of deletion protected managed resources are not deleted; remove the " + AnnotationKeyDeletionProtection + " annotation to delete it"
+)
+
+// ObserveOnly returns true if the Azure resource of the supplied managed
+// resource should only be observed.
+func ObserveOnly(mg resource.Managed) bool {
+	return mg.GetAnnotations()[AnnotationKeyManagementPolicy] == ManagementPolicyObserveOnly
+}
+
+// DeletionProtected returns true if the Azure resource of the supplied managed
+// resource should not be deleted.
+func DeletionProtected(mg resource.Managed) bool {
+	return mg.GetAnnotations()[AnnotationKeyDeletionProtection] == "true"
+}
+
+// A Connecter enforces the management policy of managed resources on the
+// ExternalClients produced by the ExternalConnecter it wraps. The Azure
+// resources of observe-only managed resources are observed, and their
+// connection details published, but their managed resources are never late
+// initialized from them and they are never created, updated, or deleted.
+// Deleting an observe-only managed resource orphans its Azure resource. The
+// Azure resources of deletion protected managed resources are never deleted;
+// deleting a deletion protected managed resource blocks until the protection
+// is removed.
+type Connecter struct {
+	connecter managed.ExternalConnecter
+}
+
+// NewConnecter returns a Connecter that enforces the management policy of
+// managed resources on the ExternalClients of the supplied ExternalConnecter.
+func NewConnecter(c managed.ExternalConnecter) *Connecter {
+	return &Connecter{connecter: c}
+}
+
+// Connect to Azure using the wrapped ExternalConnecter.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ec, err := c.connecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &external{client: ec}, nil
+}
+
+type external struct {
+	client managed.ExternalClient
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	if !ObserveOnly(mg) {
+		return e.client.Observe(ctx, mg)
+	}
+	// Reporting that a deleted managed resource's Azure resource does not
+	// exist finalizes it without deleting the Azure resource.
+	if meta.WasDeleted(mg) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	o, err := e.client.Observe(ctx, mg)
+	if err != nil {
+		return o, err
+	}
+	if !o.ResourceExists {
+		return o, errors.New(errNotExist)
+	}
+	// Any changes to the managed resource's spec made while observing are not
+	// persisted unless it was late initialized.
+	o.ResourceLateInitialized = false
+	o.ResourceUpToDate = true
+	return o, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	if ObserveOnly(mg) {
+		return managed.ExternalCreation{}, errors.New(errObserveOnly)
+	}
+	return e.client.Create(ctx, mg)
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	if ObserveOnly(mg) {
+		return managed.ExternalUpdate{}, errors.New(errObserveOnly)
+	}
+	return e.client.Update(ctx, mg)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	if ObserveOnly(mg) {
+		return errors.New(errObserveOnly)
+	}
+	if DeletionProtected(mg) {
+		return errors.New(errProtected)
+	}
+	return e.client.Delete(ctx, mg)
+}
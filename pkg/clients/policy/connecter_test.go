@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	observeOnly := func(deleted bool) *fake.Managed {
+		mg := &fake.Managed{}
+		mg.SetAnnotations(map[string]string{AnnotationKeyManagementPolicy: ManagementPolicyObserveOnly})
+		if deleted {
+			now := metav1.Now()
+			mg.SetDeletionTimestamp(&now)
+		}
+		return mg
+	}
+	observed := func(o managed.ExternalObservation, err error) managed.ExternalClient {
+		return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+			return o, err
+		}}
+	}
+	details := managed.ConnectionDetails{"endpoint": []byte("cool.example.org")}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		client managed.ExternalClient
+		want   want
+	}{
+		"FullyManaged": {
+			reason: "The observations of managed resources without a policy should be returned unchanged.",
+			mg:     &fake.Managed{},
+			client: observed(managed.ExternalObservation{ResourceExists: true, ResourceLateInitialized: true}, nil),
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceLateInitialized: true}},
+		},
+		"ObserveOnly": {
+			reason: "Observe-only managed resources should be neither late initialized nor updated, but should publish their connection details.",
+			mg:     observeOnly(false),
+			client: observed(managed.ExternalObservation{ResourceExists: true, ResourceLateInitialized: true, ConnectionDetails: details}, nil),
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: details}},
+		},
+		"NotExist": {
+			reason: "Observe-only managed resources whose Azure resource does not exist should report an error rather than create it.",
+			mg:     observeOnly(false),
+			client: observed(managed.ExternalObservation{}, nil),
+			want:   want{err: errors.New(errNotExist)},
+		},
+		"ObserveError": {
+			reason: "Errors observing observe-only managed resources should be returned.",
+			mg:     observeOnly(false),
+			client: observed(managed.ExternalObservation{}, errBoom),
+			want:   want{err: errBoom},
+		},
+		"Deleted": {
+			reason: "Deleted observe-only managed resources should be finalized without deleting their Azure resource.",
+			mg:     observeOnly(true),
+			client: observed(managed.ExternalObservation{ResourceExists: true}, nil),
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{client: tc.client}
+			got, err := e.Observe(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNeverModified(t *testing.T) {
+	mg := &fake.Managed{}
+	mg.SetAnnotations(map[string]string{AnnotationKeyManagementPolicy: ManagementPolicyObserveOnly})
+
+	called := false
+	e := &external{client: &managed.ExternalClientFns{
+		CreateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+			called = true
+			return managed.ExternalCreation{}, nil
+		},
+		UpdateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+			called = true
+			return managed.ExternalUpdate{}, nil
+		},
+		DeleteFn: func(_ context.Context, _ resource.Managed) error {
+			called = true
+			return nil
+		},
+	}}
+
+	want := errors.New(errObserveOnly)
+	_, cerr := e.Create(context.Background(), mg)
+	_, uerr := e.Update(context.Background(), mg)
+	derr := e.Delete(context.Background(), mg)
+	for _, err := range []error{cerr, uerr, derr} {
+		if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+			t.Errorf("Observe-only managed resources should never be modified: -want error, +got error:\n%s", diff)
+		}
+	}
+	if called {
+		t.Errorf("Observe-only managed resources should never be modified: the wrapped ExternalClient was called")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	annotated := func(a map[string]string) *fake.Managed {
+		mg := &fake.Managed{}
+		mg.SetAnnotations(a)
+		return mg
+	}
+
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		want   error
+	}{
+		"Unprotected": {
+			reason: "The Azure resources of managed resources without deletion protection should be deleted.",
+			mg:     &fake.Managed{},
+			want:   errBoom,
+		},
+		"ProtectionDisabled": {
+			reason: "The Azure resources of managed resources whose deletion protection is not \"true\" should be deleted.",
+			mg:     annotated(map[string]string{AnnotationKeyDeletionProtection: "false"}),
+			want:   errBoom,
+		},
+		"Protected": {
+			reason: "The Azure resources of deletion protected managed resources should not be deleted.",
+			mg:     annotated(map[string]string{AnnotationKeyDeletionProtection: "true"}),
+			want:   errors.New(errProtected),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{client: &managed.ExternalClientFns{DeleteFn: func(_ context.Context, _ resource.Managed) error {
+				return errBoom
+			}}}
+			err := e.Delete(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDelete(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
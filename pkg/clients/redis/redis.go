@@ -20,6 +20,8 @@ import (
 	"reflect"
 
 	"github.com/Azure/azure-sdk-for-go/services/redis/mgmt/2018-03-01/redis"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/crossplane/provider-azure/apis/cache/v1beta1"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
@@ -34,12 +36,12 @@ const (
 )
 
 // NewCreateParameters returns Redis resource creation parameters suitable for
-// use with the Azure API.
+// use with the Azure API. The cache is tagged with its owner.
 func NewCreateParameters(cr *v1beta1.Redis) redis.CreateParameters {
 	return redis.CreateParameters{
 		Location: azure.ToStringPtr(cr.Spec.ForProvider.Location),
 		Zones:    azure.ToStringArrayPtr(cr.Spec.ForProvider.Zones),
-		Tags:     azure.ToStringPtrMap(cr.Spec.ForProvider.Tags),
+		Tags:     azure.AddOwnerTags(azure.ToStringPtrMap(cr.Spec.ForProvider.Tags), cr),
 		CreateProperties: &redis.CreateProperties{
 			Sku:                NewSKU(cr.Spec.ForProvider.SKU),
 			SubnetID:           cr.Spec.ForProvider.SubnetID,
@@ -78,12 +80,9 @@ func NewUpdateParameters(spec v1beta1.RedisParameters, state redis.ResourceType)
 	// ResourceType and extract a JSON patch. But since the number of fields
 	// are not that many, I wanted to go with if statements. Hopefully, we'll
 	// generate this code in the future.
-	for k, v := range state.Tags {
-		if patch.Tags[k] == v {
-			delete(patch.Tags, k)
-		}
-	}
-	if len(patch.Tags) == 0 {
+	// Updated tags replace all of the cache's tags, so they're patched in
+	// full if any differ. The cache's owner tags are not part of its spec.
+	if cmp.Equal(patch.Tags, azure.RemoveOwnerTags(state.Tags), cmpopts.EquateEmpty()) {
 		patch.Tags = nil
 	}
 	if state.Properties == nil {
@@ -166,12 +165,14 @@ func GenerateObservation(az redis.ResourceType) v1beta1.RedisObservation {
 }
 
 // LateInitialize fills the spec values that user did not fill with their
-// corresponding value in the Azure, if there is any.
-func LateInitialize(spec *v1beta1.RedisParameters, az redis.ResourceType) {
+// corresponding value in the Azure, if there is any. It returns true if any
+// values were filled.
+func LateInitialize(spec *v1beta1.RedisParameters, az redis.ResourceType) bool {
+	before := spec.DeepCopy()
 	spec.Zones = azure.LateInitializeStringValArrFromArrPtr(spec.Zones, az.Zones)
-	spec.Tags = azure.LateInitializeStringMap(spec.Tags, az.Tags)
+	spec.Tags = azure.LateInitializeStringMap(spec.Tags, azure.RemoveOwnerTags(az.Tags))
 	if az.Properties == nil {
-		return
+		return !cmp.Equal(before, spec, cmpopts.EquateEmpty())
 	}
 	spec.SubnetID = azure.LateInitializeStringPtrFromPtr(spec.SubnetID, az.Properties.SubnetID)
 	spec.StaticIP = azure.LateInitializeStringPtrFromPtr(spec.StaticIP, az.Properties.StaticIP)
@@ -181,4 +182,5 @@ func LateInitialize(spec *v1beta1.RedisParameters, az redis.ResourceType) {
 	spec.ShardCount = azure.LateInitializeIntPtrFromInt32Ptr(spec.ShardCount, az.Properties.ShardCount)
 	minTLS := string(az.Properties.MinimumTLSVersion)
 	spec.MinimumTLSVersion = azure.LateInitializeStringPtrFromPtr(spec.MinimumTLSVersion, &minTLS)
+	return !cmp.Equal(before, spec, cmpopts.EquateEmpty())
 }
@@ -315,6 +315,54 @@ func TestNeedsUpdate(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "NeedsNoUpdateOwnerTags",
+			spec: v1beta1.RedisParameters{
+				SKU: v1beta1.SKU{
+					Name:     skuName,
+					Family:   skuFamily,
+					Capacity: skuCapacity,
+				},
+				Tags: tags,
+			},
+			az: redismgmt.ResourceType{
+				Tags: map[string]*string{
+					"key1":                azure.ToStringPtr("val1"),
+					azure.TagKeyManagedBy: azure.ToStringPtr(azure.TagValueManagedBy),
+					azure.TagKeyOwnerName: azure.ToStringPtr("cool-redis"),
+				},
+				Properties: &redismgmt.Properties{
+					Sku: &redismgmt.Sku{
+						Name:     redismgmt.SkuName(skuName),
+						Family:   redismgmt.SkuFamily(skuFamily),
+						Capacity: azure.ToInt32Ptr(skuCapacity),
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "DifferentTags",
+			spec: v1beta1.RedisParameters{
+				SKU: v1beta1.SKU{
+					Name:     skuName,
+					Family:   skuFamily,
+					Capacity: skuCapacity,
+				},
+				Tags: tags2,
+			},
+			az: redismgmt.ResourceType{
+				Tags: azure.ToStringPtrMap(tags),
+				Properties: &redismgmt.Properties{
+					Sku: &redismgmt.Sku{
+						Name:     redismgmt.SkuName(skuName),
+						Family:   redismgmt.SkuFamily(skuFamily),
+						Capacity: azure.ToInt32Ptr(skuCapacity),
+					},
+				},
+			},
+			want: true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -388,7 +436,8 @@ func TestLateInitialize(t *testing.T) {
 		spec *v1beta1.RedisParameters
 	}
 	type want struct {
-		spec *v1beta1.RedisParameters
+		spec    *v1beta1.RedisParameters
+		changed bool
 	}
 	cases := map[string]struct {
 		args
@@ -437,15 +486,40 @@ func TestLateInitialize(t *testing.T) {
 					ShardCount:         &shardCount,
 					MinimumTLSVersion:  &minTLSVersion,
 				},
+				changed: true,
+			},
+		},
+		"AlreadyLateInitialized": {
+			args: args{
+				az: redismgmt.ResourceType{
+					Zones: azure.ToStringArrayPtr(zones),
+					Properties: &redismgmt.Properties{
+						MinimumTLSVersion: redismgmt.TLSVersion(minTLSVersion),
+					},
+				},
+				spec: &v1beta1.RedisParameters{
+					Zones:             zones,
+					MinimumTLSVersion: &minTLSVersion,
+				},
+			},
+			want: want{
+				spec: &v1beta1.RedisParameters{
+					Zones:             zones,
+					MinimumTLSVersion: &minTLSVersion,
+				},
+				changed: false,
 			},
 		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			LateInitialize(tc.args.spec, tc.args.az)
+			changed := LateInitialize(tc.args.spec, tc.args.az)
 			if diff := cmp.Diff(tc.want.spec, tc.args.spec); diff != "" {
 				t.Errorf("LateInitialize(...): -want, +got\n%s", diff)
 			}
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("LateInitialize(...): -want changed, +got changed\n%s", diff)
+			}
 		})
 	}
 }
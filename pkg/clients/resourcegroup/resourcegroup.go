@@ -40,7 +40,7 @@ func NewClient(credentials []byte) (GroupsClient, error) {
 	if err := json.Unmarshal(credentials, &c); err != nil {
 		return nil, errors.Wrap(err, "cannot unmarshal Azure client secret data")
 	}
-	client := resources.NewGroupsClient(c.SubscriptionID)
+	client := resources.NewGroupsClientWithBaseURI(c.BaseURI(), c.SubscriptionID)
 
 	cfg := auth.ClientCredentialsConfig{
 		ClientID:     c.ClientID,
@@ -53,19 +53,17 @@ func NewClient(credentials []byte) (GroupsClient, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot create Azure authorizer from credentials config")
 	}
-	client.Authorizer = a
-	if err := client.AddToUserAgent(azure.UserAgent); err != nil {
-		return nil, errors.Wrap(err, "cannot add to Azure client user agent")
-	}
+	azure.ConfigureClient(&client.Client, a)
 
 	return client, nil
 }
 
 // NewParameters returns Resource Group resource creation parameters suitable for
-// use with the Azure API.
+// use with the Azure API. The resource group is tagged with its owner.
 func NewParameters(r *v1alpha3.ResourceGroup) resources.Group {
 	return resources.Group{
 		Name:     azure.ToStringPtr(meta.GetExternalName(r)),
 		Location: azure.ToStringPtr(r.Spec.Location),
+		Tags:     azure.AddOwnerTags(nil, r),
 	}
 }
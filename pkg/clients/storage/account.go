@@ -45,12 +45,8 @@ func NewStorageAccountClient(data []byte) (*storage.AccountsClient, error) {
 		return nil, fmt.Errorf("failed to get authorizer from config: %+v", err)
 	}
 
-	client := storage.NewAccountsClient(creds.SubscriptionID)
-	client.Authorizer = authorizer
-
-	if err := client.AddToUserAgent(azure.UserAgent); err != nil {
-		return nil, errors.Wrap(err, "cannot add to Azure client user agent")
-	}
+	client := storage.NewAccountsClientWithBaseURI(creds.BaseURI(), creds.SubscriptionID)
+	azure.ConfigureClient(&client.Client, authorizer)
 	return &client, nil
 }
 
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/Azure/azure-storage-blob-go/azblob"
 
@@ -43,20 +44,51 @@ type ContainerHandle struct {
 
 var _ ContainerOperations = &ContainerHandle{}
 
-const blobFormatString = `https://%s.blob.core.windows.net`
+const (
+	blobFormatString = `https://%s.blob.%s`
+	blobHostInfix    = ".blob."
+
+	// DefaultEndpointSuffix is the DNS suffix of storage accounts in the
+	// Azure public cloud.
+	DefaultEndpointSuffix = "core.windows.net"
+)
+
+// BlobEndpointSuffix returns the DNS suffix of the supplied blob service
+// endpoint, e.g. core.chinacloudapi.cn for
+// https://example.blob.core.chinacloudapi.cn/. It returns an empty string if
+// the endpoint is not a blob service endpoint.
+func BlobEndpointSuffix(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	i := strings.Index(u.Hostname(), blobHostInfix)
+	if i < 0 {
+		return ""
+	}
+	return u.Hostname()[i+len(blobHostInfix):]
+}
+
+// NewContainerHandle creates a new instance of ContainerHandle for given
+// storage account and given container name. The storage account's endpoints
+// are assumed to use the supplied DNS suffix, or that of the Azure public
+// cloud if it is empty.
+func NewContainerHandle(accountName, accountKey, containerName, endpointSuffix string) (*ContainerHandle, error) {
+	if endpointSuffix == "" {
+		endpointSuffix = DefaultEndpointSuffix
+	}
 
-// NewContainerHandle creates a new instance of ContainerHandle for given storage account and given container name
-func NewContainerHandle(accountName, accountKey, containerName string) (*ContainerHandle, error) {
 	c, err := azblob.NewSharedKeyCredential(accountName, accountKey)
 	if err != nil {
 		return nil, err
 	}
 
 	p := azblob.NewPipeline(c, azblob.PipelineOptions{
-		Telemetry: azblob.TelemetryOptions{Value: azure.UserAgent},
+		Telemetry:  azblob.TelemetryOptions{Value: azure.UserAgent},
+		HTTPSender: azure.NewPipelineSender(),
 	})
 
-	u, _ := url.Parse(fmt.Sprintf(blobFormatString, accountName))
+	u, _ := url.Parse(fmt.Sprintf(blobFormatString, accountName, endpointSuffix))
 	service := azblob.NewServiceURL(*u, p)
 
 	return &ContainerHandle{
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBlobEndpointSuffix(t *testing.T) {
+	cases := map[string]struct {
+		endpoint string
+		want     string
+	}{
+		"PublicCloud": {
+			endpoint: "https://example.blob.core.windows.net/",
+			want:     "core.windows.net",
+		},
+		"ChinaCloud": {
+			endpoint: "https://example.blob.core.chinacloudapi.cn/",
+			want:     "core.chinacloudapi.cn",
+		},
+		"AzureStack": {
+			endpoint: "https://example.blob.local.azurestack.external/",
+			want:     "local.azurestack.external",
+		},
+		"NotABlobEndpoint": {
+			endpoint: "https://example.queue.core.windows.net/",
+			want:     "",
+		},
+		"Empty": {
+			endpoint: "",
+			want:     "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := BlobEndpointSuffix(tc.endpoint)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("BlobEndpointSuffix(%q): -want, +got:\n%s", tc.endpoint, diff)
+			}
+		})
+	}
+}
+
+func TestNewContainerHandle(t *testing.T) {
+	cases := map[string]struct {
+		suffix string
+		want   string
+	}{
+		"DefaultSuffix": {
+			suffix: "",
+			want:   "https://example.blob.core.windows.net/cool-container",
+		},
+		"ChinaCloud": {
+			suffix: "core.chinacloudapi.cn",
+			want:   "https://example.blob.core.chinacloudapi.cn/cool-container",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ch, err := NewContainerHandle("example", "dGVzdC1rZXkK", "cool-container", tc.suffix)
+			if err != nil {
+				t.Fatalf("NewContainerHandle(...): %v", err)
+			}
+			u := ch.URL()
+			if diff := cmp.Diff(tc.want, u.String()); diff != "" {
+				t.Errorf("NewContainerHandle(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
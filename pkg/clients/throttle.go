@@ -0,0 +1,348 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/apis"
+)
+
+// Azure Resource Manager throttling response headers.
+const (
+	HeaderRemainingSubscriptionReads  = "x-ms-ratelimit-remaining-subscription-reads"
+	HeaderRemainingSubscriptionWrites = "x-ms-ratelimit-remaining-subscription-writes"
+	HeaderRetryAfter                  = "Retry-After"
+)
+
+const (
+	// Requests are paced once the remaining reads or writes of a
+	// subscription fall below these watermarks. Azure Resource Manager
+	// allows 12,000 reads and 1,200 writes per subscription per hour.
+	paceReadsBelow  = 1000
+	paceWritesBelow = 100
+
+	// maxPace is the delay before a request once a subscription has no
+	// remaining reads or writes.
+	maxPace = 10 * time.Second
+
+	// defaultRetryAfter is how long a subscription is throttled after Azure
+	// responds with 429 Too Many Requests without a Retry-After header.
+	defaultRetryAfter = 1 * time.Minute
+)
+
+const errThrottledFmt = "subscription %s is throttled by Azure Resource Manager for %s"
+
+// throttle tracks the Azure Resource Manager throttling state of the
+// subscriptions used by all controllers.
+var throttle = NewThrottle()
+
+// managedScheme is used to determine the kind of managed resources, which is
+// typically not set on objects read from the API server.
+var managedScheme = func() *runtime.Scheme {
+	s := runtime.NewScheme()
+	_ = apis.AddToScheme(s)
+	return s
+}()
+
+// gvkOf returns the kind and API version of the supplied managed resource.
+func gvkOf(mg resource.Managed) schema.GroupVersionKind {
+	if gvk, err := apiutil.GVKForObject(mg, managedScheme); err == nil {
+		return gvk
+	}
+	return mg.GetObjectKind().GroupVersionKind()
+}
+
+// A ManagedKey identifies a managed resource, which is cluster scoped, by its
+// kind and name. Managed resources of different kinds may share a name.
+type ManagedKey struct {
+	Kind schema.GroupKind
+	Name string
+}
+
+// ManagedKeyOf returns the key of the supplied managed resource.
+func ManagedKeyOf(mg resource.Managed) ManagedKey {
+	return ManagedKey{Kind: gvkOf(mg).GroupKind(), Name: mg.GetName()}
+}
+
+// A ThrottledError is returned when a request would be made to a subscription
+// that Azure Resource Manager is throttling.
+type ThrottledError struct {
+	Subscription string
+	RetryAfter   time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf(errThrottledFmt, e.Subscription, e.RetryAfter)
+}
+
+// IsThrottled returns true if the supplied error indicates a request was not
+// made because its subscription is throttled.
+func IsThrottled(err error) bool {
+	_, ok := errors.Cause(err).(*ThrottledError)
+	return ok
+}
+
+// subscriptionThrottle is the throttling state of a subscription.
+type subscriptionThrottle struct {
+	// Remaining reads and writes; negative if unknown.
+	reads  int
+	writes int
+
+	// Azure won't accept requests until this time.
+	blockedUntil time.Time
+}
+
+// A Throttle tracks how close subscriptions are to the Azure Resource Manager
+// request limits, using the headers of the responses to requests made to
+// them. It paces requests as subscriptions near their limits, and reports
+// how long each subscription is throttled for once they reach them.
+type Throttle struct {
+	mu    sync.Mutex
+	subs  map[string]*subscriptionThrottle
+	items map[ManagedKey]map[string]bool
+	now   func() time.Time
+}
+
+// NewThrottle returns a Throttle that knows of no subscriptions.
+func NewThrottle() *Throttle {
+	return &Throttle{
+		subs:  map[string]*subscriptionThrottle{},
+		items: map[ManagedKey]map[string]bool{},
+		now:   time.Now,
+	}
+}
+
+// SendDecorator returns an autorest SendDecorator that paces requests to
+// subscriptions that are nearing their limits, and observes the throttling
+// headers of their responses. It should decorate the Sender of a client, so
+// that it observes every attempt of a retried request.
+func (t *Throttle) SendDecorator() autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			sub := SubscriptionFromPath(r.URL.Path)
+			if d := t.Pace(sub, isWrite(r.Method)); d > 0 {
+				select {
+				case <-time.After(d):
+				case <-r.Context().Done():
+					return nil, r.Context().Err()
+				}
+			}
+			resp, err := s.Do(r)
+			t.Observe(sub, resp)
+			return resp, err
+		})
+	}
+}
+
+// Observe the throttling headers of a response to a request made to the
+// supplied subscription.
+func (t *Throttle) Observe(sub string, resp *http.Response) {
+	if sub == "" || resp == nil {
+		return
+	}
+	sub = strings.ToLower(sub)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.subscription(sub)
+	if v, err := strconv.Atoi(resp.Header.Get(HeaderRemainingSubscriptionReads)); err == nil {
+		s.reads = v
+	}
+	if v, err := strconv.Atoi(resp.Header.Get(HeaderRemainingSubscriptionWrites)); err == nil {
+		s.writes = v
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		s.blockedUntil = t.now().Add(retryAfter(resp.Header.Get(HeaderRetryAfter), t.now()))
+	}
+}
+
+// RetryAfter returns how long Azure Resource Manager will reject requests to
+// the supplied subscription for, or zero if it is not throttled.
+func (t *Throttle) RetryAfter(sub string) time.Duration {
+	sub = strings.ToLower(sub)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.subs[sub]
+	if !ok {
+		return 0
+	}
+	if d := s.blockedUntil.Sub(t.now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Pace returns how long to wait before making a read or write request to the
+// supplied subscription. The delay grows as the subscription's remaining
+// requests approach zero.
+func (t *Throttle) Pace(sub string, write bool) time.Duration {
+	sub = strings.ToLower(sub)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.subs[sub]
+	if !ok {
+		return 0
+	}
+	if write {
+		return pace(s.writes, paceWritesBelow)
+	}
+	return pace(s.reads, paceReadsBelow)
+}
+
+// Track that the supplied managed resource makes requests to the supplied
+// subscription.
+func (t *Throttle) Track(k ManagedKey, sub string) {
+	if sub == "" {
+		return
+	}
+	sub = strings.ToLower(sub)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.items[k] == nil {
+		t.items[k] = map[string]bool{}
+	}
+	t.items[k][sub] = true
+}
+
+// Untrack the subscriptions the supplied managed resource makes requests to.
+func (t *Throttle) Untrack(k ManagedKey) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.items, k)
+}
+
+// Delay returns how long to wait before reconciling the supplied managed
+// resource again, given the throttling state of the subscriptions it makes
+// requests to.
+func (t *Throttle) Delay(k ManagedKey) time.Duration {
+	t.mu.Lock()
+	subs := make([]string, 0, len(t.items[k]))
+	for sub := range t.items[k] {
+		subs = append(subs, sub)
+	}
+	t.mu.Unlock()
+
+	var d time.Duration
+	for _, sub := range subs {
+		if ra := t.RetryAfter(sub); ra > d {
+			d = ra
+		}
+		if p := t.Pace(sub, false); p > d {
+			d = p
+		}
+	}
+	return d
+}
+
+// subscription returns the state of the supplied subscription. t.mu must be
+// held.
+func (t *Throttle) subscription(sub string) *subscriptionThrottle {
+	s, ok := t.subs[sub]
+	if !ok {
+		s = &subscriptionThrottle{reads: -1, writes: -1}
+		t.subs[sub] = s
+	}
+	return s
+}
+
+func pace(remaining, below int) time.Duration {
+	if remaining < 0 || remaining >= below {
+		return 0
+	}
+	return maxPace * time.Duration(below-remaining) / time.Duration(below)
+}
+
+func isWrite(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead
+}
+
+// retryAfter parses the supplied Retry-After header, which may be a number
+// of seconds or an HTTP date.
+func retryAfter(v string, now time.Time) time.Duration {
+	if s, err := strconv.Atoi(v); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return defaultRetryAfter
+}
+
+// SubscriptionFromPath returns the subscription ID of the supplied Azure
+// Resource Manager request path, or an empty string if it is not scoped to a
+// subscription. Subscription IDs are case insensitive, and are returned in
+// lower case.
+func SubscriptionFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || !strings.EqualFold(parts[0], "subscriptions") {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
+
+// NewRateLimiter returns a workqueue rate limiter that delays managed
+// resources of the supplied kind until the subscriptions they make requests to
+// are no longer throttled, or at least as long as the supplied rate limiter
+// would. Each controller must use its own, because workqueue items identify
+// managed resources only by name.
+func NewRateLimiter(rl workqueue.RateLimiter, kind schema.GroupKind) workqueue.RateLimiter {
+	return &throttledRateLimiter{RateLimiter: rl, kind: kind, throttle: throttle}
+}
+
+type throttledRateLimiter struct {
+	workqueue.RateLimiter
+	kind     schema.GroupKind
+	throttle *Throttle
+}
+
+func (l *throttledRateLimiter) When(item interface{}) time.Duration {
+	d := l.RateLimiter.When(item)
+	r, ok := item.(reconcile.Request)
+	if !ok {
+		return d
+	}
+	if td := l.throttle.Delay(ManagedKey{Kind: l.kind, Name: r.Name}); td > d {
+		return td
+	}
+	return d
+}
+
+// Forget the supplied item. Controllers forget an item once it has been
+// reconciled successfully, including when its managed resource has been
+// deleted. The subscriptions it makes requests to are tracked again the next
+// time it connects to Azure.
+func (l *throttledRateLimiter) Forget(item interface{}) {
+	l.RateLimiter.Forget(item)
+	if r, ok := item.(reconcile.Request); ok {
+		l.throttle.Untrack(ManagedKey{Kind: l.kind, Name: r.Name})
+	}
+}
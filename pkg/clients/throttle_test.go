@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/provider-azure/apis/v1alpha3"
+)
+
+const testSubscription = "bf1b0e59-93da-42e0-82c6-5a1d94227911"
+
+func TestThrottleObserve(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		retryAfter time.Duration
+		readPace   time.Duration
+		writePace  time.Duration
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		header http.Header
+		want   want
+	}{
+		"Plenty": {
+			reason: "Requests should not be paced while plenty of requests remain.",
+			status: http.StatusOK,
+			header: http.Header{
+				http.CanonicalHeaderKey(HeaderRemainingSubscriptionReads):  {"11999"},
+				http.CanonicalHeaderKey(HeaderRemainingSubscriptionWrites): {"1199"},
+			},
+		},
+		"NearlyExhausted": {
+			reason: "Requests should be paced as the remaining requests approach zero.",
+			status: http.StatusOK,
+			header: http.Header{
+				http.CanonicalHeaderKey(HeaderRemainingSubscriptionReads):  {"500"},
+				http.CanonicalHeaderKey(HeaderRemainingSubscriptionWrites): {"0"},
+			},
+			want: want{readPace: maxPace / 2, writePace: maxPace},
+		},
+		"RetryAfterSeconds": {
+			reason: "A subscription should be throttled for the seconds specified by Retry-After.",
+			status: http.StatusTooManyRequests,
+			header: http.Header{HeaderRetryAfter: {"17"}},
+			want:   want{retryAfter: 17 * time.Second},
+		},
+		"RetryAfterDate": {
+			reason: "A subscription should be throttled until the date specified by Retry-After.",
+			status: http.StatusTooManyRequests,
+			header: http.Header{HeaderRetryAfter: {now.Add(2 * time.Minute).Format(http.TimeFormat)}},
+			want:   want{retryAfter: 2 * time.Minute},
+		},
+		"NoRetryAfter": {
+			reason: "A subscription should be throttled for a default duration if Retry-After is omitted.",
+			status: http.StatusTooManyRequests,
+			header: http.Header{},
+			want:   want{retryAfter: defaultRetryAfter},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			th := NewThrottle()
+			th.now = func() time.Time { return now }
+			th.Observe(testSubscription, &http.Response{StatusCode: tc.status, Header: tc.header})
+
+			if diff := cmp.Diff(tc.want.retryAfter, th.RetryAfter(testSubscription)); diff != "" {
+				t.Errorf("\n%s\nRetryAfter(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.readPace, th.Pace(testSubscription, false)); diff != "" {
+				t.Errorf("\n%s\nPace(...): -want read pace, +got read pace:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.writePace, th.Pace(testSubscription, true)); diff != "" {
+				t.Errorf("\n%s\nPace(...): -want write pace, +got write pace:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestThrottleSendDecorator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderRetryAfter, "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	th := NewThrottle()
+	s := autorest.DecorateSender(&http.Client{}, th.SendDecorator())
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/subscriptions/"+testSubscription+"/resourcegroups", nil)
+	resp, err := s.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if th.RetryAfter(testSubscription) <= 0 {
+		t.Errorf("SendDecorator(): subscription should be throttled after a 429 response")
+	}
+}
+
+func TestThrottleRateLimiter(t *testing.T) {
+	redis := schema.GroupKind{Group: "cache.azure.crossplane.io", Kind: "Redis"}
+	vnet := schema.GroupKind{Group: "network.azure.crossplane.io", Kind: "VirtualNetwork"}
+
+	th := NewThrottle()
+	th.Track(ManagedKey{Kind: redis, Name: "cool"}, testSubscription)
+	th.Observe(testSubscription, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{HeaderRetryAfter: {"60"}}})
+
+	newRateLimiter := func(kind schema.GroupKind) workqueue.RateLimiter {
+		return &throttledRateLimiter{RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(time.Second, time.Minute), kind: kind, throttle: th}
+	}
+	cool := reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool"}}
+
+	if d := newRateLimiter(redis).When(cool); d <= 59*time.Second {
+		t.Errorf("When(...): items using a throttled subscription should wait for Retry-After, got %s", d)
+	}
+	if d := newRateLimiter(redis).When(reconcile.Request{NamespacedName: types.NamespacedName{Name: "other"}}); d != time.Second {
+		t.Errorf("When(...): items not using a throttled subscription should use the base rate limiter, got %s", d)
+	}
+	if d := newRateLimiter(vnet).When(cool); d != time.Second {
+		t.Errorf("When(...): items of another kind with the same name should use the base rate limiter, got %s", d)
+	}
+
+	newRateLimiter(redis).Forget(cool)
+	if d := newRateLimiter(redis).When(cool); d != time.Second {
+		t.Errorf("When(...): forgotten items should use the base rate limiter, got %s", d)
+	}
+}
+
+func TestManagedKeyOf(t *testing.T) {
+	mg := &v1alpha3.ResourceGroup{ObjectMeta: metav1.ObjectMeta{Name: "cool"}}
+	want := ManagedKey{Kind: schema.GroupKind{Group: v1alpha3.Group, Kind: v1alpha3.ResourceGroupKind}, Name: "cool"}
+	if diff := cmp.Diff(want, ManagedKeyOf(mg)); diff != "" {
+		t.Errorf("ManagedKeyOf(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestSubscriptionFromPath(t *testing.T) {
+	cases := map[string]string{
+		"/subscriptions/" + testSubscription + "/resourceGroups/cool": testSubscription,
+		"/SUBSCRIPTIONS/BF1B0E59-93DA-42E0-82C6-5A1D94227911":         testSubscription,
+		"/cool-tenant/applications":                                   "",
+		"/":                                                           "",
+	}
+	for path, want := range cases {
+		if diff := cmp.Diff(want, SubscriptionFromPath(path)); diff != "" {
+			t.Errorf("SubscriptionFromPath(%q): -want, +got:\n%s", path, diff)
+		}
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	err := &ThrottledError{Subscription: testSubscription, RetryAfter: time.Minute}
+	if !IsThrottled(errors.Wrap(err, "boom")) {
+		t.Errorf("IsThrottled(...): wrapped ThrottledError should be throttled")
+	}
+	if IsThrottled(errors.New("boom")) {
+		t.Errorf("IsThrottled(...): other errors should not be throttled")
+	}
+}
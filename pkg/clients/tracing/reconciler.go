@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// reconciles are the spans of the reconciles in progress. The managed resource
+// Reconciler ignores the context it is called with, so a Connecter finds the
+// span of the reconcile it is called by here rather than in its context.
+var reconciles = newSpans()
+
+type spanKey struct {
+	controller string
+	name       string
+}
+
+// spans are the spans of reconciles in progress, by controller and the name
+// of the reconciled resource. A controller never reconciles a resource
+// concurrently.
+type spans struct {
+	mu     sync.RWMutex
+	active map[spanKey]trace.Span
+}
+
+func newSpans() *spans {
+	return &spans{active: map[spanKey]trace.Span{}}
+}
+
+func (s *spans) add(k spanKey, span trace.Span) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active[k] = span
+}
+
+func (s *spans) remove(k spanKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, k)
+}
+
+func (s *spans) get(k spanKey) trace.Span {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active[k]
+}
+
+// A Reconciler records each reconcile of the Reconciler it wraps as a span.
+// Spans recorded during the reconcile, e.g. by a Connecter of the same
+// controller, are its children.
+type Reconciler struct {
+	name       string
+	reconciler reconcile.Reconciler
+}
+
+// NewReconciler returns a Reconciler that records the reconciles of the
+// supplied Reconciler, which is run by the named controller.
+func NewReconciler(name string, r reconcile.Reconciler) *Reconciler {
+	return &Reconciler{name: name, reconciler: r}
+}
+
+// Reconcile the supplied request using the wrapped Reconciler.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx, span := tracer().Start(ctx, "Reconcile", trace.WithAttributes(
+		AttributeController.String(r.name),
+		AttributeName.String(req.Name)))
+	k := spanKey{controller: r.name, name: req.Name}
+	reconciles.add(k, span)
+	res, err := r.reconciler.Reconcile(ctx, req)
+	reconciles.remove(k)
+	end(span, err)
+	return res, err
+}
+
+// A Connecter records each call to the ExternalConnecter it wraps, and to the
+// ExternalClients it produces, as a span. The spans are children of the span
+// of the reconcile they are made by, if the controller's Reconciler is wrapped
+// by a Reconciler of the same name.
+type Connecter struct {
+	name      string
+	connecter managed.ExternalConnecter
+}
+
+// NewConnecter returns a Connecter that records the calls of the supplied
+// ExternalConnecter, which is used by the named controller.
+func NewConnecter(name string, c managed.ExternalConnecter) *Connecter {
+	return &Connecter{name: name, connecter: c}
+}
+
+// Connect to Azure using the wrapped ExternalConnecter.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	root := reconciles.get(spanKey{controller: c.name, name: mg.GetName()})
+	e := &external{root: root}
+	sctx, span := e.start(ctx, "Connect", mg)
+	ec, err := c.connecter.Connect(sctx, mg)
+	e.end(span, err)
+	if err != nil {
+		return nil, err
+	}
+	e.client = ec
+	return e, nil
+}
+
+// start a span for the supplied managed resource.
+func start(ctx context.Context, name string, mg resource.Managed) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(AttributeName.String(mg.GetName())))
+}
+
+type external struct {
+	client managed.ExternalClient
+
+	// root is the span of the reconcile the client was connected by, or nil.
+	root trace.Span
+}
+
+// start a span for the supplied call. It is a child of the reconcile span, if
+// any, unless the supplied context already has a span.
+func (e *external) start(ctx context.Context, name string, mg resource.Managed) (context.Context, trace.Span) {
+	if e.root != nil && !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.ContextWithSpan(ctx, e.root)
+	}
+	return start(ctx, name, mg)
+}
+
+// end the supplied span, recording the supplied error, which may be nil. The
+// error is also recorded on the reconcile span, since the managed resource
+// Reconciler doesn't return it.
+func (e *external) end(span trace.Span, err error) {
+	end(span, err)
+	if err != nil && e.root != nil {
+		e.root.RecordError(err)
+		e.root.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	ctx, span := e.start(ctx, "Observe", mg)
+	o, err := e.client.Observe(ctx, mg)
+	e.end(span, err)
+	return o, err
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	ctx, span := e.start(ctx, "Create", mg)
+	c, err := e.client.Create(ctx, mg)
+	e.end(span, err)
+	return c, err
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	ctx, span := e.start(ctx, "Update", mg)
+	u, err := e.client.Update(ctx, mg)
+	e.end(span, err)
+	return u, err
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	ctx, span := e.start(ctx, "Delete", mg)
+	err := e.client.Delete(ctx, mg)
+	e.end(span, err)
+	return err
+}
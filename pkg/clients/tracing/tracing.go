@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing records reconciles, their external client calls, and the
+// requests they make to Azure as OpenTelemetry spans.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer that records the provider's spans.
+const TracerName = "github.com/crossplane/provider-azure"
+
+// ServiceName identifies the provider in exported traces.
+const ServiceName = "provider-azure"
+
+// HeaderRequestID is the response header containing the ID Azure assigned to
+// a request.
+const HeaderRequestID = "x-ms-request-id"
+
+// Span attributes.
+const (
+	AttributeController = attribute.Key("crossplane.controller")
+	AttributeName       = attribute.Key("crossplane.resource.name")
+	AttributeRequestID  = attribute.Key("azure.request_id")
+)
+
+const errNewExporter = "cannot create OTLP trace exporter"
+
+// NewTracerProvider returns a TracerProvider that exports spans in batches to
+// the OTLP gRPC collector at the supplied endpoint. It samples the supplied
+// ratio of traces, unless their parent span was sampled.
+func NewTracerProvider(ctx context.Context, endpoint string, insecure bool, ratio float64) (*sdktrace.TracerProvider, error) {
+	o := []otlpgrpc.Option{otlpgrpc.WithEndpoint(endpoint)}
+	if insecure {
+		o = append(o, otlpgrpc.WithInsecure())
+	}
+	exp, err := otlp.NewExporter(ctx, otlpgrpc.NewDriver(o...))
+	if err != nil {
+		return nil, errors.Wrap(err, errNewExporter)
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String(ServiceName))),
+	), nil
+}
+
+// tracer returns the provider's tracer. It uses the global TracerProvider,
+// which records nothing unless one has been installed.
+func tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// end the supplied span, recording the supplied error, which may be nil.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SendDecorator returns an autorest SendDecorator that records each request
+// made by the Sender it decorates as a span. Spans are children of the span,
+// if any, in the context of their request.
+func SendDecorator() autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return NewSender(s)
+	}
+}
+
+// NewSender returns a Sender that records each request made by the supplied
+// Sender as a span. It also satisfies the Sender interface of ADAL tokens, so
+// that requests for tokens may be recorded.
+func NewSender(s autorest.Sender) autorest.Sender {
+	return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+		ctx, span := tracer().Start(r.Context(), "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(r)...))
+
+		resp, err := s.Do(r.WithContext(ctx))
+		if err != nil {
+			end(span, err)
+			return resp, err
+		}
+
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+		if id := resp.Header.Get(HeaderRequestID); id != "" {
+			span.SetAttributes(AttributeRequestID.String(id))
+		}
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+		span.End()
+		return resp, nil
+	})
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+const testRequestID = "cool-request"
+
+// record installs a global TracerProvider that records spans in memory.
+func record() *tracetest.InMemoryExporter {
+	exp := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	return exp
+}
+
+func attributes(s *sdktrace.SpanSnapshot) map[attribute.Key]attribute.Value {
+	a := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes {
+		a[kv.Key] = kv.Value
+	}
+	return a
+}
+
+func TestSender(t *testing.T) {
+	type want struct {
+		status    codes.Code
+		code      int64
+		requestID string
+	}
+
+	cases := map[string]struct {
+		reason string
+		status int
+		want   want
+	}{
+		"Success": {
+			reason: "Successful requests should be recorded with their status code and request ID.",
+			status: http.StatusOK,
+			want:   want{status: codes.Unset, code: http.StatusOK, requestID: testRequestID},
+		},
+		"ServerError": {
+			reason: "Requests that fail with a server error should be recorded as errors.",
+			status: http.StatusServiceUnavailable,
+			want:   want{status: codes.Error, code: http.StatusServiceUnavailable, requestID: testRequestID},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set(HeaderRequestID, testRequestID)
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			exp := record()
+			ctx, parent := tracer().Start(context.Background(), "Observe")
+			r, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			resp, err := autorest.CreateSender(SendDecorator()).Do(r)
+			if err != nil {
+				t.Fatalf("\n%s\nDo(...): %s", tc.reason, err)
+			}
+			_ = resp.Body.Close()
+			parent.End()
+
+			spans := exp.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("\n%s\nDo(...): want 2 spans, got %d", tc.reason, len(spans))
+			}
+			s := spans[0]
+			if diff := cmp.Diff(parent.SpanContext().SpanID(), s.Parent.SpanID()); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want parent, +got parent:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.status, s.StatusCode); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			a := attributes(s)
+			if diff := cmp.Diff(tc.want.code, a["http.status_code"].AsInt64()); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want status code, +got status code:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.requestID, a[AttributeRequestID].AsString()); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want request ID, +got request ID:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReconciler(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err   error
+		spans []string
+	}
+
+	cases := map[string]struct {
+		reason    string
+		connecter managed.ExternalConnecter
+		want      want
+	}{
+		"Success": {
+			reason: "External client calls should be recorded as children of their reconcile.",
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, nil
+				}}, nil
+			}),
+			want: want{spans: []string{"Connect", "Observe", "Reconcile"}},
+		},
+		"ObserveError": {
+			reason: "External client calls that fail should be recorded as errors.",
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, errBoom
+				}}, nil
+			}),
+			want: want{err: errBoom, spans: []string{"Connect", "Observe", "Reconcile"}},
+		},
+		"ConnectError": {
+			reason: "Reconciles that fail to connect should record only their connection.",
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return nil, errBoom
+			}),
+			want: want{err: errBoom, spans: []string{"Connect", "Reconcile"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			exp := record()
+			c := NewConnecter("cool", tc.connecter)
+			r := NewReconciler("cool", reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				mg := &fake.Managed{}
+				mg.SetName(req.Name)
+				ec, err := c.Connect(ctx, mg)
+				if err != nil {
+					return reconcile.Result{}, err
+				}
+				_, err = ec.Observe(ctx, mg)
+				return reconcile.Result{}, err
+			}))
+
+			req := reconcile.Request{}
+			req.Name = "coolresource"
+			_, err := r.Reconcile(context.Background(), req)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			spans := exp.GetSpans()
+			names := make([]string, len(spans))
+			for i, s := range spans {
+				names[i] = s.Name
+				if s.Name == "Reconcile" {
+					continue
+				}
+				root := spans[len(spans)-1]
+				if diff := cmp.Diff(root.SpanContext.SpanID(), s.Parent.SpanID()); diff != "" {
+					t.Errorf("\n%s\nReconcile(...): %s: -want parent, +got parent:\n%s", tc.reason, s.Name, diff)
+				}
+				if diff := cmp.Diff(req.Name, attributes(s)[AttributeName].AsString()); diff != "" {
+					t.Errorf("\n%s\nReconcile(...): %s: -want name, +got name:\n%s", tc.reason, s.Name, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.want.spans, names); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want spans, +got spans:\n%s", tc.reason, diff)
+			}
+			if tc.want.err != nil && spans[len(spans)-1].StatusCode != codes.Error {
+				t.Errorf("\n%s\nReconcile(...): want reconcile span status %s, got %s", tc.reason, codes.Error, spans[len(spans)-1].StatusCode)
+			}
+		})
+	}
+}
+
+func TestManagedReconciler(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		status codes.Code
+		spans  []string
+	}
+
+	cases := map[string]struct {
+		reason    string
+		connecter managed.ExternalConnecter
+		want      want
+	}{
+		"Success": {
+			reason: "External client calls should be recorded as children of the reconcile of the managed resource reconciler, which does not pass its context on.",
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+				}}, nil
+			}),
+			want: want{status: codes.Unset, spans: []string{"Connect", "Observe", "Reconcile"}},
+		},
+		"ObserveError": {
+			reason: "Reconciles whose external client calls fail should be recorded as errors.",
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, errBoom
+				}}, nil
+			}),
+			want: want{status: codes.Error, spans: []string{"Connect", "Observe", "Reconcile"}},
+		},
+		"ConnectError": {
+			reason: "Reconciles that fail to connect should record only their connection.",
+			connecter: managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return nil, errBoom
+			}),
+			want: want{status: codes.Error, spans: []string{"Connect", "Reconcile"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			exp := record()
+			m := &fake.Manager{
+				Client: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						obj.SetName("coolresource")
+						return nil
+					}),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+				Scheme: fake.SchemeWith(&fake.Managed{}),
+			}
+			r := NewReconciler("cool", managed.NewReconciler(m, resource.ManagedKind(fake.GVK(&fake.Managed{})),
+				managed.WithInitializers(),
+				managed.WithReferenceResolver(managed.ReferenceResolverFn(func(_ context.Context, _ resource.Managed) error { return nil })),
+				managed.WithExternalConnecter(NewConnecter("cool", tc.connecter)),
+				managed.WithConnectionPublishers(),
+				managed.WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil }})))
+
+			req := reconcile.Request{}
+			req.Name = "coolresource"
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("\n%s\nReconcile(...): %s", tc.reason, err)
+			}
+
+			spans := exp.GetSpans()
+
+			names := make([]string, len(spans))
+			for i, s := range spans {
+				names[i] = s.Name
+			}
+			if diff := cmp.Diff(tc.want.spans, names); diff != "" {
+				t.Fatalf("\n%s\nReconcile(...): -want spans, +got spans:\n%s", tc.reason, diff)
+			}
+
+			root := spans[len(spans)-1]
+			if root.Parent.IsValid() {
+				t.Errorf("\n%s\nReconcile(...): want reconcile span to be a root span", tc.reason)
+			}
+			if diff := cmp.Diff("cool", attributes(root)[AttributeController].AsString()); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want controller, +got controller:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.status, root.StatusCode); diff != "" {
+				t.Errorf("\n%s\nReconcile(...): -want reconcile span status, +got reconcile span status:\n%s", tc.reason, diff)
+			}
+			for _, s := range spans[:len(spans)-1] {
+				if diff := cmp.Diff(root.SpanContext.SpanID(), s.Parent.SpanID()); diff != "" {
+					t.Errorf("\n%s\nReconcile(...): %s: -want parent, +got parent:\n%s", tc.reason, s.Name, diff)
+				}
+				if diff := cmp.Diff(req.Name, attributes(s)[AttributeName].AsString()); diff != "" {
+					t.Errorf("\n%s\nReconcile(...): %s: -want name, +got name:\n%s", tc.reason, s.Name, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package whatif
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// TypeDryRun indicates whether a managed resource is run in dry-run mode. Its
+// message describes the changes its most recent create or update would have
+// made.
+const TypeDryRun xpv1.ConditionType = "DryRun"
+
+// Reasons a managed resource is or is not run in dry-run mode.
+const (
+	ReasonChangesPredicted xpv1.ConditionReason = "ChangesPredicted"
+	ReasonNoChanges        xpv1.ConditionReason = "NoChanges"
+	ReasonNotPredicted     xpv1.ConditionReason = "NotPredicted"
+	ReasonDisabled         xpv1.ConditionReason = "Disabled"
+)
+
+// Predicted returns a condition that indicates a managed resource is run in
+// dry-run mode, and describes the changes it would have made.
+func Predicted(r xpv1.ConditionReason, msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDryRun,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             r,
+		Message:            truncate(msg),
+	}
+}
+
+// Disabled returns a condition that indicates a managed resource is no longer
+// run in dry-run mode.
+func Disabled() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDryRun,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDisabled,
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package whatif
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+const errDeleteDryRun = "refusing to delete external resource in dry-run mode"
+
+const reasonDryRun event.Reason = "DryRun"
+
+// A Connecter runs the ExternalClients produced by the ExternalConnecter it
+// wraps in dry-run mode, for managed resources that are Enabled. The requests
+// their Create, Update, and Delete methods make to modify Azure resources are
+// not sent. The changes they would make are reported using the DryRun
+// condition and an event instead. Create and Update report success, so that
+// managed resources are reconciled as usual, while Delete reports an error, so
+// that managed resources are not finalized while their Azure resources exist.
+type Connecter struct {
+	connecter managed.ExternalConnecter
+	record    event.Recorder
+	predict   PredictFn
+
+	mu sync.Mutex
+	// reported is the digest of the requests most recently reported for each
+	// managed resource, so that unchanged requests are not predicted again
+	// every time the managed resource is reconciled.
+	reported map[types.UID]string
+}
+
+// A ConnecterOption configures a Connecter.
+type ConnecterOption func(*Connecter)
+
+// WithPredictFn specifies how a Connecter should predict the changes a
+// request would make.
+func WithPredictFn(fn PredictFn) ConnecterOption {
+	return func(c *Connecter) {
+		c.predict = fn
+	}
+}
+
+// NewConnecter returns a Connecter that runs the ExternalClients of the
+// supplied ExternalConnecter in dry-run mode, reporting the changes they would
+// make using the supplied event recorder.
+func NewConnecter(c managed.ExternalConnecter, r event.Recorder, o ...ConnecterOption) *Connecter {
+	wc := &Connecter{connecter: c, record: r, predict: Predict, reported: map[types.UID]string{}}
+	for _, fn := range o {
+		fn(wc)
+	}
+	return wc
+}
+
+// Connect to Azure using the wrapped ExternalConnecter.
+func (c *Connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ec, err := c.connecter.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &external{client: ec, connecter: c}, nil
+}
+
+// report the changes the supplied requests, made for the supplied managed
+// resource, would have made, unless they were most recently reported.
+func (c *Connecter) report(ctx context.Context, mg resource.Managed, rs []azureclients.DryRunRequest) {
+	digest := digestOf(rs)
+	c.mu.Lock()
+	if c.reported[mg.GetUID()] == digest {
+		c.mu.Unlock()
+		return
+	}
+	c.reported[mg.GetUID()] = digest
+	c.mu.Unlock()
+
+	predicted, changed := true, false
+	lines := make([]string, 0, len(rs))
+	for _, r := range rs {
+		lines = append(lines, fmt.Sprintf("Would %s %s", r.Method, r.URL.Path))
+		var err error
+		if r.Method == http.MethodPut {
+			var cs []Change
+			if cs, err = c.predict(ctx, r); err == nil {
+				changed = changed || Changed(cs)
+				lines = append(lines, Describe(cs))
+				continue
+			}
+			lines = append(lines, err.Error())
+		}
+		predicted = false
+		if b := body(r.Body); b != "" {
+			lines = append(lines, b)
+		}
+	}
+
+	reason := ReasonNoChanges
+	switch {
+	case !predicted:
+		reason = ReasonNotPredicted
+	case changed:
+		reason = ReasonChangesPredicted
+	}
+	msg := strings.Join(lines, "\n")
+	mg.SetConditions(Predicted(reason, msg))
+	c.record.Event(mg, event.Normal(reasonDryRun, truncate(msg)))
+}
+
+// disabled reports that the supplied managed resource is no longer run in
+// dry-run mode, if it was.
+func (c *Connecter) disabled(mg resource.Managed) {
+	if mg.GetCondition(TypeDryRun).Status != corev1.ConditionTrue {
+		return
+	}
+	c.forget(mg)
+	mg.SetConditions(Disabled())
+}
+
+// forget the requests most recently reported for the supplied managed
+// resource.
+func (c *Connecter) forget(mg resource.Managed) {
+	c.mu.Lock()
+	delete(c.reported, mg.GetUID())
+	c.mu.Unlock()
+}
+
+// body returns the supplied request body, with the values of sensitive
+// properties omitted.
+func body(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return ""
+	}
+	rb, err := json.Marshal(Redact(v))
+	if err != nil {
+		return ""
+	}
+	return string(rb)
+}
+
+// digestOf the supplied requests. Request bodies are digested with the values
+// of sensitive properties omitted, so that requests that e.g. generate a new
+// password every time they are made are not considered changed.
+func digestOf(rs []azureclients.DryRunRequest) string {
+	h := sha256.New()
+	for _, r := range rs {
+		b := body(r.Body)
+		if b == "" {
+			b = string(r.Body)
+		}
+		fmt.Fprintf(h, "%s %s\n%s\n", r.Method, r.URL.String(), b)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type external struct {
+	client    managed.ExternalClient
+	connecter *Connecter
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	if !Enabled(mg) {
+		e.connecter.disabled(mg)
+	}
+	o, err := e.client.Observe(ctx, mg)
+	if err == nil && !o.ResourceExists && meta.WasDeleted(mg) {
+		// The managed resource is about to be finalized.
+		e.connecter.forget(mg)
+	}
+	return o, err
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	if !Enabled(mg) {
+		return e.client.Create(ctx, mg)
+	}
+	d := &azureclients.DryRun{}
+	cr, err := e.client.Create(azureclients.WithDryRun(ctx, d), mg)
+	rs := d.Requests()
+	if len(rs) == 0 {
+		return cr, err
+	}
+	e.connecter.report(ctx, mg, rs)
+	// The Azure resource was not created, regardless of what Create reported.
+	mg.SetConditions(xpv1.Unavailable())
+	return managed.ExternalCreation{}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	if !Enabled(mg) {
+		return e.client.Update(ctx, mg)
+	}
+	d := &azureclients.DryRun{}
+	u, err := e.client.Update(azureclients.WithDryRun(ctx, d), mg)
+	rs := d.Requests()
+	if len(rs) == 0 {
+		return u, err
+	}
+	e.connecter.report(ctx, mg, rs)
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	if !Enabled(mg) {
+		return e.client.Delete(ctx, mg)
+	}
+	d := &azureclients.DryRun{}
+	err := e.client.Delete(azureclients.WithDryRun(ctx, d), mg)
+	rs := d.Requests()
+	if len(rs) == 0 {
+		return err
+	}
+	e.connecter.report(ctx, mg, rs)
+	return errors.New(errDeleteDryRun)
+}
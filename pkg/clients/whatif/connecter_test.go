@@ -0,0 +1,284 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package whatif
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+const testURL = "https://management.azure.com/subscriptions/bf1b0e59-93da-42e0-82c6-5a1d94227911/resourceGroups/coolRG/providers/Microsoft.Cache/Redis/cool?api-version=2018-03-01"
+
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(_ runtime.Object, e event.Event) { r.events = append(r.events, e) }
+
+func (r *recorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestConnecter(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// send a request as an Azure SDK client configured by ConfigureClient
+	// would, reporting whether it was sent.
+	send := func(ctx context.Context, method string, sent *bool) error {
+		s := autorest.DecorateSender(autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+			*sent = true
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}), azureclients.DryRunSendDecorator(nil))
+		req, _ := http.NewRequestWithContext(ctx, method, testURL, strings.NewReader(`{"location":"westus","properties":{"accessKeys":"secret"}}`))
+		_, err := s.Do(req)
+		return errors.Wrap(err, "cannot send request")
+	}
+	modify := func(_ context.Context, _ azureclients.DryRunRequest) ([]Change, error) {
+		return []Change{{ResourceID: "/cool", ChangeType: "Modify", Delta: []PropertyChange{{Path: "location", PropertyChangeType: "Modify", Before: "eastus", After: "westus"}}}}, nil
+	}
+	unchanged := func(_ context.Context, _ azureclients.DryRunRequest) ([]Change, error) {
+		return []Change{{ResourceID: "/cool", ChangeType: ChangeTypeNoChange}}, nil
+	}
+	unpredictable := func(_ context.Context, _ azureclients.DryRunRequest) ([]Change, error) {
+		return nil, errBoom
+	}
+
+	withAnnotation := func(v string, c ...xpv1.Condition) *fake.Managed {
+		mg := &fake.Managed{}
+		mg.SetName("cool")
+		if v != "" {
+			mg.SetAnnotations(map[string]string{AnnotationKeyDryRun: v})
+		}
+		mg.SetConditions(c...)
+		return mg
+	}
+
+	type args struct {
+		mg      *fake.Managed
+		method  string
+		predict PredictFn
+		err     error
+	}
+	type want struct {
+		err     error
+		sent    bool
+		reason  xpv1.ConditionReason
+		message string
+		events  int
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotEnabled": {
+			reason: "Requests should be sent if the managed resource is not run in dry-run mode.",
+			args:   args{mg: withAnnotation(""), method: http.MethodPut, predict: modify},
+			want:   want{sent: true},
+		},
+		"Disabled": {
+			reason: "Managed resources that are no longer run in dry-run mode should report so.",
+			args:   args{mg: withAnnotation("false", Predicted(ReasonNoChanges, "")), method: http.MethodPut, predict: modify},
+			want:   want{sent: true, reason: ReasonDisabled},
+		},
+		"ChangesPredicted": {
+			reason: "The changes a request would make should be reported rather than made.",
+			args:   args{mg: withAnnotation("true"), method: http.MethodPut, predict: modify},
+			want: want{
+				reason:  ReasonChangesPredicted,
+				message: "Would PUT " + strings.Split(testURL[len("https://management.azure.com"):], "?")[0] + "\nModify /cool\n  Modify location: \"eastus\" => \"westus\"",
+				events:  1,
+			},
+		},
+		"NoChanges": {
+			reason: "Requests that would change nothing should be reported as such.",
+			args:   args{mg: withAnnotation("true"), method: http.MethodPut, predict: unchanged},
+			want: want{
+				reason:  ReasonNoChanges,
+				message: "Would PUT " + strings.Split(testURL[len("https://management.azure.com"):], "?")[0] + "\nNoChange /cool",
+				events:  1,
+			},
+		},
+		"NotPredicted": {
+			reason: "Requests whose changes can't be predicted should be reported with their redacted body.",
+			args:   args{mg: withAnnotation("true"), method: http.MethodPut, predict: unpredictable},
+			want: want{
+				reason:  ReasonNotPredicted,
+				message: "Would PUT " + strings.Split(testURL[len("https://management.azure.com"):], "?")[0] + "\nboom\n" + `{"location":"westus","properties":{"accessKeys":"(sensitive)"}}`,
+				events:  1,
+			},
+		},
+		"FailedBeforeRequest": {
+			reason: "Errors returned before any request would have been sent should be returned.",
+			args:   args{mg: withAnnotation("true"), predict: modify, err: errBoom},
+			want:   want{err: errBoom},
+		},
+		"Delete": {
+			reason: "Managed resources in dry-run mode should not be deleted.",
+			args:   args{mg: withAnnotation("true"), method: http.MethodDelete, predict: modify},
+			want: want{
+				err:     errors.New(errDeleteDryRun),
+				reason:  ReasonNotPredicted,
+				message: "Would DELETE " + strings.Split(testURL[len("https://management.azure.com"):], "?")[0] + "\n" + `{"location":"westus","properties":{"accessKeys":"(sensitive)"}}`,
+				events:  1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sent := false
+			do := func(ctx context.Context) error {
+				if tc.args.err != nil {
+					return tc.args.err
+				}
+				return send(ctx, tc.args.method, &sent)
+			}
+			ec := &managed.ExternalClientFns{
+				ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					return managed.ExternalObservation{}, nil
+				},
+				CreateFn: func(ctx context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+					return managed.ExternalCreation{}, do(ctx)
+				},
+				DeleteFn: func(ctx context.Context, _ resource.Managed) error {
+					return do(ctx)
+				},
+			}
+			r := &recorder{}
+			c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return ec, nil
+			}), r, WithPredictFn(tc.args.predict))
+
+			e, _ := c.Connect(context.Background(), tc.args.mg)
+			_, err := e.Observe(context.Background(), tc.args.mg)
+			if err == nil {
+				if tc.args.method == http.MethodDelete {
+					err = e.Delete(context.Background(), tc.args.mg)
+				} else {
+					_, err = e.Create(context.Background(), tc.args.mg)
+				}
+			}
+
+			if diff := cmp.Diff(tc.want.err, errors.Cause(err), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sent, sent); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want sent, +got sent:\n%s", tc.reason, diff)
+			}
+			got := tc.args.mg.GetCondition(TypeDryRun)
+			if diff := cmp.Diff(tc.want.reason, got.Reason); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want reason, +got reason:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.message, got.Message); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want message, +got message:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, len(r.events)); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnecterReportsOnce(t *testing.T) {
+	mg := &fake.Managed{}
+	mg.SetAnnotations(map[string]string{AnnotationKeyDryRun: "true"})
+
+	predictions, updates := 0, 0
+	predict := func(_ context.Context, _ azureclients.DryRunRequest) ([]Change, error) {
+		predictions++
+		return nil, nil
+	}
+	ec := &managed.ExternalClientFns{
+		UpdateFn: func(ctx context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+			s := autorest.DecorateSender(autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}), azureclients.DryRunSendDecorator(nil))
+			// Each request generates a new password, as e.g. database servers'
+			// requests do.
+			updates++
+			body := fmt.Sprintf(`{"properties":{"administratorLoginPassword":"password-%d"}}`, updates)
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPut, testURL, strings.NewReader(body))
+			_, err := s.Do(req)
+			return managed.ExternalUpdate{}, err
+		},
+	}
+	r := &recorder{}
+	c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return ec, nil
+	}), r, WithPredictFn(predict))
+
+	e, _ := c.Connect(context.Background(), mg)
+	for i := 0; i < 3; i++ {
+		if _, err := e.Update(context.Background(), mg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if predictions != 1 || len(r.events) != 1 {
+		t.Errorf("Update(...): unchanged requests should be predicted and reported once: got %d predictions, %d events", predictions, len(r.events))
+	}
+}
+
+func TestConnecterForgetsDeleted(t *testing.T) {
+	mg := &fake.Managed{}
+	mg.SetUID("cool-uid")
+	mg.SetAnnotations(map[string]string{AnnotationKeyDryRun: "true"})
+
+	ec := &managed.ExternalClientFns{
+		ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+			return managed.ExternalObservation{ResourceExists: false}, nil
+		},
+	}
+	c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return ec, nil
+	}), &recorder{})
+	c.reported[mg.GetUID()] = "digest"
+
+	e, _ := c.Connect(context.Background(), mg)
+	if _, err := e.Observe(context.Background(), mg); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.reported[mg.GetUID()]; !ok {
+		t.Errorf("Observe(...): the requests reported for managed resources that are not deleted should be remembered")
+	}
+
+	now := metav1.Now()
+	mg.SetDeletionTimestamp(&now)
+	if _, err := e.Observe(context.Background(), mg); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.reported[mg.GetUID()]; ok {
+		t.Errorf("Observe(...): the requests reported for deleted managed resources whose Azure resource doesn't exist should be forgotten")
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package whatif runs managed resources in dry-run mode, in which the requests
+// that would modify their Azure resources are not sent. The changes they would
+// make are predicted using the Azure Resource Manager what-if API instead.
+package whatif
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+// AnnotationKeyDryRun runs a managed resource in dry-run mode if it is "true",
+// and never runs it in dry-run mode if it is "false", regardless of the
+// default.
+const AnnotationKeyDryRun = "azure.crossplane.io/dry-run"
+
+// Error strings.
+const (
+	errNotPredictable = "what-if only predicts PUT requests for resources in a resource group"
+	errWhatIf         = "cannot predict changes using the what-if API"
+	errWhatIfFailed   = "what-if operation failed"
+)
+
+const (
+	whatIfAPIVersion = "2020-06-01"
+	whatIfPath       = "/subscriptions/{subscriptionId}/resourcegroups/{resourceGroupName}/providers/Microsoft.Resources/deployments/{deploymentName}/whatIf"
+
+	// The deployment is never created; its name only identifies the what-if
+	// operation.
+	deploymentName = "crossplane-dry-run"
+
+	templateSchema  = "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#"
+	templateVersion = "1.0.0.0"
+
+	// maxMessageLength is the longest message reported by a DryRun condition
+	// or event.
+	maxMessageLength = 1024
+)
+
+// sensitive matches the names of properties whose values are not reported.
+var sensitive = regexp.MustCompile(`(?i)password|secret|key`)
+
+// dryRun is whether managed resources without an AnnotationKeyDryRun
+// annotation are run in dry-run mode.
+var dryRun bool
+
+// SetDefault sets whether managed resources without an AnnotationKeyDryRun
+// annotation are run in dry-run mode. It should be called before any
+// controllers are started.
+func SetDefault(enabled bool) {
+	dryRun = enabled
+}
+
+// Enabled returns true if the supplied managed resource should be run in
+// dry-run mode.
+func Enabled(mg resource.Managed) bool {
+	switch mg.GetAnnotations()[AnnotationKeyDryRun] {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return dryRun
+}
+
+// A Change to an Azure resource, as predicted by the what-if API.
+type Change struct {
+	ResourceID string           `json:"resourceId"`
+	ChangeType string           `json:"changeType"`
+	Delta      []PropertyChange `json:"delta,omitempty"`
+}
+
+// A PropertyChange is a change to a property of an Azure resource.
+type PropertyChange struct {
+	Path               string           `json:"path"`
+	PropertyChangeType string           `json:"propertyChangeType"`
+	Before             interface{}      `json:"before,omitempty"`
+	After              interface{}      `json:"after,omitempty"`
+	Children           []PropertyChange `json:"children,omitempty"`
+}
+
+// Change types that don't change an Azure resource.
+const (
+	ChangeTypeNoChange = "NoChange"
+	ChangeTypeIgnore   = "Ignore"
+)
+
+type whatIfResult struct {
+	Status     string `json:"status"`
+	Properties struct {
+		Changes []Change `json:"changes"`
+	} `json:"properties"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// A PredictFn predicts the changes the supplied request would make.
+type PredictFn func(ctx context.Context, r azureclients.DryRunRequest) ([]Change, error)
+
+// Deployment returns the resource group and the deployment properties of an
+// incremental deployment of the Azure resource the supplied request would
+// create or update. Only PUT requests for resources in a resource group can be
+// deployed.
+func Deployment(r azureclients.DryRunRequest) (string, map[string]interface{}, error) {
+	// subscriptions/{sub}/resourceGroups/{rg}/providers/{namespace}/{type}/{name}[/{type}/{name}...]
+	s := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.Method != http.MethodPut || len(s) < 8 || len(s)%2 != 0 ||
+		!strings.EqualFold(s[0], "subscriptions") || !strings.EqualFold(s[2], "resourceGroups") || !strings.EqualFold(s[4], "providers") {
+		return "", nil, errors.New(errNotPredictable)
+	}
+	types, names := []string{s[5]}, []string{}
+	for i := 6; i < len(s); i += 2 {
+		types = append(types, s[i])
+		names = append(names, s[i+1])
+	}
+
+	res := map[string]interface{}{}
+	if len(r.Body) > 0 {
+		if err := json.Unmarshal(r.Body, &res); err != nil {
+			return "", nil, err
+		}
+	}
+	delete(res, "id")
+	delete(res, "etag")
+	res["type"] = strings.Join(types, "/")
+	res["name"] = strings.Join(names, "/")
+	res["apiVersion"] = r.URL.Query().Get("api-version")
+
+	return s[3], map[string]interface{}{
+		"mode": "Incremental",
+		"template": map[string]interface{}{
+			"$schema":        templateSchema,
+			"contentVersion": templateVersion,
+			"resources":      []interface{}{res},
+		},
+	}, nil
+}
+
+// Predict the changes the supplied request would make using the what-if API.
+// The request is sent to the same endpoint, using the same authorizer, as the
+// request would have been.
+func Predict(ctx context.Context, r azureclients.DryRunRequest) ([]Change, error) {
+	rg, d, err := Deployment(r)
+	if err != nil {
+		return nil, err
+	}
+	sub := azureclients.SubscriptionFromPath(strings.ToLower(strings.Trim(r.URL.Path, "/")))
+
+	cl := autorest.NewClientWithUserAgent(azureclients.UserAgent)
+	azureclients.ConfigureClient(&cl, r.Authorizer)
+
+	req, err := autorest.Prepare((&http.Request{}).WithContext(ctx),
+		autorest.AsContentType("application/json; charset=utf-8"),
+		autorest.AsPost(),
+		autorest.WithBaseURL(r.URL.Scheme+"://"+r.URL.Host),
+		autorest.WithPathParameters(whatIfPath, map[string]interface{}{
+			"subscriptionId":    autorest.Encode("path", sub),
+			"resourceGroupName": autorest.Encode("path", rg),
+			"deploymentName":    autorest.Encode("path", deploymentName),
+		}),
+		autorest.WithQueryParameters(map[string]interface{}{"api-version": whatIfAPIVersion}),
+		autorest.WithJSON(map[string]interface{}{"properties": d}))
+	if err != nil {
+		return nil, errors.Wrap(err, errWhatIf)
+	}
+	resp, err := cl.Send(req, autorest.DoRetryForStatusCodes(cl.RetryAttempts, cl.RetryDuration, autorest.StatusCodesForRetry...))
+	if err != nil {
+		return nil, errors.Wrap(err, errWhatIf)
+	}
+	if err := autorest.Respond(resp, azure.WithErrorUnlessStatusCode(http.StatusOK, http.StatusAccepted)); err != nil {
+		return nil, errors.Wrap(err, errWhatIf)
+	}
+	f, err := azure.NewFutureFromResponse(resp)
+	if err != nil {
+		return nil, errors.Wrap(err, errWhatIf)
+	}
+	if err := f.WaitForCompletionRef(ctx, cl); err != nil {
+		return nil, errors.Wrap(err, errWhatIf)
+	}
+	resp, err = f.GetResult(cl)
+	if err != nil {
+		return nil, errors.Wrap(err, errWhatIf)
+	}
+
+	res := whatIfResult{}
+	if err := autorest.Respond(resp,
+		azure.WithErrorUnlessStatusCode(http.StatusOK),
+		autorest.ByUnmarshallingJSON(&res),
+		autorest.ByClosing()); err != nil {
+		return nil, errors.Wrap(err, errWhatIf)
+	}
+	if res.Error != nil {
+		return nil, errors.Errorf("%s: %s: %s", errWhatIfFailed, res.Error.Code, res.Error.Message)
+	}
+	return res.Properties.Changes, nil
+}
+
+// Changed returns true if any of the supplied changes would change an Azure
+// resource.
+func Changed(cs []Change) bool {
+	for _, c := range cs {
+		if c.ChangeType != ChangeTypeNoChange && c.ChangeType != ChangeTypeIgnore {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe the supplied changes, one line per changed property. The values of
+// sensitive properties are omitted.
+func Describe(cs []Change) string {
+	lines := make([]string, 0, len(cs))
+	for _, c := range cs {
+		lines = append(lines, c.ChangeType+" "+c.ResourceID)
+		for _, p := range flatten("", c.Delta) {
+			lines = append(lines, fmt.Sprintf("  %s %s: %s => %s", p.PropertyChangeType, p.Path, value(p.Path, p.Before), value(p.Path, p.After)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// flatten the supplied property changes, such that each has the full path of
+// the property it changes.
+func flatten(parent string, ps []PropertyChange) []PropertyChange {
+	out := make([]PropertyChange, 0, len(ps))
+	for _, p := range ps {
+		if parent != "" {
+			p.Path = parent + "." + p.Path
+		}
+		if len(p.Children) > 0 {
+			out = append(out, flatten(p.Path, p.Children)...)
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func value(path string, v interface{}) string {
+	switch {
+	case v == nil:
+		return "(none)"
+	case sensitive.MatchString(path):
+		return "(sensitive)"
+	}
+	b, err := json.Marshal(Redact(v))
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// Redact returns a copy of the supplied JSON value in which the values of
+// sensitive properties are omitted.
+func Redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			if sensitive.MatchString(k) {
+				out[k] = "(sensitive)"
+				continue
+			}
+			out[k] = Redact(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i := range t {
+			out[i] = Redact(t[i])
+		}
+		return out
+	}
+	return v
+}
+
+// truncate the supplied message to maxMessageLength.
+func truncate(msg string) string {
+	if len(msg) <= maxMessageLength {
+		return msg
+	}
+	return msg[:maxMessageLength-3] + "..."
+}
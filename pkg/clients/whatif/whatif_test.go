@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package whatif
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+func TestDeployment(t *testing.T) {
+	parse := func(s string) *url.URL {
+		u, _ := url.Parse(s)
+		return u
+	}
+	base := "https://management.azure.com/subscriptions/bf1b0e59-93da-42e0-82c6-5a1d94227911/resourceGroups/coolRG"
+
+	type want struct {
+		rg       string
+		resource map[string]interface{}
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		r      azureclients.DryRunRequest
+		want   want
+	}{
+		"Resource": {
+			reason: "A resource should be deployed by its type and name, with the properties of its request body.",
+			r: azureclients.DryRunRequest{
+				Method: http.MethodPut,
+				URL:    parse(base + "/providers/Microsoft.Cache/Redis/cool?api-version=2018-03-01"),
+				Body:   []byte(`{"id":"ignored","location":"westus","properties":{"enableNonSslPort":true}}`),
+			},
+			want: want{rg: "coolRG", resource: map[string]interface{}{
+				"type":       "Microsoft.Cache/Redis",
+				"name":       "cool",
+				"apiVersion": "2018-03-01",
+				"location":   "westus",
+				"properties": map[string]interface{}{"enableNonSslPort": true},
+			}},
+		},
+		"ChildResource": {
+			reason: "A child resource should be deployed by the types and names of it and its parents.",
+			r: azureclients.DryRunRequest{
+				Method: http.MethodPut,
+				URL:    parse(base + "/providers/Microsoft.Network/virtualNetworks/coolVNet/subnets/coolSubnet?api-version=2019-06-01"),
+			},
+			want: want{rg: "coolRG", resource: map[string]interface{}{
+				"type":       "Microsoft.Network/virtualNetworks/subnets",
+				"name":       "coolVNet/coolSubnet",
+				"apiVersion": "2019-06-01",
+			}},
+		},
+		"ResourceGroup": {
+			reason: "Resource groups are not in a resource group, and thus can't be deployed to one.",
+			r: azureclients.DryRunRequest{
+				Method: http.MethodPut,
+				URL:    parse(base + "?api-version=2019-05-01"),
+			},
+			want: want{err: errors.New(errNotPredictable)},
+		},
+		"Post": {
+			reason: "Only PUT requests can be deployed.",
+			r: azureclients.DryRunRequest{
+				Method: http.MethodPost,
+				URL:    parse(base + "/providers/Microsoft.Cache/Redis/cool/regenerateKey?api-version=2018-03-01"),
+			},
+			want: want{err: errors.New(errNotPredictable)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rg, d, err := Deployment(tc.r)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDeployment(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rg, rg); diff != "" {
+				t.Errorf("\n%s\nDeployment(...): -want resource group, +got resource group:\n%s", tc.reason, diff)
+			}
+			var got map[string]interface{}
+			if d != nil {
+				got = d["template"].(map[string]interface{})["resources"].([]interface{})[0].(map[string]interface{})
+			}
+			if diff := cmp.Diff(tc.want.resource, got); diff != "" {
+				t.Errorf("\n%s\nDeployment(...): -want resource, +got resource:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cs     []Change
+		want   string
+	}{
+		"Create": {
+			reason: "Resources that would be created should be described by their ID.",
+			cs:     []Change{{ResourceID: "/cool", ChangeType: "Create"}},
+			want:   "Create /cool",
+		},
+		"Modify": {
+			reason: "Nested property changes should be described by their full path, omitting sensitive values.",
+			cs: []Change{{ResourceID: "/cool", ChangeType: "Modify", Delta: []PropertyChange{
+				{Path: "properties", PropertyChangeType: "Modify", Children: []PropertyChange{
+					{Path: "sku.capacity", PropertyChangeType: "Modify", Before: 1.0, After: 2.0},
+					{Path: "administratorLoginPassword", PropertyChangeType: "Create", After: "hunter2"},
+				}},
+				{Path: "tags.env", PropertyChangeType: "Delete", Before: "dev"},
+			}}},
+			want: "Modify /cool\n" +
+				"  Modify properties.sku.capacity: 1 => 2\n" +
+				"  Create properties.administratorLoginPassword: (none) => (sensitive)\n" +
+				"  Delete tags.env: \"dev\" => (none)",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Describe(tc.cs)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDescribe(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managementlock
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-09-01/locks"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/apis/authorization/v1alpha3"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/authorization"
+	"github.com/crossplane/provider-azure/pkg/clients/azureerrors"
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/clients/whatif"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
+)
+
+// Error strings.
+const (
+	errNotManagementLock    = "managed resource is not a ManagementLock"
+	errGetManagementLock    = "cannot get ManagementLock"
+	errCreateManagementLock = "cannot create ManagementLock"
+	errUpdateManagementLock = "cannot update ManagementLock"
+	errDeleteManagementLock = "cannot delete ManagementLock"
+)
+
+// Setup adds a controller that reconciles ManagementLocks.
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
+	name := managed.ControllerName(v1alpha3.ManagementLockGroupKind)
+
+	events, err := eventgrid.NewSource(mgr, &v1alpha3.ManagementLock{}, resourcePath)
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(controller.Options{
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
+		}).
+		For(&v1alpha3.ManagementLock{}).
+		Watches(events, &handler.EnqueueRequestForObject{}).
+		Complete(tracing.NewReconciler(name, managed.NewReconciler(mgr,
+			resource.ManagedKind(v1alpha3.ManagementLockGroupVersionKind),
+			managed.WithConnectionPublishers(),
+			managed.WithExternalConnecter(tracing.NewConnecter(name, azureerrors.NewConnecter(policy.NewConnecter(whatif.NewConnecter(&connecter{kube: mgr.GetClient()}, event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))),
+			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+			managed.WithPollInterval(poll),
+			managed.WithLogger(l.WithValues("controller", name)),
+			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))
+}
+
+type connecter struct {
+	kube client.Client
+}
+
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	creds, auth, err := azure.GetAuthInfo(ctx, c.kube, mg)
+	if err != nil {
+		return nil, err
+	}
+	cl := locks.NewManagementLocksClientWithBaseURI(creds[azure.CredentialsKeyResourceManagerEndpointURL], creds[azure.CredentialsKeySubscriptionID])
+	azure.ConfigureClient(&cl.Client, auth)
+	return &external{client: cl, subscriptionID: creds[azure.CredentialsKeySubscriptionID]}, nil
+}
+
+// external is a createsyncdeleter using the Azure management locks API.
+// Management locks are created, updated, and deleted synchronously.
+type external struct {
+	client         authorization.LocksClient
+	subscriptionID string
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha3.ManagementLock)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotManagementLock)
+	}
+	scope, err := authorization.Scope(e.subscriptionID, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	l, err := e.client.GetByScope(ctx, scope, meta.GetExternalName(cr))
+	if azure.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetManagementLock)
+	}
+
+	authorization.UpdateManagementLockObservation(&cr.Status.AtProvider, l)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: authorization.IsUpToDate(cr.Spec.ForProvider, l),
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha3.ManagementLock)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotManagementLock)
+	}
+	scope, err := authorization.Scope(e.subscriptionID, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	l, err := e.client.CreateOrUpdateByScope(ctx, scope, meta.GetExternalName(cr), authorization.NewManagementLockObject(cr.Spec.ForProvider))
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateManagementLock)
+	}
+	authorization.UpdateManagementLockObservation(&cr.Status.AtProvider, l)
+	return managed.ExternalCreation{}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha3.ManagementLock)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotManagementLock)
+	}
+	scope, err := authorization.Scope(e.subscriptionID, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	l, err := e.client.CreateOrUpdateByScope(ctx, scope, meta.GetExternalName(cr), authorization.NewManagementLockObject(cr.Spec.ForProvider))
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateManagementLock)
+	}
+	authorization.UpdateManagementLockObservation(&cr.Status.AtProvider, l)
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha3.ManagementLock)
+	if !ok {
+		return errors.New(errNotManagementLock)
+	}
+	scope, err := authorization.Scope(e.subscriptionID, cr.Spec.ForProvider)
+	if err != nil {
+		return err
+	}
+
+	cr.SetConditions(xpv1.Deleting())
+	_, err = e.client.DeleteByScope(ctx, scope, meta.GetExternalName(cr))
+	return errors.Wrap(resource.Ignore(azure.IsNotFound, err), errDeleteManagementLock)
+}
+
+// resourcePath returns the path of the ManagementLock's Azure resource, once
+// it has been observed.
+func resourcePath(mg resource.Managed) string {
+	cr, ok := mg.(*v1alpha3.ManagementLock)
+	if !ok || cr.Status.AtProvider.ID == "" {
+		return ""
+	}
+	return eventgrid.PathFromID(cr.Status.AtProvider.ID)
+}
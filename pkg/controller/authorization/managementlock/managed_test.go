@@ -0,0 +1,351 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managementlock
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2016-09-01/locks"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-azure/apis/authorization/v1alpha3"
+	"github.com/crossplane/provider-azure/pkg/clients/authorization/fake"
+)
+
+const (
+	name           = "cool-lock"
+	subscriptionID = "cool-sub"
+	resourceGroup  = "cool-rg"
+	scope          = "/subscriptions/cool-sub/resourceGroups/cool-rg"
+	lockID         = scope + "/providers/Microsoft.Authorization/locks/cool-lock"
+	lockType       = "Microsoft.Authorization/locks"
+)
+
+type lockModifier func(*v1alpha3.ManagementLock)
+
+func withLevel(l v1alpha3.LockLevel) lockModifier {
+	return func(cr *v1alpha3.ManagementLock) { cr.Spec.ForProvider.Level = l }
+}
+
+func withResourceGroupName(n string) lockModifier {
+	return func(cr *v1alpha3.ManagementLock) { cr.Spec.ForProvider.ResourceGroupName = n }
+}
+
+func withConditions(c ...xpv1.Condition) lockModifier {
+	return func(cr *v1alpha3.ManagementLock) { cr.Status.SetConditions(c...) }
+}
+
+func withObservation(o v1alpha3.ManagementLockObservation) lockModifier {
+	return func(cr *v1alpha3.ManagementLock) { cr.Status.AtProvider = o }
+}
+
+func lock(m ...lockModifier) *v1alpha3.ManagementLock {
+	cr := &v1alpha3.ManagementLock{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha3.ManagementLockSpec{
+			ForProvider: v1alpha3.ManagementLockParameters{
+				Level:             v1alpha3.LockLevelCanNotDelete,
+				ResourceGroupName: resourceGroup,
+			},
+		},
+	}
+	meta.SetExternalName(cr, name)
+	for _, f := range m {
+		f(cr)
+	}
+	return cr
+}
+
+func azureLock(level locks.LockLevel) locks.ManagementLockObject {
+	return locks.ManagementLockObject{
+		ManagementLockProperties: &locks.ManagementLockProperties{Level: level},
+		ID:                       to.StringPtr(lockID),
+		Name:                     to.StringPtr(name),
+		Type:                     to.StringPtr(lockType),
+	}
+}
+
+var observation = v1alpha3.ManagementLockObservation{ID: lockID, Name: name, Type: lockType}
+
+var errNotFound = autorest.DetailedError{StatusCode: http.StatusNotFound}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		o   managed.ExternalObservation
+		mg  resource.Managed
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		e      managed.ExternalClient
+		mg     resource.Managed
+		want   want
+	}{
+		"NotManagementLock": {
+			reason: "An error should be returned if the managed resource is not a ManagementLock.",
+			e:      &external{},
+			want:   want{err: errors.New(errNotManagementLock)},
+		},
+		"NoScope": {
+			reason: "An error should be returned if the ManagementLock does not specify what it locks.",
+			e:      &external{subscriptionID: subscriptionID},
+			mg:     lock(withResourceGroupName("")),
+			want: want{
+				mg:  lock(withResourceGroupName("")),
+				err: errors.New("a management lock must specify or reference either a resource ID or a resource group"),
+			},
+		},
+		"NotFound": {
+			reason: "ManagementLocks whose Azure lock does not exist should not exist.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockGetByScope: func(_ context.Context, _ string, _ string) (locks.ManagementLockObject, error) {
+					return locks.ManagementLockObject{}, errNotFound
+				},
+			}},
+			mg:   lock(),
+			want: want{mg: lock()},
+		},
+		"GetError": {
+			reason: "Errors getting the Azure lock should be returned.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockGetByScope: func(_ context.Context, _ string, _ string) (locks.ManagementLockObject, error) {
+					return locks.ManagementLockObject{}, errBoom
+				},
+			}},
+			mg:   lock(),
+			want: want{mg: lock(), err: errors.Wrap(errBoom, errGetManagementLock)},
+		},
+		"UpToDate": {
+			reason: "ManagementLocks whose Azure lock matches should be available and up to date.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockGetByScope: func(_ context.Context, s string, n string) (locks.ManagementLockObject, error) {
+					if s != scope || n != name {
+						return locks.ManagementLockObject{}, errNotFound
+					}
+					return azureLock(locks.CanNotDelete), nil
+				},
+			}},
+			mg: lock(),
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				mg: lock(withConditions(xpv1.Available()), withObservation(observation)),
+			},
+		},
+		"NeedsUpdate": {
+			reason: "ManagementLocks whose Azure lock has a different level should not be up to date.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockGetByScope: func(_ context.Context, _ string, _ string) (locks.ManagementLockObject, error) {
+					return azureLock(locks.CanNotDelete), nil
+				},
+			}},
+			mg: lock(withLevel(v1alpha3.LockLevelReadOnly)),
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				mg: lock(withLevel(v1alpha3.LockLevelReadOnly), withConditions(xpv1.Available()), withObservation(observation)),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.e.Observe(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.mg, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want managed resource, +got managed resource:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		mg  resource.Managed
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		e      managed.ExternalClient
+		mg     resource.Managed
+		want   want
+	}{
+		"NotManagementLock": {
+			reason: "An error should be returned if the managed resource is not a ManagementLock.",
+			e:      &external{},
+			want:   want{err: errors.New(errNotManagementLock)},
+		},
+		"CreateError": {
+			reason: "Errors creating the Azure lock should be returned.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockCreateOrUpdateByScope: func(_ context.Context, _ string, _ string, _ locks.ManagementLockObject) (locks.ManagementLockObject, error) {
+					return locks.ManagementLockObject{}, errBoom
+				},
+			}},
+			mg:   lock(),
+			want: want{mg: lock(withConditions(xpv1.Creating())), err: errors.Wrap(errBoom, errCreateManagementLock)},
+		},
+		"Success": {
+			reason: "The Azure lock should be created at the ManagementLock's scope, with its level.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockCreateOrUpdateByScope: func(_ context.Context, s string, n string, p locks.ManagementLockObject) (locks.ManagementLockObject, error) {
+					if s != scope || n != name || p.Level != locks.CanNotDelete {
+						return locks.ManagementLockObject{}, errBoom
+					}
+					return azureLock(p.Level), nil
+				},
+			}},
+			mg:   lock(),
+			want: want{mg: lock(withConditions(xpv1.Creating()), withObservation(observation))},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.mg, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want managed resource, +got managed resource:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		mg  resource.Managed
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		e      managed.ExternalClient
+		mg     resource.Managed
+		want   want
+	}{
+		"NotManagementLock": {
+			reason: "An error should be returned if the managed resource is not a ManagementLock.",
+			e:      &external{},
+			want:   want{err: errors.New(errNotManagementLock)},
+		},
+		"UpdateError": {
+			reason: "Errors updating the Azure lock should be returned.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockCreateOrUpdateByScope: func(_ context.Context, _ string, _ string, _ locks.ManagementLockObject) (locks.ManagementLockObject, error) {
+					return locks.ManagementLockObject{}, errBoom
+				},
+			}},
+			mg:   lock(),
+			want: want{mg: lock(), err: errors.Wrap(errBoom, errUpdateManagementLock)},
+		},
+		"Success": {
+			reason: "The Azure lock should be updated to the ManagementLock's level.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockCreateOrUpdateByScope: func(_ context.Context, _ string, _ string, p locks.ManagementLockObject) (locks.ManagementLockObject, error) {
+					if p.Level != locks.ReadOnly {
+						return locks.ManagementLockObject{}, errBoom
+					}
+					return azureLock(p.Level), nil
+				},
+			}},
+			mg:   lock(withLevel(v1alpha3.LockLevelReadOnly)),
+			want: want{mg: lock(withLevel(v1alpha3.LockLevelReadOnly), withObservation(observation))},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nUpdate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.mg, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nUpdate(...): -want managed resource, +got managed resource:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		e      managed.ExternalClient
+		mg     resource.Managed
+		want   error
+	}{
+		"NotManagementLock": {
+			reason: "An error should be returned if the managed resource is not a ManagementLock.",
+			e:      &external{},
+			want:   errors.New(errNotManagementLock),
+		},
+		"NotFound": {
+			reason: "Azure locks that do not exist should be considered deleted.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockDeleteByScope: func(_ context.Context, _ string, _ string) (autorest.Response, error) {
+					return autorest.Response{}, errNotFound
+				},
+			}},
+			mg: lock(),
+		},
+		"DeleteError": {
+			reason: "Errors deleting the Azure lock should be returned.",
+			e: &external{subscriptionID: subscriptionID, client: &fake.MockManagementLocksClient{
+				MockDeleteByScope: func(_ context.Context, _ string, _ string) (autorest.Response, error) {
+					return autorest.Response{}, errBoom
+				},
+			}},
+			mg:   lock(),
+			want: errors.Wrap(errBoom, errDeleteManagementLock),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.e.Delete(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDelete(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
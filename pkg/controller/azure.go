@@ -17,13 +17,27 @@ limitations under the License.
 package controller
 
 import (
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 
+	authorizationv1alpha3 "github.com/crossplane/provider-azure/apis/authorization/v1alpha3"
+	cachev1beta1 "github.com/crossplane/provider-azure/apis/cache/v1beta1"
+	computev1alpha3 "github.com/crossplane/provider-azure/apis/compute/v1alpha3"
+	databasev1alpha3 "github.com/crossplane/provider-azure/apis/database/v1alpha3"
+	databasev1beta1 "github.com/crossplane/provider-azure/apis/database/v1beta1"
+	networkv1alpha3 "github.com/crossplane/provider-azure/apis/network/v1alpha3"
+	storagev1alpha3 "github.com/crossplane/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1alpha3"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/azureerrors"
+	"github.com/crossplane/provider-azure/pkg/controller/authorization/managementlock"
 	"github.com/crossplane/provider-azure/pkg/controller/cache"
 	"github.com/crossplane/provider-azure/pkg/controller/compute"
 	"github.com/crossplane/provider-azure/pkg/controller/config"
@@ -42,28 +56,181 @@ import (
 	"github.com/crossplane/provider-azure/pkg/controller/storage/container"
 )
 
-// Setup Azure controllers.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
-	for _, setup := range []func(ctrl.Manager, logging.Logger, workqueue.RateLimiter, time.Duration) error{
-		cache.SetupRedis,
-		compute.SetupAKSCluster,
-		mysqlserver.Setup,
-		mysqlserverfirewallrule.Setup,
-		mysqlservervirtualnetworkrule.Setup,
-		postgresqlserver.Setup,
-		postgresqlserverfirewallrule.Setup,
-		postgresqlservervirtualnetworkrule.Setup,
-		postgresqlserverconfiguration.Setup,
-		cosmosdb.Setup,
-		virtualnetwork.Setup,
-		subnet.Setup,
-		resourcegroup.Setup,
-		account.Setup,
-		container.Setup,
-	} {
-		if err := setup(mgr, l, rl, poll); err != nil {
+const (
+	errUnknownController = "unknown controller group or kind"
+	errConcurrency       = "concurrency must be at least 1"
+)
+
+// A SetupFn adds a controller that reconciles managed resources of a kind.
+type SetupFn func(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error
+
+// A Controller reconciles the managed resources of a kind.
+type Controller struct {
+	// Group is the API group of the kind, e.g. network.azure.crossplane.io.
+	Group string
+
+	// Kind of managed resource, e.g. VirtualNetwork.
+	Kind string
+
+	// Setup adds the controller to a manager.
+	Setup SetupFn
+}
+
+// Matches returns true if the supplied name refers to the Controller's group
+// or kind. Names are case insensitive.
+func (c Controller) Matches(name string) bool {
+	return c.matchesGroup(name) || c.matchesKind(name)
+}
+
+// matchesGroup returns true if the supplied name is the full name of the
+// Controller's group, or the first segment of its name, e.g. 'network'.
+func (c Controller) matchesGroup(name string) bool {
+	return strings.EqualFold(name, c.Group) || strings.EqualFold(name, strings.SplitN(c.Group, ".", 2)[0])
+}
+
+// matchesKind returns true if the supplied name is the Controller's kind, or
+// its kind and group, e.g. 'VirtualNetwork.network.azure.crossplane.io'.
+func (c Controller) matchesKind(name string) bool {
+	return strings.EqualFold(name, c.Kind) || strings.EqualFold(name, c.Kind+"."+c.Group)
+}
+
+// Controllers that reconcile Azure managed resources.
+var Controllers = []Controller{
+	{Group: authorizationv1alpha3.Group, Kind: authorizationv1alpha3.ManagementLockKind, Setup: managementlock.Setup},
+	{Group: cachev1beta1.Group, Kind: cachev1beta1.RedisKind, Setup: cache.SetupRedis},
+	{Group: computev1alpha3.Group, Kind: computev1alpha3.AKSClusterKind, Setup: compute.SetupAKSCluster},
+	{Group: databasev1beta1.Group, Kind: databasev1beta1.MySQLServerKind, Setup: mysqlserver.Setup},
+	{Group: databasev1alpha3.Group, Kind: databasev1alpha3.MySQLServerFirewallRuleKind, Setup: mysqlserverfirewallrule.Setup},
+	{Group: databasev1alpha3.Group, Kind: databasev1alpha3.MySQLServerVirtualNetworkRuleKind, Setup: mysqlservervirtualnetworkrule.Setup},
+	{Group: databasev1beta1.Group, Kind: databasev1beta1.PostgreSQLServerKind, Setup: postgresqlserver.Setup},
+	{Group: databasev1alpha3.Group, Kind: databasev1alpha3.PostgreSQLServerFirewallRuleKind, Setup: postgresqlserverfirewallrule.Setup},
+	{Group: databasev1alpha3.Group, Kind: databasev1alpha3.PostgreSQLServerVirtualNetworkRuleKind, Setup: postgresqlservervirtualnetworkrule.Setup},
+	{Group: databasev1beta1.Group, Kind: databasev1beta1.PostgreSQLServerConfigurationKind, Setup: postgresqlserverconfiguration.Setup},
+	{Group: databasev1alpha3.Group, Kind: databasev1alpha3.CosmosDBAccountKind, Setup: cosmosdb.Setup},
+	{Group: networkv1alpha3.Group, Kind: networkv1alpha3.VirtualNetworkKind, Setup: virtualnetwork.Setup},
+	{Group: networkv1alpha3.Group, Kind: networkv1alpha3.SubnetKind, Setup: subnet.Setup},
+	{Group: v1alpha3.Group, Kind: v1alpha3.ResourceGroupKind, Setup: resourcegroup.Setup},
+	{Group: storagev1alpha3.Group, Kind: storagev1alpha3.AccountKind, Setup: account.Setup},
+	{Group: storagev1alpha3.Group, Kind: storagev1alpha3.ContainerKind, Setup: container.Setup},
+}
+
+// Options configures which controllers are set up, and how they reconcile.
+type Options struct {
+	// PollInterval is how often an individual resource is checked for drift.
+	PollInterval time.Duration
+
+	// HealthInterval is how often the credentials of a ProviderConfig are
+	// validated.
+	HealthInterval time.Duration
+
+	// Enabled controllers, by group or kind. All controllers are enabled if
+	// none are specified.
+	Enabled []string
+
+	// Disabled controllers, by group or kind. Takes precedence over Enabled.
+	Disabled []string
+
+	// Concurrency is the number of resources each controller may reconcile
+	// concurrently, unless overridden by GroupConcurrency.
+	Concurrency int
+
+	// GroupConcurrency is the number of resources each controller of a group
+	// or kind may reconcile concurrently. Kinds take precedence over groups.
+	GroupConcurrency map[string]int
+}
+
+// Select returns the supplied controllers that are enabled by the Options. It
+// returns an error if the Options refer to an unknown group or kind, or
+// specify a concurrency less than one.
+func (o Options) Select(cs []Controller) ([]Controller, error) {
+	for _, names := range [][]string{o.Enabled, o.Disabled, keys(o.GroupConcurrency)} {
+		for _, n := range names {
+			if !matchesAny(cs, n) {
+				return nil, errors.Errorf("%s: %s", errUnknownController, n)
+			}
+		}
+	}
+	if o.Concurrency < 1 {
+		return nil, errors.New(errConcurrency)
+	}
+	for name, n := range o.GroupConcurrency {
+		if n < 1 {
+			return nil, errors.Errorf("%s: %s", errConcurrency, name)
+		}
+	}
+
+	selected := make([]Controller, 0, len(cs))
+	for _, c := range cs {
+		if len(o.Enabled) > 0 && !matches(c, o.Enabled) {
+			continue
+		}
+		if matches(c, o.Disabled) {
+			continue
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+// ConcurrencyOf returns the number of resources the supplied controller may
+// reconcile concurrently. The Options must have been validated by Select.
+func (o Options) ConcurrencyOf(c Controller) int {
+	n := o.Concurrency
+	for name, gc := range o.GroupConcurrency {
+		// A kind is more specific than its group.
+		if c.matchesKind(name) {
+			return gc
+		}
+		if c.matchesGroup(name) {
+			n = gc
+		}
+	}
+	return n
+}
+
+func matches(c Controller, names []string) bool {
+	for _, n := range names {
+		if c.Matches(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(cs []Controller, name string) bool {
+	for _, c := range cs {
+		if c.Matches(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func keys(m map[string]int) []string {
+	k := make([]string, 0, len(m))
+	for n := range m {
+		k = append(k, n)
+	}
+	return k
+}
+
+// Setup Azure controllers. Each managed resource controller's rate limiter
+// also delays the managed resources of its kind while the subscriptions they
+// make requests to are throttled, or according to their most recent error.
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, o Options) error {
+	cs, err := o.Select(Controllers)
+	if err != nil {
+		return err
+	}
+	for _, c := range cs {
+		gk := schema.GroupKind{Group: c.Group, Kind: c.Kind}
+		krl := azureerrors.NewRateLimiter(azure.NewRateLimiter(rl, gk), gk)
+		if err := c.Setup(mgr, l, krl, o.PollInterval, o.ConcurrencyOf(c)); err != nil {
 			return err
 		}
 	}
+	if err := config.SetupHealth(mgr, l, rl, o.HealthInterval); err != nil {
+		return err
+	}
 	return config.Setup(mgr, l, rl)
 }
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var (
+	testVNet   = Controller{Group: "network.azure.crossplane.io", Kind: "VirtualNetwork"}
+	testSubnet = Controller{Group: "network.azure.crossplane.io", Kind: "Subnet"}
+	testRedis  = Controller{Group: "cache.azure.crossplane.io", Kind: "Redis"}
+	testAKS    = Controller{Group: "compute.azure.crossplane.io", Kind: "AKSCluster"}
+)
+
+func kinds(cs []Controller) []string {
+	k := make([]string, len(cs))
+	for i, c := range cs {
+		k[i] = c.Kind
+	}
+	return k
+}
+
+func TestSelect(t *testing.T) {
+	type want struct {
+		kinds []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      Options
+		want   want
+	}{
+		"All": {
+			reason: "All controllers should be selected if none are enabled.",
+			o:      Options{Concurrency: 1},
+			want:   want{kinds: []string{"VirtualNetwork", "Subnet", "Redis", "AKSCluster"}},
+		},
+		"EnabledGroups": {
+			reason: "Only controllers of enabled groups should be selected.",
+			o:      Options{Concurrency: 1, Enabled: []string{"network", "cache.azure.crossplane.io"}},
+			want:   want{kinds: []string{"VirtualNetwork", "Subnet", "Redis"}},
+		},
+		"EnabledKind": {
+			reason: "Controllers should be selectable by case insensitive kind.",
+			o:      Options{Concurrency: 1, Enabled: []string{"akscluster"}},
+			want:   want{kinds: []string{"AKSCluster"}},
+		},
+		"Disabled": {
+			reason: "Disabled controllers should not be selected, even if their group is enabled.",
+			o:      Options{Concurrency: 1, Enabled: []string{"network"}, Disabled: []string{"Subnet.network.azure.crossplane.io"}},
+			want:   want{kinds: []string{"VirtualNetwork"}},
+		},
+		"Unknown": {
+			reason: "Unknown groups and kinds should be rejected.",
+			o:      Options{Concurrency: 1, Enabled: []string{"netwerk"}},
+			want:   want{err: errors.Errorf("%s: %s", errUnknownController, "netwerk")},
+		},
+		"UnknownConcurrency": {
+			reason: "Concurrency of unknown groups and kinds should be rejected.",
+			o:      Options{Concurrency: 1, GroupConcurrency: map[string]int{"storage": 2}},
+			want:   want{err: errors.Errorf("%s: %s", errUnknownController, "storage")},
+		},
+		"InvalidConcurrency": {
+			reason: "Concurrency less than one should be rejected.",
+			o:      Options{Concurrency: 0},
+			want:   want{err: errors.New(errConcurrency)},
+		},
+		"InvalidGroupConcurrency": {
+			reason: "Group concurrency less than one should be rejected.",
+			o:      Options{Concurrency: 1, GroupConcurrency: map[string]int{"compute": 0}},
+			want:   want{err: errors.Errorf("%s: %s", errConcurrency, "compute")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.o.Select([]Controller{testVNet, testSubnet, testRedis, testAKS})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSelect(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.kinds, kinds(got)); diff != "" {
+				t.Errorf("\n%s\nSelect(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConcurrencyOf(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      Options
+		c      Controller
+		want   int
+	}{
+		"Default": {
+			reason: "Controllers should reconcile one resource at a time by default.",
+			o:      Options{Concurrency: 1},
+			c:      testAKS,
+			want:   1,
+		},
+		"Concurrency": {
+			reason: "Controllers should use the concurrency of the Options if their group has none.",
+			o:      Options{Concurrency: 3, GroupConcurrency: map[string]int{"network": 2}},
+			c:      testAKS,
+			want:   3,
+		},
+		"Group": {
+			reason: "Controllers should use the concurrency of their group.",
+			o:      Options{Concurrency: 3, GroupConcurrency: map[string]int{"compute": 5}},
+			c:      testAKS,
+			want:   5,
+		},
+		"Kind": {
+			reason: "The concurrency of a kind should take precedence over that of its group.",
+			o:      Options{Concurrency: 1, GroupConcurrency: map[string]int{"network": 2, "Subnet": 4}},
+			c:      testSubnet,
+			want:   4,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.o.ConcurrencyOf(tc.c)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nConcurrencyOf(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -18,16 +18,19 @@ package cache
 
 import (
 	"context"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/redis/mgmt/redis"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/redis/mgmt/redis/redisapi"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/pkg/errors"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
@@ -38,13 +41,18 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-azure/apis/cache/v1beta1"
+	azurev1beta1 "github.com/crossplane/provider-azure/apis/v1beta1"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/azureerrors"
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
 	redisclients "github.com/crossplane/provider-azure/pkg/clients/redis"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/clients/whatif"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
 )
 
 const (
-	errNotRedis            = "the custom resource is not a Redis instance"
-	errUpdateRedisCRFailed = "cannot update Redis custom resource instance"
+	errNotRedis = "the custom resource is not a Redis instance"
 
 	errConnectFailed        = "cannot connect to Azure API"
 	errGetFailed            = "cannot get Redis instance from Azure API"
@@ -52,25 +60,34 @@ const (
 	errCreateFailed         = "cannot create the Redis instance"
 	errUpdateFailed         = "cannot update the Redis instance"
 	errDeleteFailed         = "cannot delete the Redis instance"
+	errFetchLastOperation   = "cannot fetch last operation"
 )
 
 // SetupRedis adds a controller that reconciles Redis resources.
-func SetupRedis(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+func SetupRedis(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
 	name := managed.ControllerName(v1beta1.RedisGroupKind)
 
+	events, err := eventgrid.NewSource(mgr, &v1beta1.Redis{}, resourcePath)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(controller.Options{
-			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
 		}).
 		For(&v1beta1.Redis{}).
-		Complete(managed.NewReconciler(mgr,
+		Watches(events, &handler.EnqueueRequestForObject{}).
+		Complete(tracing.NewReconciler(name, managed.NewReconciler(mgr,
 			resource.ManagedKind(v1beta1.RedisGroupVersionKind),
-			managed.WithExternalConnecter(&connector{kube: mgr.GetClient()}),
+			managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), azure.NewDefaultsInitializer(mgr.GetClient(), setDefaults)),
+			managed.WithExternalConnecter(tracing.NewConnecter(name, azureerrors.NewConnecter(policy.NewConnecter(whatif.NewConnecter(&connector{kube: mgr.GetClient()}, event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))),
 			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
 			managed.WithPollInterval(poll),
 			managed.WithLogger(l.WithValues("controller", name)),
-			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
+			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))
 }
 
 type connector struct {
@@ -82,14 +99,14 @@ func (c connector) Connect(ctx context.Context, mg resource.Managed) (managed.Ex
 	if err != nil {
 		return nil, errors.Wrap(err, errConnectFailed)
 	}
-	cl := redis.NewClient(creds[azure.CredentialsKeySubscriptionID])
-	cl.Authorizer = auth
-	return &external{kube: c.kube, client: cl}, nil
+	cl := redis.NewClientWithBaseURI(creds[azure.CredentialsKeyResourceManagerEndpointURL], creds[azure.CredentialsKeySubscriptionID])
+	azure.ConfigureClient(&cl.Client, auth)
+	return &external{client: cl, sender: cl.Client}, nil
 }
 
 type external struct {
-	kube   client.Client
 	client redisapi.ClientAPI
+	sender autorest.Sender
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -98,15 +115,23 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotRedis)
 	}
 	cache, err := c.client.Get(ctx, cr.Spec.ForProvider.ResourceGroupName, meta.GetExternalName(cr))
+	if azure.IsNotFound(err) {
+		if err := azure.FetchAsyncOperation(ctx, c.sender, &cr.Status.AtProvider.LastOperation); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+		}
+		return managed.ExternalObservation{ResourceExists: azure.IsCreating(cr.Status.AtProvider.LastOperation)}, nil
+	}
 	if err != nil {
-		return managed.ExternalObservation{ResourceExists: false}, errors.Wrap(resource.Ignore(azure.IsNotFound, err), errGetFailed)
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetFailed)
 	}
 
-	redisclients.LateInitialize(&cr.Spec.ForProvider, cache)
-	if err := c.kube.Update(ctx, cr); err != nil {
-		return managed.ExternalObservation{}, errors.Wrap(err, errUpdateRedisCRFailed)
-	}
+	li := redisclients.LateInitialize(&cr.Spec.ForProvider, cache)
+	op := cr.Status.AtProvider.LastOperation
 	cr.Status.AtProvider = redisclients.GenerateObservation(cache)
+	cr.Status.AtProvider.LastOperation = op
+	if err := azure.FetchAsyncOperation(ctx, c.sender, &cr.Status.AtProvider.LastOperation); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+	}
 
 	var conn managed.ConnectionDetails
 	switch cr.Status.AtProvider.ProvisioningState {
@@ -129,9 +154,10 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		cr.Status.SetConditions(xpv1.Unavailable())
 	}
 	return managed.ExternalObservation{
-		ResourceExists:    true,
-		ResourceUpToDate:  !redisclients.NeedsUpdate(cr.Spec.ForProvider, cache),
-		ConnectionDetails: conn,
+		ResourceExists:          true,
+		ResourceUpToDate:        !redisclients.NeedsUpdate(cr.Spec.ForProvider, cache),
+		ResourceLateInitialized: li,
+		ConnectionDetails:       conn,
 	}, nil
 }
 
@@ -141,8 +167,14 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotRedis)
 	}
 	cr.Status.SetConditions(xpv1.Creating())
-	_, err := c.client.Create(ctx, cr.Spec.ForProvider.ResourceGroupName, meta.GetExternalName(cr), redisclients.NewCreateParameters(cr))
-	return managed.ExternalCreation{}, errors.Wrap(err, errCreateFailed)
+	op, err := c.client.Create(ctx, cr.Spec.ForProvider.ResourceGroupName, meta.GetExternalName(cr), redisclients.NewCreateParameters(cr))
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateFailed)
+	}
+	cr.Status.AtProvider.LastOperation = azure.NewAsyncOperation(http.MethodPut, op.Future)
+	return managed.ExternalCreation{}, errors.Wrap(
+		azure.FetchAsyncOperation(ctx, c.sender, &cr.Status.AtProvider.LastOperation),
+		errFetchLastOperation)
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
@@ -159,11 +191,12 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 	if err != nil {
 		return managed.ExternalUpdate{}, errors.Wrap(err, errGetFailed)
 	}
-	_, err = c.client.Update(
-		ctx,
-		cr.Spec.ForProvider.ResourceGroupName,
-		meta.GetExternalName(cr),
-		redisclients.NewUpdateParameters(cr.Spec.ForProvider, cache))
+	p := redisclients.NewUpdateParameters(cr.Spec.ForProvider, cache)
+	// Updated tags replace all of the cache's tags, including its owner's. We
+	// always send them, so that caches created before they were owner tagged
+	// are tagged by their next update.
+	p.Tags = azure.AddOwnerTags(azure.ToStringPtrMap(cr.Spec.ForProvider.Tags), cr)
+	_, err = c.client.Update(ctx, cr.Spec.ForProvider.ResourceGroupName, meta.GetExternalName(cr), p)
 	return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateFailed)
 }
 
@@ -176,6 +209,31 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	if cr.Status.AtProvider.ProvisioningState == redisclients.ProvisioningStateDeleting {
 		return nil
 	}
-	_, err := c.client.Delete(ctx, cr.Spec.ForProvider.ResourceGroupName, meta.GetExternalName(cr))
-	return errors.Wrap(resource.Ignore(azure.IsNotFound, err), errDeleteFailed)
+	op, err := c.client.Delete(ctx, cr.Spec.ForProvider.ResourceGroupName, meta.GetExternalName(cr))
+	if err != nil {
+		return errors.Wrap(resource.Ignore(azure.IsNotFound, err), errDeleteFailed)
+	}
+	cr.Status.AtProvider.LastOperation = azure.NewAsyncOperation(http.MethodDelete, op.Future)
+	return errors.Wrap(
+		azure.FetchAsyncOperation(ctx, c.sender, &cr.Status.AtProvider.LastOperation),
+		errFetchLastOperation)
+}
+
+func setDefaults(mg resource.Managed, d *azurev1beta1.ResourceDefaults) {
+	cr, ok := mg.(*v1beta1.Redis)
+	if !ok {
+		return
+	}
+	azure.DefaultLocation(&cr.Spec.ForProvider.Location, d)
+	azure.DefaultResourceGroupName(&cr.Spec.ForProvider.ResourceGroupName, cr.Spec.ForProvider.ResourceGroupNameRef, cr.Spec.ForProvider.ResourceGroupNameSelector, d)
+	azure.MergeTags(&cr.Spec.ForProvider.Tags, d)
+}
+
+// resourcePath returns the path of the Redis's Azure resource.
+func resourcePath(mg resource.Managed) string {
+	cr, ok := mg.(*v1beta1.Redis)
+	if !ok {
+		return ""
+	}
+	return eventgrid.Path(cr.Spec.ForProvider.ResourceGroupName, "Microsoft.Cache/Redis", meta.GetExternalName(cr))
 }
@@ -18,8 +18,10 @@ package cache
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/redis/mgmt/redis/redisapi"
@@ -27,7 +29,7 @@ import (
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	"k8s.io/apimachinery/pkg/types"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
@@ -35,6 +37,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane/provider-azure/apis/cache/v1beta1"
+	azurev1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
 	redisclient "github.com/crossplane/provider-azure/pkg/clients/redis"
 	"github.com/crossplane/provider-azure/pkg/clients/redis/fake"
@@ -45,6 +48,8 @@ const (
 	namespace = "cool-namespace"
 
 	connectionSecretName = "cool-connection-secret"
+
+	inProgressResponse = `{"status": "InProgress"}`
 )
 
 var (
@@ -86,6 +91,14 @@ func withPort(p int) redisResourceModifier {
 	return func(r *v1beta1.Redis) { r.Status.AtProvider.Port = p }
 }
 
+func withUID(uid string) redisResourceModifier {
+	return func(r *v1beta1.Redis) { r.SetUID(types.UID(uid)) }
+}
+
+func withLastOperation(op azurev1alpha3.AsyncOperation) redisResourceModifier {
+	return func(r *v1beta1.Redis) { r.Status.AtProvider.LastOperation = op }
+}
+
 func instance(rm ...redisResourceModifier) *v1beta1.Redis {
 	r := &v1beta1.Redis{
 		Spec: v1beta1.RedisSpec{
@@ -130,9 +143,9 @@ var _ managed.ExternalConnecter = &connector{}
 
 func TestObserve(t *testing.T) {
 	type args struct {
-		cr   *v1beta1.Redis
-		r    redisapi.ClientAPI
-		kube client.Client
+		cr     *v1beta1.Redis
+		r      redisapi.ClientAPI
+		sender autorest.Sender
 	}
 	type want struct {
 		cr  *v1beta1.Redis
@@ -147,9 +160,6 @@ func TestObserve(t *testing.T) {
 		"Successful": {
 			args: args{
 				cr: instance(),
-				kube: &test.MockClient{
-					MockUpdate: test.NewMockUpdateFn(nil),
-				},
 				r: &fake.MockClient{
 					MockGet: func(_ context.Context, resourceGroupName string, name string) (result redis.ResourceType, err error) {
 						return redis.ResourceType{
@@ -185,43 +195,63 @@ func TestObserve(t *testing.T) {
 				},
 			},
 		},
-		"GetFailed": {
+		"NotFound": {
 			args: args{
 				cr: instance(),
 				r: &fake.MockClient{
 					MockGet: func(_ context.Context, resourceGroupName string, name string) (result redis.ResourceType, err error) {
-						return redis.ResourceType{}, errorBoom
+						return redis.ResourceType{}, autorest.DetailedError{StatusCode: http.StatusNotFound}
 					},
 				},
 			},
 			want: want{
-				cr:  instance(),
-				err: errors.Wrap(errorBoom, errGetFailed),
+				cr: instance(),
+				o:  managed.ExternalObservation{ResourceExists: false},
 			},
 		},
-		"KubeUpdateFailed": {
+		"CreationInProgress": {
 			args: args{
-				cr: instance(),
-				kube: &test.MockClient{
-					MockUpdate: test.NewMockUpdateFn(errorBoom),
+				cr: instance(withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodPut, PollingURL: "crossplane.io"})),
+				r: &fake.MockClient{
+					MockGet: func(_ context.Context, resourceGroupName string, name string) (result redis.ResourceType, err error) {
+						return redis.ResourceType{}, autorest.DetailedError{StatusCode: http.StatusNotFound}
+					},
 				},
+				sender: autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						Request:       req,
+						StatusCode:    http.StatusAccepted,
+						Body:          ioutil.NopCloser(strings.NewReader(inProgressResponse)),
+						ContentLength: int64(len(inProgressResponse)),
+					}, nil
+				}),
+			},
+			want: want{
+				cr: instance(withLastOperation(azurev1alpha3.AsyncOperation{
+					Method:     http.MethodPut,
+					PollingURL: "crossplane.io",
+					Status:     azure.AsyncOperationStatusInProgress,
+				})),
+				o: managed.ExternalObservation{ResourceExists: true},
+			},
+		},
+		"GetFailed": {
+			args: args{
+				cr: instance(),
 				r: &fake.MockClient{
 					MockGet: func(_ context.Context, resourceGroupName string, name string) (result redis.ResourceType, err error) {
-						return redis.ResourceType{}, nil
+						return redis.ResourceType{}, errorBoom
 					},
 				},
 			},
 			want: want{
 				cr:  instance(),
-				err: errors.Wrap(errorBoom, errUpdateRedisCRFailed),
+				err: errors.Wrap(errorBoom, errGetFailed),
 			},
 		},
 		"ListAccessKeysFailed": {
 			args: args{
 				cr: instance(),
-				kube: &test.MockClient{
-					MockUpdate: test.NewMockUpdateFn(nil),
-				},
 				r: &fake.MockClient{
 					MockGet: func(_ context.Context, resourceGroupName string, name string) (result redis.ResourceType, err error) {
 						return redis.ResourceType{Properties: &redis.Properties{ProvisioningState: redis.Succeeded}}, nil
@@ -241,9 +271,6 @@ func TestObserve(t *testing.T) {
 		"Creating": {
 			args: args{
 				cr: instance(),
-				kube: &test.MockClient{
-					MockUpdate: test.NewMockUpdateFn(nil),
-				},
 				r: &fake.MockClient{
 					MockGet: func(_ context.Context, resourceGroupName string, name string) (result redis.ResourceType, err error) {
 						return redis.ResourceType{Properties: &redis.Properties{ProvisioningState: redis.Creating}}, nil
@@ -267,9 +294,6 @@ func TestObserve(t *testing.T) {
 		"Deleting": {
 			args: args{
 				cr: instance(),
-				kube: &test.MockClient{
-					MockUpdate: test.NewMockUpdateFn(nil),
-				},
 				r: &fake.MockClient{
 					MockGet: func(_ context.Context, resourceGroupName string, name string) (result redis.ResourceType, err error) {
 						return redis.ResourceType{Properties: &redis.Properties{ProvisioningState: redis.Deleting}}, nil
@@ -293,9 +317,6 @@ func TestObserve(t *testing.T) {
 		"Unavailable": {
 			args: args{
 				cr: instance(),
-				kube: &test.MockClient{
-					MockUpdate: test.NewMockUpdateFn(nil),
-				},
 				r: &fake.MockClient{
 					MockGet: func(_ context.Context, resourceGroupName string, name string) (result redis.ResourceType, err error) {
 						return redis.ResourceType{Properties: &redis.Properties{ProvisioningState: redis.Failed}}, nil
@@ -321,8 +342,8 @@ func TestObserve(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			e := external{
-				kube:   tc.kube,
 				client: tc.r,
+				sender: tc.sender,
 			}
 			o, err := e.Observe(context.Background(), tc.args.cr)
 			if diff := cmp.Diff(tc.want.cr, tc.args.cr); diff != "" {
@@ -364,6 +385,7 @@ func TestCreate(t *testing.T) {
 			want: want{
 				cr: instance(
 					withConditions(xpv1.Creating()),
+					withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodPut}),
 				),
 			},
 		},
@@ -433,6 +455,28 @@ func TestUpdate(t *testing.T) {
 				cr: instance(withProvisioningState(redisclient.ProvisioningStateSucceeded)),
 			},
 		},
+		"OwnerTags": {
+			args: args{
+				cr: instance(withProvisioningState(redisclient.ProvisioningStateSucceeded), withUID("cool-uid")),
+				r: &fake.MockClient{
+					MockGet: func(_ context.Context, _ string, _ string) (result redis.ResourceType, err error) {
+						return redis.ResourceType{Tags: azure.ToStringPtrMap(map[string]string{"key1": "val1"}), Properties: &redis.Properties{}}, nil
+					},
+					MockUpdate: func(_ context.Context, _ string, _ string, parameters redis.UpdateParameters) (result redis.ResourceType, err error) {
+						// The cache's user tags are unchanged, but it
+						// predates owner tags.
+						want := azure.AddOwnerTags(azure.ToStringPtrMap(map[string]string{"key1": "val1"}), instance(withUID("cool-uid")))
+						if diff := cmp.Diff(want, parameters.Tags); diff != "" {
+							t.Errorf("Update(...): -want tags, +got tags\n%s", diff)
+						}
+						return redis.ResourceType{}, nil
+					},
+				},
+			},
+			want: want{
+				cr: instance(withProvisioningState(redisclient.ProvisioningStateSucceeded), withUID("cool-uid")),
+			},
+		},
 		"NotReady": {
 			args: args{
 				cr: instance(withProvisioningState(redisclient.ProvisioningStateFailed)),
@@ -517,6 +561,7 @@ func TestDelete(t *testing.T) {
 			want: want{
 				cr: instance(
 					withConditions(xpv1.Deleting()),
+					withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodDelete}),
 				),
 			},
 		},
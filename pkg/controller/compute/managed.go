@@ -27,6 +27,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
@@ -38,37 +39,52 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-azure/apis/compute/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1beta1"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/azureerrors"
 	"github.com/crossplane/provider-azure/pkg/clients/compute"
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/clients/whatif"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
 )
 
 // Error strings.
 const (
-	errGenPassword      = "cannot generate service principal secret"
-	errNotAKSCluster    = "managed resource is not a AKSCluster"
-	errCreateAKSCluster = "cannot create AKSCluster"
-	errGetAKSCluster    = "cannot get AKSCluster"
-	errGetKubeConfig    = "cannot get AKSCluster kubeconfig"
-	errDeleteAKSCluster = "cannot delete AKSCluster"
+	errGenPassword        = "cannot generate service principal secret"
+	errNotAKSCluster      = "managed resource is not a AKSCluster"
+	errCreateAKSCluster   = "cannot create AKSCluster"
+	errGetAKSCluster      = "cannot get AKSCluster"
+	errGetKubeConfig      = "cannot get AKSCluster kubeconfig"
+	errDeleteAKSCluster   = "cannot delete AKSCluster"
+	errFetchLastOperation = "cannot fetch last operation"
 )
 
 // SetupAKSCluster adds a controller that reconciles AKSClusters.
-func SetupAKSCluster(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+func SetupAKSCluster(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
 	name := managed.ControllerName(v1alpha3.AKSClusterGroupKind)
 
+	events, err := eventgrid.NewSource(mgr, &v1alpha3.AKSCluster{}, resourcePath)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(controller.Options{
-			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
 		}).
 		For(&v1alpha3.AKSCluster{}).
-		Complete(managed.NewReconciler(mgr,
+		Watches(events, &handler.EnqueueRequestForObject{}).
+		Complete(tracing.NewReconciler(name, managed.NewReconciler(mgr,
 			resource.ManagedKind(v1alpha3.AKSClusterGroupVersionKind),
-			managed.WithExternalConnecter(&connecter{client: mgr.GetClient()}),
+			managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), azure.NewDefaultsInitializer(mgr.GetClient(), setDefaults)),
+			managed.WithExternalConnecter(tracing.NewConnecter(name, azureerrors.NewConnecter(policy.NewConnecter(whatif.NewConnecter(&connecter{client: mgr.GetClient()}, event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))),
 			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
 			managed.WithPollInterval(poll),
 			managed.WithLogger(l.WithValues("controller", name)),
-			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
+			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))
 }
 
 type connecter struct {
@@ -80,10 +96,11 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl, err := compute.NewAggregateClient(creds, auth)
+	graph, err := azure.GetAuthorizer(ctx, c.client, mg, creds[azure.CredentialsKeyActiveDirectoryGraphResourceID])
 	if err != nil {
 		return nil, err
 	}
+	cl := compute.NewAggregateClient(creds, auth, graph)
 	return &external{kube: c.client, client: cl, newPasswordFn: password.Generate}, nil
 }
 
@@ -101,7 +118,10 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 
 	c, err := e.client.GetManagedCluster(ctx, cr)
 	if azure.IsNotFound(err) {
-		return managed.ExternalObservation{ResourceExists: false}, nil
+		if err := azure.FetchAsyncOperation(ctx, e.client.GetRESTClient(), &cr.Status.LastOperation); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+		}
+		return managed.ExternalObservation{ResourceExists: azure.IsCreating(cr.Status.LastOperation)}, nil
 	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetAKSCluster)
@@ -110,6 +130,9 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	cr.Status.ProviderID = to.String(c.ID)
 	cr.Status.State = to.String(c.ProvisioningState)
 	cr.Status.Endpoint = to.String(c.Fqdn)
+	if err := azure.FetchAsyncOperation(ctx, e.client.GetRESTClient(), &cr.Status.LastOperation); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+	}
 
 	if cr.Status.State != "Succeeded" {
 		// AKS clusters are always up to date because we can't yet update them.
@@ -147,7 +170,12 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	if err != nil {
 		return managed.ExternalCreation{}, errors.Wrap(err, errGenPassword)
 	}
-	return managed.ExternalCreation{}, errors.Wrap(e.client.EnsureManagedCluster(ctx, cr, secret), errCreateAKSCluster)
+	if err := e.client.EnsureManagedCluster(ctx, cr, secret); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateAKSCluster)
+	}
+	return managed.ExternalCreation{}, errors.Wrap(
+		azure.FetchAsyncOperation(ctx, e.client.GetRESTClient(), &cr.Status.LastOperation),
+		errFetchLastOperation)
 }
 
 func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
@@ -161,7 +189,12 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotAKSCluster)
 	}
 	cr.SetConditions(xpv1.Deleting())
-	return errors.Wrap(e.client.DeleteManagedCluster(ctx, cr), errDeleteAKSCluster)
+	if err := e.client.DeleteManagedCluster(ctx, cr); err != nil {
+		return errors.Wrap(err, errDeleteAKSCluster)
+	}
+	return errors.Wrap(
+		azure.FetchAsyncOperation(ctx, e.client.GetRESTClient(), &cr.Status.LastOperation),
+		errFetchLastOperation)
 }
 
 func connectionDetails(kubeconfig []byte, name string) (managed.ConnectionDetails, error) {
@@ -191,3 +224,21 @@ func connectionDetails(kubeconfig []byte, name string) (managed.ConnectionDetail
 		xpv1.ResourceCredentialsSecretKubeconfigKey: kubeconfig,
 	}, nil
 }
+
+func setDefaults(mg resource.Managed, d *v1beta1.ResourceDefaults) {
+	cr, ok := mg.(*v1alpha3.AKSCluster)
+	if !ok {
+		return
+	}
+	azure.DefaultLocation(&cr.Spec.Location, d)
+	azure.DefaultResourceGroupName(&cr.Spec.ResourceGroupName, cr.Spec.ResourceGroupNameRef, cr.Spec.ResourceGroupNameSelector, d)
+}
+
+// resourcePath returns the path of the AKSCluster's Azure resource.
+func resourcePath(mg resource.Managed) string {
+	cr, ok := mg.(*v1alpha3.AKSCluster)
+	if !ok {
+		return ""
+	}
+	return eventgrid.Path(cr.Spec.ResourceGroupName, "Microsoft.ContainerService/managedClusters", meta.GetExternalName(cr))
+}
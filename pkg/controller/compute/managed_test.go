@@ -18,7 +18,9 @@ package compute
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2018-03-31/containerservice"
@@ -32,6 +34,8 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane/provider-azure/apis/compute/v1alpha3"
+	azurev1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
 	"github.com/crossplane/provider-azure/pkg/clients/compute/fake"
 )
 
@@ -55,6 +59,12 @@ func withEndpoint(ep string) modifier {
 	}
 }
 
+func withLastOperation(op azurev1alpha3.AsyncOperation) modifier {
+	return func(c *v1alpha3.AKSCluster) {
+		c.Status.LastOperation = op
+	}
+}
+
 func aksCluster(m ...modifier) *v1alpha3.AKSCluster {
 	ac := &v1alpha3.AKSCluster{}
 
@@ -71,6 +81,7 @@ func TestObserve(t *testing.T) {
 	stateSucceeded := "Succeeded"
 	stateWat := "Wat"
 	endpoint := "http://wat.example.org"
+	inProgressResponse := `{"status": "InProgress"}`
 
 	type args struct {
 		ctx context.Context
@@ -102,6 +113,7 @@ func TestObserve(t *testing.T) {
 					MockGetManagedCluster: func(_ context.Context, _ *v1alpha3.AKSCluster) (containerservice.ManagedCluster, error) {
 						return containerservice.ManagedCluster{}, autorest.DetailedError{StatusCode: http.StatusNotFound}
 					},
+					MockGetRESTClient: func() autorest.Sender { return nil },
 				},
 			},
 			args: args{
@@ -113,6 +125,37 @@ func TestObserve(t *testing.T) {
 				mg: aksCluster(),
 			},
 		},
+		"CreationInProgress": {
+			e: &external{
+				client: fake.AKSClient{
+					MockGetManagedCluster: func(_ context.Context, _ *v1alpha3.AKSCluster) (containerservice.ManagedCluster, error) {
+						return containerservice.ManagedCluster{}, autorest.DetailedError{StatusCode: http.StatusNotFound}
+					},
+					MockGetRESTClient: func() autorest.Sender {
+						return autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+							return &http.Response{
+								Request:       req,
+								StatusCode:    http.StatusAccepted,
+								Body:          ioutil.NopCloser(strings.NewReader(inProgressResponse)),
+								ContentLength: int64(len(inProgressResponse)),
+							}, nil
+						})
+					},
+				},
+			},
+			args: args{
+				ctx: context.Background(),
+				mg:  aksCluster(withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodPut, PollingURL: "crossplane.io"})),
+			},
+			want: want{
+				eo: managed.ExternalObservation{ResourceExists: true},
+				mg: aksCluster(withLastOperation(azurev1alpha3.AsyncOperation{
+					Method:     http.MethodPut,
+					PollingURL: "crossplane.io",
+					Status:     azure.AsyncOperationStatusInProgress,
+				})),
+			},
+		},
 		"ErrGetCluster": {
 			e: &external{
 				client: fake.AKSClient{
@@ -142,6 +185,7 @@ func TestObserve(t *testing.T) {
 							},
 						}, nil
 					},
+					MockGetRESTClient: func() autorest.Sender { return nil },
 				},
 			},
 			args: args{
@@ -168,6 +212,7 @@ func TestObserve(t *testing.T) {
 					MockGetKubeConfig: func(_ context.Context, _ *v1alpha3.AKSCluster) ([]byte, error) {
 						return nil, errBoom
 					},
+					MockGetRESTClient: func() autorest.Sender { return nil },
 				},
 			},
 			args: args{
@@ -0,0 +1,235 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/apis/v1beta1"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+)
+
+const (
+	healthTimeout = 1 * time.Minute
+
+	// defaultHealthInterval is how often credentials are validated by
+	// default. Credentials rarely change, and each validation acquires a
+	// token, so they are validated much less often than resources are polled.
+	defaultHealthInterval = 10 * time.Minute
+
+	errGetPC       = "cannot get ProviderConfig"
+	errPatchStatus = "cannot patch ProviderConfig status"
+	errValidate    = "cannot validate credentials"
+	errExpiredFmt  = "credentials expired at %s"
+
+	reasonValidate event.Reason = "ValidateCredentials"
+)
+
+// A Validator validates the credentials of a ProviderConfig.
+type Validator interface {
+	// Validate returns the time at which the credentials of the supplied
+	// ProviderConfig expire, if known, or an error if they are invalid.
+	Validate(ctx context.Context, pc *v1beta1.ProviderConfig) (*time.Time, error)
+}
+
+// A ValidatorFn is a function that satisfies the Validator interface.
+type ValidatorFn func(ctx context.Context, pc *v1beta1.ProviderConfig) (*time.Time, error)
+
+// Validate the credentials of the supplied ProviderConfig.
+func (fn ValidatorFn) Validate(ctx context.Context, pc *v1beta1.ProviderConfig) (*time.Time, error) {
+	return fn(ctx, pc)
+}
+
+// An AzureValidator validates the credentials of a ProviderConfig by using
+// them to acquire a token and read its subscription.
+type AzureValidator struct {
+	client client.Client
+}
+
+// Validate the credentials of the supplied ProviderConfig.
+func (v *AzureValidator) Validate(ctx context.Context, pc *v1beta1.ProviderConfig) (*time.Time, error) {
+	e, err := azure.ProviderConfigAuthorizers(ctx, v.client, pc)
+	if err != nil {
+		return nil, err
+	}
+	creds := e.Credentials()
+	a, err := e.ResourceManagerAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	if err := azure.ValidateAuthorizer(ctx, creds[azure.CredentialsKeyResourceManagerEndpointURL], creds[azure.CredentialsKeySubscriptionID], a); err != nil {
+		return nil, err
+	}
+
+	// The expiry of the credentials is informational, and often can't be
+	// determined because service principals are rarely allowed to read their
+	// own application. We don't consider this an error.
+	graph, err := e.Authorizer(creds[azure.CredentialsKeyActiveDirectoryGraphResourceID])
+	if err != nil {
+		return nil, nil
+	}
+	exp, _ := azure.CredentialsExpiry(ctx, creds, graph)
+	return exp, nil
+}
+
+// SetupHealth adds a controller that validates the credentials of
+// ProviderConfigs at the supplied interval, and reports whether they are ready
+// for use.
+func SetupHealth(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+	name := "providerconfighealth/" + strings.ToLower(v1beta1.ProviderConfigGroupKind)
+
+	r := NewHealthReconciler(mgr,
+		WithLogger(l.WithValues("controller", name)),
+		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithPollInterval(poll))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(controller.Options{
+			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+		}).
+		// Status updates must not trigger validation, or we'd validate
+		// continuously. We validate again after the poll interval instead.
+		For(&v1beta1.ProviderConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(tracing.NewReconciler(name, r))
+}
+
+// A HealthReconciler validates the credentials of ProviderConfigs.
+type HealthReconciler struct {
+	client    client.Client
+	validator Validator
+	log       logging.Logger
+	record    event.Recorder
+	poll      time.Duration
+}
+
+// A HealthReconcilerOption configures a HealthReconciler.
+type HealthReconcilerOption func(*HealthReconciler)
+
+// WithLogger specifies how the HealthReconciler should log messages.
+func WithLogger(l logging.Logger) HealthReconcilerOption {
+	return func(r *HealthReconciler) {
+		r.log = l
+	}
+}
+
+// WithRecorder specifies how the HealthReconciler should record events.
+func WithRecorder(er event.Recorder) HealthReconcilerOption {
+	return func(r *HealthReconciler) {
+		r.record = er
+	}
+}
+
+// WithPollInterval specifies how often the HealthReconciler should validate
+// credentials.
+func WithPollInterval(after time.Duration) HealthReconcilerOption {
+	return func(r *HealthReconciler) {
+		r.poll = after
+	}
+}
+
+// WithValidator specifies how the HealthReconciler should validate
+// credentials.
+func WithValidator(v Validator) HealthReconcilerOption {
+	return func(r *HealthReconciler) {
+		r.validator = v
+	}
+}
+
+// NewHealthReconciler returns a HealthReconciler that validates the
+// credentials of ProviderConfigs.
+func NewHealthReconciler(m ctrl.Manager, o ...HealthReconcilerOption) *HealthReconciler {
+	r := &HealthReconciler{
+		client:    m.GetClient(),
+		validator: &AzureValidator{client: m.GetClient()},
+		log:       logging.NewNopLogger(),
+		record:    event.NewNopRecorder(),
+		poll:      defaultHealthInterval,
+	}
+	for _, ro := range o {
+		ro(r)
+	}
+	return r
+}
+
+// Reconcile a ProviderConfig by validating its credentials.
+func (r *HealthReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	pc := &v1beta1.ProviderConfig{}
+	if err := r.client.Get(ctx, req.NamespacedName, pc); err != nil {
+		log.Debug(errGetPC, "error", err)
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPC)
+	}
+	if meta.WasDeleted(pc) {
+		return reconcile.Result{Requeue: false}, nil
+	}
+
+	// The usage controller also updates the status of ProviderConfigs. We
+	// patch only the fields we set, so that we don't conflict with it.
+	patch := client.MergeFrom(pc.DeepCopy())
+
+	exp, err := r.validator.Validate(ctx, pc)
+	pc.Status.CredentialsExpiry = nil
+	if exp != nil {
+		pc.Status.CredentialsExpiry = &metav1.Time{Time: *exp}
+	}
+
+	switch {
+	case err == nil && exp != nil && exp.Before(time.Now()):
+		err = errors.Errorf(errExpiredFmt, exp.Format(time.RFC3339))
+		pc.SetConditions(v1beta1.Expired().WithMessage(err.Error()))
+	case err == nil:
+		pc.SetConditions(xpv1.Available())
+	case azure.IsCredentialsExpired(err):
+		pc.SetConditions(v1beta1.Expired().WithMessage(err.Error()))
+	case azure.IsUnauthorized(err):
+		pc.SetConditions(v1beta1.Unauthorized().WithMessage(err.Error()))
+	default:
+		pc.SetConditions(xpv1.Unavailable().WithMessage(err.Error()))
+	}
+	if err != nil {
+		log.Debug(errValidate, "error", err)
+		r.record.Event(pc, event.Warning(reasonValidate, errors.Wrap(err, errValidate)))
+	}
+
+	return reconcile.Result{RequeueAfter: r.poll}, errors.Wrap(r.client.Status().Patch(ctx, pc, patch), errPatchStatus)
+}
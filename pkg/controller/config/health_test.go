@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-azure/apis/v1beta1"
+)
+
+func TestHealthReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+	poll := 5 * time.Minute
+	future := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	past := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+	errUnauthorized := autorest.DetailedError{StatusCode: http.StatusUnauthorized, Original: errors.New("AADSTS7000215: Invalid client secret provided.")}
+	errExpired := autorest.DetailedError{StatusCode: http.StatusUnauthorized, Original: errors.New("AADSTS7000222: The provided client secret keys are expired.")}
+
+	// statusIs returns a MockStatusPatchFn that verifies the status of the
+	// supplied ProviderConfig matches the one that is patched, and that the
+	// patch is a merge patch that doesn't touch the rest of the status.
+	statusIs := func(t *testing.T, want v1beta1.ProviderConfigStatus) test.MockStatusPatchFn {
+		return func(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+			pc := obj.(*v1beta1.ProviderConfig)
+			if diff := cmp.Diff(want, pc.Status, test.EquateConditions(), cmpopts.IgnoreFields(xpv1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("Status().Patch(...): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(types.MergePatchType, patch.Type()); diff != "" {
+				t.Errorf("Status().Patch(...): -want patch type, +got patch type:\n%s", diff)
+			}
+			b, err := patch.Data(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(b), "users") || strings.Contains(string(b), "resourceVersion") {
+				t.Errorf("Status().Patch(...): patch %s touches fields it should not", b)
+			}
+			return nil
+		}
+	}
+	status := func(c xpv1.Condition, exp *time.Time) v1beta1.ProviderConfigStatus {
+		s := v1beta1.ProviderConfigStatus{}
+		s.SetConditions(c)
+		if exp != nil {
+			s.CredentialsExpiry = &metav1.Time{Time: *exp}
+		}
+		return s
+	}
+
+	type args struct {
+		kube      func(t *testing.T) client.Client
+		validator Validator
+	}
+	type want struct {
+		result reconcile.Result
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotFound": {
+			reason: "We should not return an error if the ProviderConfig was deleted.",
+			args: args{
+				kube: func(_ *testing.T) client.Client {
+					return &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, ""))}
+				},
+			},
+			want: want{result: reconcile.Result{}},
+		},
+		"GetError": {
+			reason: "Errors getting the ProviderConfig should be returned.",
+			args: args{
+				kube: func(_ *testing.T) client.Client {
+					return &test.MockClient{MockGet: test.NewMockGetFn(errBoom)}
+				},
+			},
+			want: want{err: errors.Wrap(errBoom, errGetPC)},
+		},
+		"Available": {
+			reason: "Valid credentials should be reported as available, along with their expiry.",
+			args: args{
+				kube: func(t *testing.T) client.Client {
+					return &test.MockClient{
+						MockGet:         test.NewMockGetFn(nil),
+						MockStatusPatch: statusIs(t, status(xpv1.Available(), &future)),
+					}
+				},
+				validator: ValidatorFn(func(_ context.Context, _ *v1beta1.ProviderConfig) (*time.Time, error) { return &future, nil }),
+			},
+			want: want{result: reconcile.Result{RequeueAfter: poll}},
+		},
+		"ExpiredByDate": {
+			reason: "Credentials whose expiry has passed should be reported as expired.",
+			args: args{
+				kube: func(t *testing.T) client.Client {
+					return &test.MockClient{
+						MockGet:         test.NewMockGetFn(nil),
+						MockStatusPatch: statusIs(t, status(v1beta1.Expired().WithMessage(errors.Errorf(errExpiredFmt, past.Format(time.RFC3339)).Error()), &past)),
+					}
+				},
+				validator: ValidatorFn(func(_ context.Context, _ *v1beta1.ProviderConfig) (*time.Time, error) { return &past, nil }),
+			},
+			want: want{result: reconcile.Result{RequeueAfter: poll}},
+		},
+		"ExpiredByAzureAD": {
+			reason: "Credentials Azure AD reports as expired should be reported as expired.",
+			args: args{
+				kube: func(t *testing.T) client.Client {
+					return &test.MockClient{
+						MockGet:         test.NewMockGetFn(nil),
+						MockStatusPatch: statusIs(t, status(v1beta1.Expired().WithMessage(errExpired.Error()), nil)),
+					}
+				},
+				validator: ValidatorFn(func(_ context.Context, _ *v1beta1.ProviderConfig) (*time.Time, error) { return nil, errExpired }),
+			},
+			want: want{result: reconcile.Result{RequeueAfter: poll}},
+		},
+		"Unauthorized": {
+			reason: "Credentials Azure rejects should be reported as unauthorized.",
+			args: args{
+				kube: func(t *testing.T) client.Client {
+					return &test.MockClient{
+						MockGet:         test.NewMockGetFn(nil),
+						MockStatusPatch: statusIs(t, status(v1beta1.Unauthorized().WithMessage(errUnauthorized.Error()), nil)),
+					}
+				},
+				validator: ValidatorFn(func(_ context.Context, _ *v1beta1.ProviderConfig) (*time.Time, error) { return nil, errUnauthorized }),
+			},
+			want: want{result: reconcile.Result{RequeueAfter: poll}},
+		},
+		"Unavailable": {
+			reason: "Credentials that cannot be validated for other reasons should be reported as unavailable.",
+			args: args{
+				kube: func(t *testing.T) client.Client {
+					return &test.MockClient{
+						MockGet:         test.NewMockGetFn(nil),
+						MockStatusPatch: statusIs(t, status(xpv1.Unavailable().WithMessage(errBoom.Error()), nil)),
+					}
+				},
+				validator: ValidatorFn(func(_ context.Context, _ *v1beta1.ProviderConfig) (*time.Time, error) { return nil, errBoom }),
+			},
+			want: want{result: reconcile.Result{RequeueAfter: poll}},
+		},
+		"PatchStatusError": {
+			reason: "Errors patching the ProviderConfig's status should be returned.",
+			args: args{
+				kube: func(_ *testing.T) client.Client {
+					return &test.MockClient{
+						MockGet:         test.NewMockGetFn(nil),
+						MockStatusPatch: test.NewMockStatusPatchFn(errBoom),
+					}
+				},
+				validator: ValidatorFn(func(_ context.Context, _ *v1beta1.ProviderConfig) (*time.Time, error) { return nil, nil }),
+			},
+			want: want{result: reconcile.Result{RequeueAfter: poll}, err: errors.Wrap(errBoom, errPatchStatus)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewHealthReconciler(&fake.Manager{Client: tc.args.kube(t)},
+				WithValidator(tc.args.validator),
+				WithPollInterval(poll))
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -22,11 +22,13 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/cosmos-db/mgmt/2015-04-08/documentdb"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/pkg/errors"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
@@ -37,8 +39,14 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-azure/apis/database/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1beta1"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/azureerrors"
 	"github.com/crossplane/provider-azure/pkg/clients/database/cosmosdb"
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/clients/whatif"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
 )
 
 // Error strings
@@ -47,26 +55,35 @@ const (
 	errCreateNoSQLAccount = "cannot create Database Account"
 	errGetNoSQLAccount    = "cannot get Database Account"
 	errDeleteNoSQLAccount = "cannot delete Database Account"
+	errFetchLastOperation = "cannot fetch last operation"
 )
 
 // Setup adds a controller that reconciles NoSQLAccount.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
 	name := managed.ControllerName(v1alpha3.CosmosDBAccountGroupKind)
 
+	events, err := eventgrid.NewSource(mgr, &v1alpha3.CosmosDBAccount{}, resourcePath)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(controller.Options{
-			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
 		}).
 		For(&v1alpha3.CosmosDBAccount{}).
-		Complete(managed.NewReconciler(mgr,
+		Watches(events, &handler.EnqueueRequestForObject{}).
+		Complete(tracing.NewReconciler(name, managed.NewReconciler(mgr,
 			resource.ManagedKind(v1alpha3.CosmosDBAccountGroupVersionKind),
 			managed.WithConnectionPublishers(),
-			managed.WithExternalConnecter(&connecter{kube: mgr.GetClient()}),
+			managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), azure.NewDefaultsInitializer(mgr.GetClient(), setDefaults)),
+			managed.WithExternalConnecter(tracing.NewConnecter(name, azureerrors.NewConnecter(policy.NewConnecter(whatif.NewConnecter(&connecter{kube: mgr.GetClient()}, event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))),
 			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
 			managed.WithPollInterval(poll),
 			managed.WithLogger(l.WithValues("controller", name)),
-			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
+			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))
 }
 
 type connecter struct {
@@ -78,15 +95,16 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := documentdb.NewDatabaseAccountsClient(creds[azure.CredentialsKeySubscriptionID])
-	cl.Authorizer = auth
-	return &external{kube: c.kube, client: cl}, nil
+	cl := documentdb.NewDatabaseAccountsClientWithBaseURI(creds[azure.CredentialsKeyResourceManagerEndpointURL], creds[azure.CredentialsKeySubscriptionID])
+	azure.ConfigureClient(&cl.Client, auth)
+	return &external{kube: c.kube, client: cl, sender: cl.Client}, nil
 }
 
 // external is a createsyncdeleter using the Azure API.
 type external struct {
 	kube   client.Client
 	client cosmosdb.AccountClient
+	sender autorest.Sender
 }
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -97,7 +115,10 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 
 	res, err := e.client.CheckNameExists(ctx, meta.GetExternalName(r))
 	if res.IsHTTPStatus(http.StatusNotFound) {
-		return managed.ExternalObservation{ResourceExists: false}, nil
+		if err := azure.FetchAsyncOperation(ctx, e.sender, &r.Status.LastOperation); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+		}
+		return managed.ExternalObservation{ResourceExists: azure.IsCreating(r.Status.LastOperation)}, nil
 	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetNoSQLAccount)
@@ -108,6 +129,9 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetNoSQLAccount)
 	}
 	cosmosdb.UpdateCosmosDBAccountObservation(&r.Status, account)
+	if err := azure.FetchAsyncOperation(ctx, e.sender, &r.Status.LastOperation); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+	}
 
 	switch r.Status.AtProvider.State {
 	case "Succeeded":
@@ -115,7 +139,8 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	default:
 		r.SetConditions(xpv1.Unavailable())
 	}
-	resourceUpToDate := cosmosdb.CheckEqualDatabaseProperties(r.Spec.ForProvider.Properties, account)
+	resourceUpToDate := cosmosdb.CheckEqualDatabaseProperties(r.Spec.ForProvider.Properties, account) &&
+		cosmosdb.CheckEqualTags(r.Spec.ForProvider.Tags, account)
 	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: resourceUpToDate}, nil
 }
 
@@ -126,15 +151,30 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	}
 
 	r.Status.SetConditions(xpv1.Creating())
-	_, err := e.client.CreateOrUpdate(ctx,
+	p := cosmosdb.ToDatabaseAccountCreateOrUpdate(&r.Spec)
+	p.Tags = azure.AddOwnerTags(p.Tags, r)
+	op, err := e.client.CreateOrUpdate(ctx,
 		r.Spec.ForProvider.ResourceGroupName,
 		meta.GetExternalName(r),
-		cosmosdb.ToDatabaseAccountCreateOrUpdate(&r.Spec))
+		p)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateNoSQLAccount)
+	}
+	r.Status.LastOperation = azure.NewAsyncOperation(http.MethodPut, op.Future)
 	// TODO(artursouza): handle secrets.
-	return managed.ExternalCreation{}, errors.Wrap(err, errCreateNoSQLAccount)
+	return managed.ExternalCreation{}, errors.Wrap(
+		azure.FetchAsyncOperation(ctx, e.sender, &r.Status.LastOperation),
+		errFetchLastOperation)
 }
 
 func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	r, ok := mg.(*v1alpha3.CosmosDBAccount)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotNoSQLAccount)
+	}
+	if r.Status.LastOperation.Status == azure.AsyncOperationStatusInProgress {
+		return managed.ExternalUpdate{}, nil
+	}
 	_, err := e.Create(ctx, mg)
 	return managed.ExternalUpdate{}, err
 }
@@ -146,6 +186,31 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 	}
 
 	r.Status.SetConditions(xpv1.Deleting())
-	_, err := e.client.Delete(ctx, r.Spec.ForProvider.ResourceGroupName, meta.GetExternalName(r))
-	return errors.Wrap(err, errDeleteNoSQLAccount)
+	op, err := e.client.Delete(ctx, r.Spec.ForProvider.ResourceGroupName, meta.GetExternalName(r))
+	if err != nil {
+		return errors.Wrap(err, errDeleteNoSQLAccount)
+	}
+	r.Status.LastOperation = azure.NewAsyncOperation(http.MethodDelete, op.Future)
+	return errors.Wrap(
+		azure.FetchAsyncOperation(ctx, e.sender, &r.Status.LastOperation),
+		errFetchLastOperation)
+}
+
+func setDefaults(mg resource.Managed, d *v1beta1.ResourceDefaults) {
+	cr, ok := mg.(*v1alpha3.CosmosDBAccount)
+	if !ok {
+		return
+	}
+	azure.DefaultLocation(&cr.Spec.ForProvider.Location, d)
+	azure.DefaultResourceGroupName(&cr.Spec.ForProvider.ResourceGroupName, cr.Spec.ForProvider.ResourceGroupNameRef, cr.Spec.ForProvider.ResourceGroupNameSelector, d)
+	azure.MergeTags(&cr.Spec.ForProvider.Tags, d)
+}
+
+// resourcePath returns the path of the CosmosDBAccount's Azure resource.
+func resourcePath(mg resource.Managed) string {
+	cr, ok := mg.(*v1alpha3.CosmosDBAccount)
+	if !ok {
+		return ""
+	}
+	return eventgrid.Path(cr.Spec.ForProvider.ResourceGroupName, "Microsoft.DocumentDB/databaseAccounts", meta.GetExternalName(cr))
 }
@@ -18,7 +18,9 @@ package cosmosdb
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/services/cosmos-db/mgmt/2015-04-08/documentdb"
@@ -36,6 +38,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane/provider-azure/apis/database/v1alpha3"
+	azurev1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
 	cosmosdbclient "github.com/crossplane/provider-azure/pkg/clients/database/cosmosdb"
 )
@@ -49,6 +52,8 @@ const (
 	kind              = "mongodb"
 
 	stateSucceeded = "Succeeded"
+
+	inProgressResponse = `{"status": "InProgress"}`
 )
 
 type cosmosDBAccountModifier func(*v1alpha3.CosmosDBAccount)
@@ -87,6 +92,10 @@ func withConditions(c ...xpv1.Condition) cosmosDBAccountModifier {
 	return func(r *v1alpha3.CosmosDBAccount) { r.Status.ConditionedStatus.Conditions = c }
 }
 
+func withLastOperation(op azurev1alpha3.AsyncOperation) cosmosDBAccountModifier {
+	return func(r *v1alpha3.CosmosDBAccount) { r.Status.LastOperation = op }
+}
+
 func cosmosDBAccount(rm ...cosmosDBAccountModifier) *v1alpha3.CosmosDBAccount {
 	r := &v1alpha3.CosmosDBAccount{
 		ObjectMeta: metav1.ObjectMeta{
@@ -198,6 +207,36 @@ func TestObserve(t *testing.T) {
 				mg: cosmosDBAccount(),
 			},
 		},
+		"CreationInProgress": {
+			e: &external{
+				kube: mockKube,
+				client: &MockClient{
+					MockCheckNameExists: func(_ context.Context, _ string) (result autorest.Response, err error) {
+						return autorest.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}, nil
+					},
+				},
+				sender: autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						Request:       req,
+						StatusCode:    http.StatusAccepted,
+						Body:          ioutil.NopCloser(strings.NewReader(inProgressResponse)),
+						ContentLength: int64(len(inProgressResponse)),
+					}, nil
+				}),
+			},
+			args: args{
+				ctx: context.Background(),
+				mg:  cosmosDBAccount(withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodPut, PollingURL: "crossplane.io"})),
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true},
+				mg: cosmosDBAccount(withLastOperation(azurev1alpha3.AsyncOperation{
+					Method:     http.MethodPut,
+					PollingURL: "crossplane.io",
+					Status:     azure.AsyncOperationStatusInProgress,
+				})),
+			},
+		},
 		"Success": {
 			e: &external{
 				kube: mockKube,
@@ -28,6 +28,7 @@ import (
 	"github.com/pkg/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
@@ -39,13 +40,18 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-azure/apis/database/v1beta1"
+	azurev1beta1 "github.com/crossplane/provider-azure/apis/v1beta1"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/azureerrors"
 	"github.com/crossplane/provider-azure/pkg/clients/database"
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/clients/whatif"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
 )
 
 // Error strings.
 const (
-	errUpdateCR           = "cannot update MySQLServer custom resource"
 	errGenPassword        = "cannot generate admin password"
 	errNotMySQLServer     = "managed resource is not a MySQLServer"
 	errCreateMySQLServer  = "cannot create MySQLServer"
@@ -56,22 +62,30 @@ const (
 )
 
 // Setup adds a controller that reconciles MySQLServers.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
 	name := managed.ControllerName(v1beta1.MySQLServerGroupKind)
 
+	events, err := eventgrid.NewSource(mgr, &v1beta1.MySQLServer{}, resourcePath)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(controller.Options{
-			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
 		}).
 		For(&v1beta1.MySQLServer{}).
-		Complete(managed.NewReconciler(mgr,
+		Watches(events, &handler.EnqueueRequestForObject{}).
+		Complete(tracing.NewReconciler(name, managed.NewReconciler(mgr,
 			resource.ManagedKind(v1beta1.MySQLServerGroupVersionKind),
-			managed.WithExternalConnecter(&connecter{client: mgr.GetClient()}),
+			managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), azure.NewDefaultsInitializer(mgr.GetClient(), setDefaults)),
+			managed.WithExternalConnecter(tracing.NewConnecter(name, azureerrors.NewConnecter(policy.NewConnecter(whatif.NewConnecter(&connecter{client: mgr.GetClient()}, event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))),
 			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
 			managed.WithPollInterval(poll),
 			managed.WithLogger(l.WithValues("controller", name)),
-			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
+			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))
 }
 
 type connecter struct {
@@ -83,13 +97,12 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := mysql.NewServersClient(creds[azure.CredentialsKeySubscriptionID])
-	cl.Authorizer = auth
-	return &external{kube: c.client, client: database.NewMySQLServerClient(cl), newPasswordFn: password.Generate}, nil
+	cl := mysql.NewServersClientWithBaseURI(creds[azure.CredentialsKeyResourceManagerEndpointURL], creds[azure.CredentialsKeySubscriptionID])
+	azure.ConfigureClient(&cl.Client, auth)
+	return &external{client: database.NewMySQLServerClient(cl), newPasswordFn: password.Generate}, nil
 }
 
 type external struct {
-	kube          client.Client
 	client        database.MySQLServerAPI
 	newPasswordFn func() (password string, err error)
 }
@@ -109,22 +122,13 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		// successfully and we cannot return `ResourceExists: false` during creation
 		// since this will cause `Create` to be called again and it's not idempotent.
 		// So, we check whether a creation operation in fact is in motion.
-		creating := cr.Status.AtProvider.LastOperation.Method == "PUT" &&
-			cr.Status.AtProvider.LastOperation.Status == azure.AsyncOperationStatusInProgress
-		return managed.ExternalObservation{ResourceExists: creating}, nil
+		return managed.ExternalObservation{ResourceExists: azure.IsCreating(cr.Status.AtProvider.LastOperation)}, nil
 	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetMySQLServer)
 	}
-	database.LateInitializeMySQL(&cr.Spec.ForProvider, server)
-	if err := e.kube.Update(ctx, cr); err != nil {
-		return managed.ExternalObservation{}, errors.Wrap(err, errUpdateCR)
-	}
+	li := database.LateInitializeMySQL(&cr.Spec.ForProvider, server)
 	database.UpdateMySQLObservation(&cr.Status.AtProvider, server)
-	// We make this call after kube.Update since it doesn't update the
-	// status subresource but fetches the the whole object after it's done. So,
-	// changes to status has to be done after kube.Update in order not to get them
-	// lost.
 	if err := azure.FetchAsyncOperation(ctx, e.client.GetRESTClient(), &cr.Status.AtProvider.LastOperation); err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
 	}
@@ -136,8 +140,9 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}
 
 	return managed.ExternalObservation{
-		ResourceExists:   true,
-		ResourceUpToDate: database.IsMySQLUpToDate(cr.Spec.ForProvider, server),
+		ResourceExists:          true,
+		ResourceUpToDate:        database.IsMySQLUpToDate(cr.Spec.ForProvider, server),
+		ResourceLateInitialized: li,
 		ConnectionDetails: managed.ConnectionDetails{
 			xpv1.ResourceCredentialsSecretEndpointKey: []byte(cr.Status.AtProvider.FullyQualifiedDomainName),
 			xpv1.ResourceCredentialsSecretUserKey:     []byte(fmt.Sprintf("%s@%s", cr.Spec.ForProvider.AdministratorLogin, meta.GetExternalName(cr))),
@@ -203,3 +208,22 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 		azure.FetchAsyncOperation(ctx, e.client.GetRESTClient(), &cr.Status.AtProvider.LastOperation),
 		errFetchLastOperation)
 }
+
+func setDefaults(mg resource.Managed, d *azurev1beta1.ResourceDefaults) {
+	cr, ok := mg.(*v1beta1.MySQLServer)
+	if !ok {
+		return
+	}
+	azure.DefaultLocation(&cr.Spec.ForProvider.Location, d)
+	azure.DefaultResourceGroupName(&cr.Spec.ForProvider.ResourceGroupName, cr.Spec.ForProvider.ResourceGroupNameRef, cr.Spec.ForProvider.ResourceGroupNameSelector, d)
+	azure.MergeTags(&cr.Spec.ForProvider.Tags, d)
+}
+
+// resourcePath returns the path of the MySQLServer's Azure resource.
+func resourcePath(mg resource.Managed) string {
+	cr, ok := mg.(*v1beta1.MySQLServer)
+	if !ok {
+		return ""
+	}
+	return eventgrid.Path(cr.Spec.ForProvider.ResourceGroupName, "Microsoft.DBforMySQL/servers", meta.GetExternalName(cr))
+}
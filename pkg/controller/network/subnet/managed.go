@@ -18,15 +18,18 @@ package subnet
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	azurenetwork "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network/networkapi"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/pkg/errors"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
@@ -38,36 +41,49 @@ import (
 
 	"github.com/crossplane/provider-azure/apis/network/v1alpha3"
 	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/azureerrors"
 	"github.com/crossplane/provider-azure/pkg/clients/network"
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/clients/whatif"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
 )
 
 // Error strings.
 const (
-	errNotSubnet    = "managed resource is not an Subnet"
-	errCreateSubnet = "cannot create Subnet"
-	errUpdateSubnet = "cannot update Subnet"
-	errGetSubnet    = "cannot get Subnet"
-	errDeleteSubnet = "cannot delete Subnet"
+	errNotSubnet          = "managed resource is not an Subnet"
+	errCreateSubnet       = "cannot create Subnet"
+	errUpdateSubnet       = "cannot update Subnet"
+	errGetSubnet          = "cannot get Subnet"
+	errDeleteSubnet       = "cannot delete Subnet"
+	errFetchLastOperation = "cannot fetch last operation"
 )
 
 // Setup adds a controller that reconciles Subnets.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
 	name := managed.ControllerName(v1alpha3.SubnetGroupKind)
 
+	events, err := eventgrid.NewSource(mgr, &v1alpha3.Subnet{}, resourcePath)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(controller.Options{
-			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
 		}).
 		For(&v1alpha3.Subnet{}).
-		Complete(managed.NewReconciler(mgr,
+		Watches(events, &handler.EnqueueRequestForObject{}).
+		Complete(tracing.NewReconciler(name, managed.NewReconciler(mgr,
 			resource.ManagedKind(v1alpha3.SubnetGroupVersionKind),
 			managed.WithConnectionPublishers(),
-			managed.WithExternalConnecter(&connecter{client: mgr.GetClient()}),
+			managed.WithExternalConnecter(tracing.NewConnecter(name, azureerrors.NewConnecter(policy.NewConnecter(whatif.NewConnecter(&connecter{client: mgr.GetClient()}, event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))),
 			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
 			managed.WithPollInterval(poll),
 			managed.WithLogger(l.WithValues("controller", name)),
-			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
+			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))
 }
 
 type connecter struct {
@@ -79,12 +95,15 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := azurenetwork.NewSubnetsClient(creds[azureclients.CredentialsKeySubscriptionID])
-	cl.Authorizer = auth
-	return &external{client: cl}, nil
+	cl := azurenetwork.NewSubnetsClientWithBaseURI(creds[azureclients.CredentialsKeyResourceManagerEndpointURL], creds[azureclients.CredentialsKeySubscriptionID])
+	azureclients.ConfigureClient(&cl.Client, auth)
+	return &external{client: cl, sender: cl.Client}, nil
 }
 
-type external struct{ client networkapi.SubnetsClientAPI }
+type external struct {
+	client networkapi.SubnetsClientAPI
+	sender autorest.Sender
+}
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
 	s, ok := mg.(*v1alpha3.Subnet)
@@ -94,13 +113,19 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 
 	az, err := e.client.Get(ctx, s.Spec.ResourceGroupName, s.Spec.VirtualNetworkName, meta.GetExternalName(s), "")
 	if azureclients.IsNotFound(err) {
-		return managed.ExternalObservation{ResourceExists: false}, nil
+		if err := azureclients.FetchAsyncOperation(ctx, e.sender, &s.Status.LastOperation); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+		}
+		return managed.ExternalObservation{ResourceExists: azureclients.IsCreating(s.Status.LastOperation)}, nil
 	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetSubnet)
 	}
 
 	network.UpdateSubnetStatusFromAzure(s, az)
+	if err := azureclients.FetchAsyncOperation(ctx, e.sender, &s.Status.LastOperation); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+	}
 	s.SetConditions(xpv1.Available())
 
 	o := managed.ExternalObservation{
@@ -120,11 +145,15 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	s.Status.SetConditions(xpv1.Creating())
 
 	snet := network.NewSubnetParameters(s)
-	if _, err := e.client.CreateOrUpdate(ctx, s.Spec.ResourceGroupName, s.Spec.VirtualNetworkName, meta.GetExternalName(s), snet); err != nil {
+	op, err := e.client.CreateOrUpdate(ctx, s.Spec.ResourceGroupName, s.Spec.VirtualNetworkName, meta.GetExternalName(s), snet)
+	if err != nil {
 		return managed.ExternalCreation{}, errors.Wrap(err, errCreateSubnet)
 	}
+	s.Status.LastOperation = azureclients.NewAsyncOperation(http.MethodPut, op.Future)
 
-	return managed.ExternalCreation{}, nil
+	return managed.ExternalCreation{}, errors.Wrap(
+		azureclients.FetchAsyncOperation(ctx, e.sender, &s.Status.LastOperation),
+		errFetchLastOperation)
 }
 
 func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
@@ -132,6 +161,9 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 	if !ok {
 		return managed.ExternalUpdate{}, errors.New(errNotSubnet)
 	}
+	if s.Status.LastOperation.Status == azureclients.AsyncOperationStatusInProgress {
+		return managed.ExternalUpdate{}, nil
+	}
 
 	az, err := e.client.Get(ctx, s.Spec.ResourceGroupName, s.Spec.VirtualNetworkName, meta.GetExternalName(s), "")
 	if err != nil {
@@ -140,11 +172,17 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	if network.SubnetNeedsUpdate(s, az) {
 		snet := network.NewSubnetParameters(s)
-		if _, err := e.client.CreateOrUpdate(ctx, s.Spec.ResourceGroupName, s.Spec.VirtualNetworkName, meta.GetExternalName(s), snet); err != nil {
+		// Only update the Subnet if it is unchanged since we last observed
+		// it. See the VirtualNetwork controller.
+		op, err := e.client.CreateOrUpdate(azureclients.WithIfMatch(ctx, s.Status.Etag), s.Spec.ResourceGroupName, s.Spec.VirtualNetworkName, meta.GetExternalName(s), snet)
+		if err != nil {
 			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateSubnet)
 		}
+		s.Status.LastOperation = azureclients.NewAsyncOperation(http.MethodPut, op.Future)
 	}
-	return managed.ExternalUpdate{}, nil
+	return managed.ExternalUpdate{}, errors.Wrap(
+		azureclients.FetchAsyncOperation(ctx, e.sender, &s.Status.LastOperation),
+		errFetchLastOperation)
 }
 
 func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -155,6 +193,21 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 
 	mg.SetConditions(xpv1.Deleting())
 
-	_, err := e.client.Delete(ctx, s.Spec.ResourceGroupName, s.Spec.VirtualNetworkName, meta.GetExternalName(s))
-	return errors.Wrap(resource.Ignore(azureclients.IsNotFound, err), errDeleteSubnet)
+	op, err := e.client.Delete(ctx, s.Spec.ResourceGroupName, s.Spec.VirtualNetworkName, meta.GetExternalName(s))
+	if err != nil {
+		return errors.Wrap(resource.Ignore(azureclients.IsNotFound, err), errDeleteSubnet)
+	}
+	s.Status.LastOperation = azureclients.NewAsyncOperation(http.MethodDelete, op.Future)
+	return errors.Wrap(
+		azureclients.FetchAsyncOperation(ctx, e.sender, &s.Status.LastOperation),
+		errFetchLastOperation)
+}
+
+// resourcePath returns the path of the Subnet's Azure resource.
+func resourcePath(mg resource.Managed) string {
+	cr, ok := mg.(*v1alpha3.Subnet)
+	if !ok {
+		return ""
+	}
+	return eventgrid.Path(cr.Spec.ResourceGroupName, "Microsoft.Network/virtualNetworks", cr.Spec.VirtualNetworkName, "subnets", meta.GetExternalName(cr))
 }
@@ -35,6 +35,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane/provider-azure/apis/network/v1alpha3"
+	azurev1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
 	"github.com/crossplane/provider-azure/pkg/clients/network/fake"
 )
@@ -45,6 +46,7 @@ const (
 	addressPrefix      = "10.0.0.0/16"
 	virtualNetworkName = "coolVnet"
 	resourceGroupName  = "coolRG"
+	etag               = `W/"cool-etag"`
 )
 
 var (
@@ -69,6 +71,14 @@ func withConditions(c ...xpv1.Condition) subnetModifier {
 func withState(s string) subnetModifier {
 	return func(r *v1alpha3.Subnet) { r.Status.State = s }
 }
+
+func withLastOperation(op azurev1alpha3.AsyncOperation) subnetModifier {
+	return func(r *v1alpha3.Subnet) { r.Status.LastOperation = op }
+}
+
+func withEtag(e string) subnetModifier {
+	return func(r *v1alpha3.Subnet) { r.Status.Etag = e }
+}
 func subnet(sm ...subnetModifier) *v1alpha3.Subnet {
 	r := &v1alpha3.Subnet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -118,6 +128,7 @@ func TestCreate(t *testing.T) {
 			r: subnet(),
 			want: subnet(
 				withConditions(xpv1.Creating()),
+				withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodPut}),
 			),
 		},
 		{
@@ -254,12 +265,15 @@ func TestUpdate(t *testing.T) {
 						},
 					}, nil
 				},
-				MockCreateOrUpdate: func(_ context.Context, _ string, _ string, _ string, _ network.Subnet) (network.SubnetsCreateOrUpdateFuture, error) {
+				MockCreateOrUpdate: func(ctx context.Context, _ string, _ string, _ string, _ network.Subnet) (network.SubnetsCreateOrUpdateFuture, error) {
+					if azure.IfMatch(ctx) != etag {
+						return network.SubnetsCreateOrUpdateFuture{}, errorBoom
+					}
 					return network.SubnetsCreateOrUpdateFuture{}, nil
 				},
 			}},
-			r:    subnet(),
-			want: subnet(),
+			r:    subnet(withEtag(etag)),
+			want: subnet(withEtag(etag), withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodPut})),
 		},
 		{
 			name: "UnsuccessfulGet",
@@ -330,6 +344,7 @@ func TestDelete(t *testing.T) {
 			r: subnet(),
 			want: subnet(
 				withConditions(xpv1.Deleting()),
+				withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodDelete}),
 			),
 		},
 		{
@@ -18,15 +18,18 @@ package virtualnetwork
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	azurenetwork "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network/networkapi"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/pkg/errors"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
@@ -37,8 +40,14 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-azure/apis/network/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1beta1"
 	azureclients "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/azureerrors"
 	"github.com/crossplane/provider-azure/pkg/clients/network"
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/clients/whatif"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
 )
 
 // Error strings.
@@ -48,26 +57,35 @@ const (
 	errUpdateVirtualNetwork = "cannot update VirtualNetwork"
 	errGetVirtualNetwork    = "cannot get VirtualNetwork"
 	errDeleteVirtualNetwork = "cannot delete VirtualNetwork"
+	errFetchLastOperation   = "cannot fetch last operation"
 )
 
 // Setup adds a controller that reconciles VirtualNetworks.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
 	name := managed.ControllerName(v1alpha3.VirtualNetworkGroupKind)
 
+	events, err := eventgrid.NewSource(mgr, &v1alpha3.VirtualNetwork{}, resourcePath)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(controller.Options{
-			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
 		}).
 		For(&v1alpha3.VirtualNetwork{}).
-		Complete(managed.NewReconciler(mgr,
+		Watches(events, &handler.EnqueueRequestForObject{}).
+		Complete(tracing.NewReconciler(name, managed.NewReconciler(mgr,
 			resource.ManagedKind(v1alpha3.VirtualNetworkGroupVersionKind),
 			managed.WithConnectionPublishers(),
-			managed.WithExternalConnecter(&connecter{client: mgr.GetClient()}),
+			managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), azureclients.NewDefaultsInitializer(mgr.GetClient(), setDefaults)),
+			managed.WithExternalConnecter(tracing.NewConnecter(name, azureerrors.NewConnecter(policy.NewConnecter(whatif.NewConnecter(&connecter{client: mgr.GetClient()}, event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))),
 			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
 			managed.WithPollInterval(poll),
 			managed.WithLogger(l.WithValues("controller", name)),
-			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)))))
+			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))))
 }
 
 type connecter struct {
@@ -79,13 +97,14 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := azurenetwork.NewVirtualNetworksClient(creds[azureclients.CredentialsKeySubscriptionID])
-	cl.Authorizer = auth
-	return &external{client: cl}, nil
+	cl := azurenetwork.NewVirtualNetworksClientWithBaseURI(creds[azureclients.CredentialsKeyResourceManagerEndpointURL], creds[azureclients.CredentialsKeySubscriptionID])
+	azureclients.ConfigureClient(&cl.Client, auth)
+	return &external{client: cl, sender: cl.Client}, nil
 }
 
 type external struct {
 	client networkapi.VirtualNetworksClientAPI
+	sender autorest.Sender
 }
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -96,13 +115,19 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 
 	az, err := e.client.Get(ctx, v.Spec.ResourceGroupName, meta.GetExternalName(v), "")
 	if azureclients.IsNotFound(err) {
-		return managed.ExternalObservation{ResourceExists: false}, nil
+		if err := azureclients.FetchAsyncOperation(ctx, e.sender, &v.Status.LastOperation); err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+		}
+		return managed.ExternalObservation{ResourceExists: azureclients.IsCreating(v.Status.LastOperation)}, nil
 	}
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetVirtualNetwork)
 	}
 
 	network.UpdateVirtualNetworkStatusFromAzure(v, az)
+	if err := azureclients.FetchAsyncOperation(ctx, e.sender, &v.Status.LastOperation); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errFetchLastOperation)
+	}
 
 	v.SetConditions(xpv1.Available())
 
@@ -123,11 +148,15 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 	v.Status.SetConditions(xpv1.Creating())
 
 	vnet := network.NewVirtualNetworkParameters(v)
-	if _, err := e.client.CreateOrUpdate(ctx, v.Spec.ResourceGroupName, meta.GetExternalName(v), vnet); err != nil {
+	op, err := e.client.CreateOrUpdate(ctx, v.Spec.ResourceGroupName, meta.GetExternalName(v), vnet)
+	if err != nil {
 		return managed.ExternalCreation{}, errors.Wrap(err, errCreateVirtualNetwork)
 	}
+	v.Status.LastOperation = azureclients.NewAsyncOperation(http.MethodPut, op.Future)
 
-	return managed.ExternalCreation{}, nil
+	return managed.ExternalCreation{}, errors.Wrap(
+		azureclients.FetchAsyncOperation(ctx, e.sender, &v.Status.LastOperation),
+		errFetchLastOperation)
 }
 
 func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
@@ -135,6 +164,9 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 	if !ok {
 		return managed.ExternalUpdate{}, errors.New(errNotVirtualNetwork)
 	}
+	if v.Status.LastOperation.Status == azureclients.AsyncOperationStatusInProgress {
+		return managed.ExternalUpdate{}, nil
+	}
 
 	az, err := e.client.Get(ctx, v.Spec.ResourceGroupName, meta.GetExternalName(v), "")
 	if err != nil {
@@ -143,11 +175,19 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	if network.VirtualNetworkNeedsUpdate(v, az) {
 		vnet := network.NewVirtualNetworkParameters(v)
-		if _, err := e.client.CreateOrUpdate(ctx, v.Spec.ResourceGroupName, meta.GetExternalName(v), vnet); err != nil {
+		// Only update the VirtualNetwork if it is unchanged since we last
+		// observed it, to avoid overwriting changes made by others. If it
+		// has changed Azure responds with 412 Precondition Failed, and we'll
+		// observe it again before retrying.
+		op, err := e.client.CreateOrUpdate(azureclients.WithIfMatch(ctx, v.Status.Etag), v.Spec.ResourceGroupName, meta.GetExternalName(v), vnet)
+		if err != nil {
 			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateVirtualNetwork)
 		}
+		v.Status.LastOperation = azureclients.NewAsyncOperation(http.MethodPut, op.Future)
 	}
-	return managed.ExternalUpdate{}, nil
+	return managed.ExternalUpdate{}, errors.Wrap(
+		azureclients.FetchAsyncOperation(ctx, e.sender, &v.Status.LastOperation),
+		errFetchLastOperation)
 }
 
 func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -158,6 +198,31 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 
 	mg.SetConditions(xpv1.Deleting())
 
-	_, err := e.client.Delete(ctx, v.Spec.ResourceGroupName, meta.GetExternalName(v))
-	return errors.Wrap(resource.Ignore(azureclients.IsNotFound, err), errDeleteVirtualNetwork)
+	op, err := e.client.Delete(ctx, v.Spec.ResourceGroupName, meta.GetExternalName(v))
+	if err != nil {
+		return errors.Wrap(resource.Ignore(azureclients.IsNotFound, err), errDeleteVirtualNetwork)
+	}
+	v.Status.LastOperation = azureclients.NewAsyncOperation(http.MethodDelete, op.Future)
+	return errors.Wrap(
+		azureclients.FetchAsyncOperation(ctx, e.sender, &v.Status.LastOperation),
+		errFetchLastOperation)
+}
+
+func setDefaults(mg resource.Managed, d *v1beta1.ResourceDefaults) {
+	cr, ok := mg.(*v1alpha3.VirtualNetwork)
+	if !ok {
+		return
+	}
+	azureclients.DefaultLocation(&cr.Spec.Location, d)
+	azureclients.DefaultResourceGroupName(&cr.Spec.ResourceGroupName, cr.Spec.ResourceGroupNameRef, cr.Spec.ResourceGroupNameSelector, d)
+	azureclients.MergeTags(&cr.Spec.Tags, d)
+}
+
+// resourcePath returns the path of the VirtualNetwork's Azure resource.
+func resourcePath(mg resource.Managed) string {
+	cr, ok := mg.(*v1alpha3.VirtualNetwork)
+	if !ok {
+		return ""
+	}
+	return eventgrid.Path(cr.Spec.ResourceGroupName, "Microsoft.Network/virtualNetworks", meta.GetExternalName(cr))
 }
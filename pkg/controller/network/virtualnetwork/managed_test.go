@@ -35,6 +35,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane/provider-azure/apis/network/v1alpha3"
+	azurev1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
 	"github.com/crossplane/provider-azure/pkg/clients/network/fake"
 )
@@ -45,6 +46,7 @@ const (
 	addressPrefix     = "10.0.0.0/16"
 	resourceGroupName = "coolRG"
 	location          = "coolplace"
+	etag              = `W/"cool-etag"`
 )
 
 var (
@@ -71,6 +73,14 @@ func withState(s string) virtualNetworkModifier {
 	return func(r *v1alpha3.VirtualNetwork) { r.Status.State = s }
 }
 
+func withLastOperation(op azurev1alpha3.AsyncOperation) virtualNetworkModifier {
+	return func(r *v1alpha3.VirtualNetwork) { r.Status.LastOperation = op }
+}
+
+func withEtag(e string) virtualNetworkModifier {
+	return func(r *v1alpha3.VirtualNetwork) { r.Status.Etag = e }
+}
+
 func virtualNetwork(vm ...virtualNetworkModifier) *v1alpha3.VirtualNetwork {
 	r := &v1alpha3.VirtualNetwork{
 		ObjectMeta: metav1.ObjectMeta{
@@ -124,6 +134,7 @@ func TestCreate(t *testing.T) {
 			r: virtualNetwork(),
 			want: virtualNetwork(
 				withConditions(xpv1.Creating()),
+				withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodPut}),
 			),
 		},
 		{
@@ -251,7 +262,7 @@ func TestUpdate(t *testing.T) {
 			e: &external{client: &fake.MockVirtualNetworksClient{
 				MockGet: func(_ context.Context, _ string, _ string, _ string) (result network.VirtualNetwork, err error) {
 					return network.VirtualNetwork{
-						Tags: azure.ToStringPtrMap(tags),
+						Tags: azure.AddOwnerTags(azure.ToStringPtrMap(tags), virtualNetwork()),
 						VirtualNetworkPropertiesFormat: &network.VirtualNetworkPropertiesFormat{
 							AddressSpace: &network.AddressSpace{
 								AddressPrefixes: &[]string{addressPrefix},
@@ -280,12 +291,15 @@ func TestUpdate(t *testing.T) {
 						},
 					}, nil
 				},
-				MockCreateOrUpdate: func(_ context.Context, _ string, _ string, _ network.VirtualNetwork) (result network.VirtualNetworksCreateOrUpdateFuture, err error) {
+				MockCreateOrUpdate: func(ctx context.Context, _ string, _ string, _ network.VirtualNetwork) (result network.VirtualNetworksCreateOrUpdateFuture, err error) {
+					if azure.IfMatch(ctx) != etag {
+						return network.VirtualNetworksCreateOrUpdateFuture{}, errorBoom
+					}
 					return network.VirtualNetworksCreateOrUpdateFuture{}, nil
 				},
 			}},
-			r:    virtualNetwork(),
-			want: virtualNetwork(),
+			r:    virtualNetwork(withEtag(etag)),
+			want: virtualNetwork(withEtag(etag), withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodPut})),
 		},
 		{
 			name: "UnsuccessfulGet",
@@ -366,6 +380,7 @@ func TestDelete(t *testing.T) {
 			r: virtualNetwork(),
 			want: virtualNetwork(
 				withConditions(xpv1.Deleting()),
+				withLastOperation(azurev1alpha3.AsyncOperation{Method: http.MethodDelete}),
 			),
 		},
 		{
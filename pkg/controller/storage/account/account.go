@@ -18,11 +18,12 @@ package account
 
 import (
 	"context"
-	"reflect"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
 	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -31,6 +32,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -43,6 +45,8 @@ import (
 	"github.com/crossplane/provider-azure/apis/storage/v1alpha3"
 	azure "github.com/crossplane/provider-azure/pkg/clients"
 	azurestorage "github.com/crossplane/provider-azure/pkg/clients/storage"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
+	"github.com/crossplane/provider-azure/pkg/eventgrid"
 )
 
 const (
@@ -71,7 +75,7 @@ type Reconciler struct {
 }
 
 // Setup adds a controller that reconciles Accounts.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
 	name := managed.ControllerName(v1alpha3.AccountGroupKind)
 
 	r := &Reconciler{
@@ -82,14 +86,21 @@ func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll ti
 		log:              l.WithValues("controller", name),
 	}
 
+	events, err := eventgrid.NewSource(mgr, &v1alpha3.Account{}, resourcePath)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(controller.Options{
-			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
 		}).
 		For(&v1alpha3.Account{}).
+		Watches(events, &handler.EnqueueRequestForObject{}).
 		Owns(&corev1.Secret{}).
-		Complete(r)
+		Complete(tracing.NewReconciler(name, r))
 }
 
 // Reconcile reads that state of the cluster for a Provider acct and makes changes based on the state read
@@ -139,8 +150,8 @@ func (m *accountSyncdeleterMaker) newSyncdeleter(ctx context.Context, b *v1alpha
 		return nil, errors.Wrap(err, "cannot get auth information")
 	}
 
-	cl := storage.NewAccountsClient(creds[azure.CredentialsKeySubscriptionID])
-	cl.Authorizer = auth
+	cl := storage.NewAccountsClientWithBaseURI(creds[azure.CredentialsKeyResourceManagerEndpointURL], creds[azure.CredentialsKeySubscriptionID])
+	azure.ConfigureClient(&cl.Client, auth)
 
 	return newAccountSyncDeleter(
 		azurestorage.NewAccountHandle(&cl, b.Spec.ResourceGroupName, meta.GetExternalName(b)),
@@ -260,6 +271,7 @@ func (acu *accountCreateUpdater) create(ctx context.Context) (reconcile.Result,
 	meta.AddFinalizer(acu.acct, finalizer)
 
 	accountSpec := v1alpha3.ToStorageAccountCreate(acu.acct.Spec.StorageAccountSpec)
+	accountSpec.Tags = azure.AddOwnerTags(accountSpec.Tags, acu.acct)
 
 	a, err := acu.Create(ctx, accountSpec)
 	if err != nil {
@@ -275,13 +287,14 @@ func (acu *accountCreateUpdater) update(ctx context.Context, account *storage.Ac
 	if account.ProvisioningState == storage.Succeeded {
 		acu.acct.Status.SetConditions(xpv1.Available())
 
-		current := v1alpha3.NewStorageAccountSpec(account)
-		if reflect.DeepEqual(current, acu.acct.Spec.StorageAccountSpec) {
+		if cmp.Equal(specFromAccount(account), acu.acct.Spec.StorageAccountSpec, cmpopts.EquateEmpty()) {
 			acu.acct.Status.SetConditions(xpv1.ReconcileSuccess())
 			return reconcile.Result{RequeueAfter: acu.poll}, acu.kube.Status().Update(ctx, acu.acct)
 		}
 
-		a, err := acu.Update(ctx, v1alpha3.ToStorageAccountUpdate(acu.acct.Spec.StorageAccountSpec))
+		p := v1alpha3.ToStorageAccountUpdate(acu.acct.Spec.StorageAccountSpec)
+		p.Tags = azure.AddOwnerTags(p.Tags, acu.acct)
+		a, err := acu.Update(ctx, p)
 		if err != nil {
 			acu.acct.Status.SetConditions(xpv1.ReconcileError(err))
 			return resultRequeue, acu.kube.Status().Update(ctx, acu.acct)
@@ -292,6 +305,16 @@ func (acu *accountCreateUpdater) update(ctx context.Context, account *storage.Ac
 	return acu.syncback(ctx, account)
 }
 
+// specFromAccount returns the spec of the supplied account, without the owner
+// tags this controller adds to it.
+func specFromAccount(a *storage.Account) *v1alpha3.StorageAccountSpec {
+	s := v1alpha3.NewStorageAccountSpec(a)
+	if s != nil {
+		s.Tags = to.StringMap(azure.RemoveOwnerTags(a.Tags))
+	}
+	return s
+}
+
 type accountSyncbacker struct {
 	secretupdater
 	acct *v1alpha3.Account
@@ -309,9 +332,13 @@ func newAccountSyncBacker(ao azurestorage.AccountOperations, kube client.Client,
 }
 
 func (asb *accountSyncbacker) syncback(ctx context.Context, acct *storage.Account) (reconcile.Result, error) {
-	asb.acct.Spec.StorageAccountSpec = v1alpha3.NewStorageAccountSpec(acct)
-	if err := asb.kube.Update(ctx, asb.acct); err != nil {
-		return resultRequeue, err
+	// Only write the spec when it differs from the account, to avoid an API
+	// server write, and conflicts with users' edits, every sync.
+	if spec := specFromAccount(acct); !cmp.Equal(spec, asb.acct.Spec.StorageAccountSpec, cmpopts.EquateEmpty()) {
+		asb.acct.Spec.StorageAccountSpec = spec
+		if err := asb.kube.Update(ctx, asb.acct); err != nil {
+			return resultRequeue, err
+		}
 	}
 
 	asb.acct.Status.StorageAccountStatus = v1alpha3.NewStorageAccountStatus(acct)
@@ -372,3 +399,12 @@ func (asu *accountSecretUpdater) updatesecret(ctx context.Context, acct *storage
 
 	return nil
 }
+
+// resourcePath returns the path of the Account's Azure resource.
+func resourcePath(mg resource.Managed) string {
+	cr, ok := mg.(*v1alpha3.Account)
+	if !ok {
+		return ""
+	}
+	return eventgrid.Path(cr.Spec.ResourceGroupName, "Microsoft.Storage/storageAccounts", meta.GetExternalName(cr))
+}
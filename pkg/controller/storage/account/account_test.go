@@ -46,6 +46,7 @@ import (
 	"github.com/crossplane/provider-azure/apis/storage/v1alpha3"
 	v1alpha3test "github.com/crossplane/provider-azure/apis/storage/v1alpha3/test"
 	azurev1alpha3 "github.com/crossplane/provider-azure/apis/v1alpha3"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
 	azurestorage "github.com/crossplane/provider-azure/pkg/clients/storage"
 	azurestoragefake "github.com/crossplane/provider-azure/pkg/clients/storage/fake"
 )
@@ -150,6 +151,19 @@ func newStoragAccountSpecWithProperties() *v1alpha3.StorageAccountSpec {
 	return v1alpha3.NewStorageAccountSpec(&storage.Account{AccountProperties: &storage.AccountProperties{}})
 }
 
+func newStorageAccountSpecWithTags(tags map[string]string) *v1alpha3.StorageAccountSpec {
+	s := v1alpha3.NewStorageAccountSpec(&storage.Account{AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded}})
+	s.Tags = tags
+	return s
+}
+
+// ownerTagged returns the supplied tags with the owner tags of an account.
+func ownerTagged(tags map[string]*string) map[string]*string {
+	a := v1alpha3test.NewMockAccount(testAccountName).Account
+	a.SetUID("definitely-a-uuid")
+	return azure.AddOwnerTags(tags, a)
+}
+
 type storageAccount struct {
 	*storage.Account
 }
@@ -687,6 +701,32 @@ func Test_bucketCreateUpdater_update(t *testing.T) {
 					Account,
 			},
 		},
+		{
+			name: "NoChangesOwnerTags",
+			attrs: &storage.Account{
+				AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded},
+				Tags:              ownerTagged(map[string]*string{"env": to.StringPtr("dev")}),
+			},
+			fields: fields{
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(newStorageAccountSpecWithTags(map[string]string{"env": "dev"})).
+					Account,
+				ao: &azurestoragefake.MockAccountOperations{
+					MockUpdate: func(ctx context.Context, update storage.AccountUpdateParameters) (attrs *storage.Account, e error) {
+						return nil, errBoom
+					},
+				},
+				kube: test.NewMockClient(),
+				poll: time.Minute,
+			},
+			want: want{
+				res: reconcile.Result{RequeueAfter: time.Minute},
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(newStorageAccountSpecWithTags(map[string]string{"env": "dev"})).
+					WithStatusConditions(xpv1.Available(), xpv1.ReconcileSuccess()).
+					Account,
+			},
+		},
 		{
 			name: "UpdateFailed",
 			attrs: &storage.Account{
@@ -808,6 +848,57 @@ func Test_accountSyncBacker_syncback(t *testing.T) {
 				acct: v1alpha3test.NewMockAccount(name).WithSpecStorageAccountSpec(newStorageAccountSpec()).Account,
 			},
 		},
+		{
+			name: "SpecUnchanged",
+			fields: fields{
+				secretupdater: &MockAccountSecretupdater{
+					MockUpdateSecret: func(ctx context.Context, a *storage.Account) error { return nil },
+				},
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(v1alpha3.NewStorageAccountSpec(&storage.Account{AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded}})).
+					Account,
+				kube: &test.MockClient{
+					MockUpdate:       test.NewMockUpdateFn(errBoom),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+			},
+			acct: &storage.Account{AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded}},
+			want: want{
+				res: reconcile.Result{},
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(v1alpha3.NewStorageAccountSpec(&storage.Account{AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded}})).
+					WithSpecStatusFromProperties(&storage.AccountProperties{ProvisioningState: storage.Succeeded}).
+					WithStatusConditions(xpv1.ReconcileSuccess()).
+					Account,
+			},
+		},
+		{
+			name: "SpecUnchangedOwnerTags",
+			fields: fields{
+				secretupdater: &MockAccountSecretupdater{
+					MockUpdateSecret: func(ctx context.Context, a *storage.Account) error { return nil },
+				},
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(newStorageAccountSpecWithTags(map[string]string{"env": "dev"})).
+					Account,
+				kube: &test.MockClient{
+					MockUpdate:       test.NewMockUpdateFn(errBoom),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+			},
+			acct: &storage.Account{
+				AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded},
+				Tags:              ownerTagged(map[string]*string{"env": to.StringPtr("dev")}),
+			},
+			want: want{
+				res: reconcile.Result{},
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(newStorageAccountSpecWithTags(map[string]string{"env": "dev"})).
+					WithStorageAccountStatus(v1alpha3.NewStorageAccountStatus(&storage.Account{AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded}})).
+					WithStatusConditions(xpv1.ReconcileSuccess()).
+					Account,
+			},
+		},
 		{
 			name: "ProvisionStatusIsNotSucceeded",
 			fields: fields{
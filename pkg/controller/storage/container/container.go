@@ -44,6 +44,7 @@ import (
 
 	"github.com/crossplane/provider-azure/apis/storage/v1alpha3"
 	"github.com/crossplane/provider-azure/pkg/clients/storage"
+	"github.com/crossplane/provider-azure/pkg/clients/tracing"
 )
 
 const (
@@ -75,7 +76,7 @@ type Reconciler struct {
 }
 
 // Setup adds a controller that reconciles Containers.
-func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration) error {
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll time.Duration, concurrency int) error {
 	name := managed.ControllerName(v1alpha3.ContainerGroupKind)
 
 	r := &Reconciler{
@@ -89,10 +90,11 @@ func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter, poll ti
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(controller.Options{
-			RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+			RateLimiter:             ratelimiter.NewDefaultManagedRateLimiter(rl),
+			MaxConcurrentReconciles: concurrency,
 		}).
 		For(&v1alpha3.Container{}).
-		Complete(r)
+		Complete(tracing.NewReconciler(name, r))
 }
 
 // Reconcile reads that state of the cluster for a Provider acct and makes changes based on the state read
@@ -155,7 +157,7 @@ func (m *containerSyncdeleterMaker) newSyncdeleter(ctx context.Context, c *v1alp
 				return nil, errors.Wrapf(err, "failed to update after removing finalizer")
 			}
 		}
-		return nil, errors.Wrapf(err, "failed to retrieve storage account: %s", c.Spec.ProviderReference.Name)
+		return nil, errors.Wrapf(err, "failed to retrieve storage account: %s", nn.Name)
 	}
 
 	if acct.GetWriteConnectionSecretToReference() == nil {
@@ -175,8 +177,10 @@ func (m *containerSyncdeleterMaker) newSyncdeleter(ctx context.Context, c *v1alp
 	accountName := string(s.Data[xpv1.ResourceCredentialsSecretUserKey])
 	accountPassword := string(s.Data[xpv1.ResourceCredentialsSecretPasswordKey])
 	containerName := meta.GetExternalName(c)
+	// The account's blob endpoint tells us which Azure cloud it lives in.
+	suffix := storage.BlobEndpointSuffix(string(s.Data[xpv1.ResourceCredentialsSecretEndpointKey]))
 
-	ch, err := storage.NewContainerHandle(accountName, accountPassword, containerName)
+	ch, err := storage.NewContainerHandle(accountName, accountPassword, containerName, suffix)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create client handle: %s, storage account: %s", containerName, accountName)
 	}
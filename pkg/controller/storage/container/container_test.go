@@ -290,7 +290,7 @@ func Test_containerSyncdeleterMaker_newSyncdeleter(t *testing.T) {
 	ctx := context.TODO()
 	testAccountKey := "dGVzdC1rZXkK"
 
-	ch, err := storage.NewContainerHandle(testAccountName, testAccountKey, testContainerName)
+	ch, err := storage.NewContainerHandle(testAccountName, testAccountKey, testContainerName, "")
 	if err != nil {
 		t.Errorf("containerSyncdeleterMaker.newSyncdeleter() unexpected error %v", err)
 	}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package eventgrid triggers reconciles of managed resources when Azure Event
+// Grid reports that their Azure resources were written or deleted, so that
+// drift is detected without waiting for the poll interval.
+//
+// Managed resources are matched to events by the path of their Azure
+// resource, and the subscription they make requests to as of when the event
+// is received, i.e. that of the ProviderConfig they reference or their
+// subscription ID annotation.
+//
+// Triggered reconciles observe Azure resources as usual. When the Azure
+// Resource Graph cache is enabled they may be served a snapshot taken before
+// the reported change, in which case the change is observed once the snapshot
+// is refreshed.
+package eventgrid
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Event Grid event types.
+const (
+	// EventTypeSubscriptionValidation is sent when an event subscription is
+	// created, to validate that the endpoint wants to receive its events.
+	EventTypeSubscriptionValidation = "Microsoft.EventGrid.SubscriptionValidationEvent"
+
+	// EventTypeResourceWriteSuccess is sent when an Azure resource is
+	// created or updated.
+	EventTypeResourceWriteSuccess = "Microsoft.Resources.ResourceWriteSuccess"
+
+	// EventTypeResourceDeleteSuccess is sent when an Azure resource is
+	// deleted.
+	EventTypeResourceDeleteSuccess = "Microsoft.Resources.ResourceDeleteSuccess"
+)
+
+// An Event delivered by Azure Event Grid, using the Event Grid event schema.
+type Event struct {
+	ID          string          `json:"id"`
+	Topic       string          `json:"topic,omitempty"`
+	Subject     string          `json:"subject"`
+	EventType   string          `json:"eventType"`
+	EventTime   string          `json:"eventTime"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	DataVersion string          `json:"dataVersion,omitempty"`
+}
+
+// SubscriptionValidationData is the data of a subscription validation event.
+type SubscriptionValidationData struct {
+	ValidationCode string `json:"validationCode"`
+	ValidationURL  string `json:"validationUrl,omitempty"`
+}
+
+// SubscriptionValidationResponse validates an event subscription.
+type SubscriptionValidationResponse struct {
+	ValidationResponse string `json:"validationResponse"`
+}
+
+// ResourceEventData is the data of a resource write or delete event.
+type ResourceEventData struct {
+	ResourceProvider string `json:"resourceProvider,omitempty"`
+	ResourceURI      string `json:"resourceUri"`
+	OperationName    string `json:"operationName,omitempty"`
+	Status           string `json:"status,omitempty"`
+	SubscriptionID   string `json:"subscriptionId,omitempty"`
+	CorrelationID    string `json:"correlationId,omitempty"`
+}
+
+// Path returns the path of an Azure resource relative to its subscription,
+// given the name of its resource group and the segments that follow its
+// resource provider, e.g. "Microsoft.Network/virtualNetworks", "coolVNet". It
+// returns the path of the resource group itself if no segments are supplied.
+// Paths are case insensitive, and are returned in lower case. An empty path
+// is returned if the resource group or any segment is empty.
+func Path(resourceGroup string, segments ...string) string {
+	if resourceGroup == "" {
+		return ""
+	}
+	parts := []string{"resourcegroups", resourceGroup}
+	if len(segments) > 0 {
+		parts = append(parts, "providers")
+	}
+	for _, s := range segments {
+		if s == "" {
+			return ""
+		}
+		parts = append(parts, s)
+	}
+	return strings.ToLower(strings.Join(parts, "/"))
+}
+
+// PathFromID returns the path of the supplied Azure resource ID relative to
+// its subscription, in lower case.
+func PathFromID(id string) string {
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+	if len(parts) >= 2 && strings.EqualFold(parts[0], "subscriptions") {
+		parts = parts[2:]
+	}
+	return strings.ToLower(strings.Join(parts, "/"))
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventgrid
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// QueryKey is the URL query parameter that must contain the Receiver's key,
+// if it has one. It should be included in the endpoint URL of the event
+// subscription, e.g. https://example.org/?key=secret.
+const QueryKey = "key"
+
+const (
+	// Event Grid delivers batches of at most 1MB.
+	maxBodyBytes = 1 << 20
+
+	shutdownTimeout = 10 * time.Second
+)
+
+// A Receiver is an Event Grid webhook endpoint that enqueues reconciles of the
+// managed resources whose Azure resources were written or deleted. It expects
+// events of an Azure subscription event subscription, using the Event Grid
+// event schema.
+type Receiver struct {
+	log   logging.Logger
+	kinds *Registry
+	key   string
+}
+
+// A ReceiverOption configures a Receiver.
+type ReceiverOption func(*Receiver)
+
+// WithLogger specifies how the Receiver should log.
+func WithLogger(l logging.Logger) ReceiverOption {
+	return func(r *Receiver) {
+		r.log = l
+	}
+}
+
+// WithKey specifies a key that requests must include as the QueryKey URL
+// query parameter. Requests are not authenticated if the key is empty.
+func WithKey(k string) ReceiverOption {
+	return func(r *Receiver) {
+		r.key = k
+	}
+}
+
+// WithRegistry specifies the kinds of managed resource the Receiver should
+// enqueue reconciles of. By default it enqueues reconciles of all kinds whose
+// controller watches a source returned by NewSource.
+func WithRegistry(rg *Registry) ReceiverOption {
+	return func(r *Receiver) {
+		r.kinds = rg
+	}
+}
+
+// NewReceiver returns a Receiver.
+func NewReceiver(o ...ReceiverOption) *Receiver {
+	r := &Receiver{log: logging.NewNopLogger(), kinds: kinds}
+	for _, fn := range o {
+		fn(r)
+	}
+	return r
+}
+
+// ServeHTTP handles a batch of events delivered by Event Grid.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.key != "" && subtle.ConstantTimeCompare([]byte(req.URL.Query().Get(QueryKey)), []byte(r.key)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	events := []Event{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBodyBytes)).Decode(&events); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, e := range events {
+		switch e.EventType {
+		case EventTypeSubscriptionValidation:
+			d := SubscriptionValidationData{}
+			if err := json.Unmarshal(e.Data, &d); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.log.Debug("Validating event subscription", "topic", e.Topic)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(SubscriptionValidationResponse{ValidationResponse: d.ValidationCode})
+			return
+		case EventTypeResourceWriteSuccess, EventTypeResourceDeleteSuccess:
+			d := ResourceEventData{}
+			if err := json.Unmarshal(e.Data, &d); err != nil || d.ResourceURI == "" {
+				d.ResourceURI = e.Subject
+			}
+			n, err := r.kinds.Enqueue(req.Context(), d.ResourceURI)
+			if err != nil {
+				// Event Grid retries deliveries that fail.
+				r.log.Info("Cannot enqueue reconciles", "id", e.ID, "resource", d.ResourceURI, "error", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			r.log.Debug("Enqueued reconciles", "id", e.ID, "type", e.EventType, "resource", d.ResourceURI, "count", n)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// A Server serves a Receiver. It implements controller-runtime's Runnable, so
+// that it may be run by a manager.
+type Server struct {
+	addr     string
+	receiver *Receiver
+}
+
+// NewServer returns a Server that serves the supplied Receiver at the supplied
+// address. Event Grid only delivers events to HTTPS endpoints, so the Server
+// is expected to be exposed by a TLS terminating ingress.
+func NewServer(addr string, r *Receiver) *Server {
+	return &Server{addr: addr, receiver: r}
+}
+
+// Start serving until the supplied context is done.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{Addr: s.addr, Handler: s.receiver}
+	errs := make(chan error, 1)
+	go func() { errs <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(sctx)
+	}
+}
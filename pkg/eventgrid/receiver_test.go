@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventgrid
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+)
+
+func recorded(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestReceiver(t *testing.T) {
+	errBoom := errors.New("boom")
+	gvk := schema.GroupVersionKind{Group: "network.azure.crossplane.io", Version: "v1alpha3", Kind: "VirtualNetwork"}
+
+	// managed resources by the path of their Azure resource.
+	managed := map[string]string{
+		"resourcegroups/coolrg/providers/microsoft.network/virtualnetworks/coolvnet":                    "cool-vnet",
+		"resourcegroups/coolrg/providers/microsoft.network/virtualnetworks/coolvnet/subnets/coolsubnet": "cool-subnet",
+	}
+	list := func(_ context.Context, path string) ([]client.Object, error) {
+		n, ok := managed[path]
+		if !ok {
+			return nil, nil
+		}
+		mg := &fake.Managed{}
+		mg.SetName(n)
+		return []client.Object{mg}, nil
+	}
+	subscription := func(sub string, err error) SubscriptionFn {
+		return func(_ context.Context, _ resource.Managed) (string, error) { return sub, err }
+	}
+	sub := subscription("BF1B0E59-93DA-42E0-82C6-5A1D94227911", nil)
+
+	type args struct {
+		method string
+		target string
+		body   []byte
+		list   ListFn
+		sub    SubscriptionFn
+	}
+	type want struct {
+		status   int
+		body     string
+		enqueued []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      []ReceiverOption
+		args   args
+		want   want
+	}{
+		"NotPost": {
+			reason: "Only POST requests should be accepted.",
+			args:   args{method: http.MethodGet, target: "/", list: list, sub: sub},
+			want:   want{status: http.StatusMethodNotAllowed},
+		},
+		"WrongKey": {
+			reason: "Requests without the Receiver's key should be rejected.",
+			o:      []ReceiverOption{WithKey("secret")},
+			args:   args{method: http.MethodPost, target: "/?key=guess", body: recorded(t, "write.json"), list: list, sub: sub},
+			want:   want{status: http.StatusUnauthorized},
+		},
+		"MalformedBody": {
+			reason: "Requests that are not a batch of events should be rejected.",
+			args:   args{method: http.MethodPost, target: "/", body: []byte(`{"cool": true}`), list: list, sub: sub},
+			want:   want{status: http.StatusBadRequest, body: "json: cannot unmarshal object into Go value of type []eventgrid.Event\n"},
+		},
+		"SubscriptionValidation": {
+			reason: "Event subscriptions should be validated by echoing their validation code.",
+			args:   args{method: http.MethodPost, target: "/", body: recorded(t, "validation.json"), list: list, sub: sub},
+			want:   want{status: http.StatusOK, body: `{"validationResponse":"512d38b6-c7b8-40c8-89fe-f46f9e9622b6"}` + "\n"},
+		},
+		"ResourceWriteSuccess": {
+			reason: "A reconcile of the managed resource whose Azure resource was written should be enqueued, and other events ignored.",
+			o:      []ReceiverOption{WithKey("secret")},
+			args:   args{method: http.MethodPost, target: "/?key=secret", body: recorded(t, "write.json"), list: list, sub: sub},
+			want:   want{status: http.StatusOK, enqueued: []string{"cool-vnet"}},
+		},
+		"ResourceDeleteSuccess": {
+			reason: "A reconcile of the managed resource whose Azure resource was deleted should be enqueued.",
+			args:   args{method: http.MethodPost, target: "/", body: recorded(t, "delete.json"), list: list, sub: sub},
+			want:   want{status: http.StatusOK, enqueued: []string{"cool-subnet"}},
+		},
+		"ListError": {
+			reason: "Deliveries should fail, so that Event Grid retries them, if managed resources cannot be listed.",
+			args: args{method: http.MethodPost, target: "/", body: recorded(t, "write.json"), list: func(_ context.Context, _ string) ([]client.Object, error) {
+				return nil, errBoom
+			}},
+			want: want{status: http.StatusInternalServerError},
+		},
+		"OtherSubscription": {
+			reason: "Managed resources that make requests to a subscription other than that of the written Azure resource should not be reconciled.",
+			args:   args{method: http.MethodPost, target: "/", body: recorded(t, "write.json"), list: list, sub: subscription("0b1f6471-1bf0-4dda-aec3-cb9272f09590", nil)},
+			want:   want{status: http.StatusOK},
+		},
+		"SubscriptionError": {
+			reason: "Managed resources whose subscription cannot be determined should be reconciled, so that their reconcile reports why.",
+			args:   args{method: http.MethodPost, target: "/", body: recorded(t, "write.json"), list: list, sub: subscription("", errBoom)},
+			want:   want{status: http.StatusOK, enqueued: []string{"cool-vnet"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rg := NewRegistry()
+			rg.Register(gvk, tc.args.list, tc.args.sub)
+			r := NewReceiver(append([]ReceiverOption{WithRegistry(rg)}, tc.o...)...)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.args.method, tc.args.target, bytes.NewReader(tc.args.body)))
+
+			if diff := cmp.Diff(tc.want.status, w.Code); diff != "" {
+				t.Errorf("\n%s\nServeHTTP(...): -want status, +got status:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.body, w.Body.String()); diff != "" {
+				t.Errorf("\n%s\nServeHTTP(...): -want body, +got body:\n%s", tc.reason, diff)
+			}
+
+			var enqueued []string
+			events := rg.kinds[gvk].events
+			for len(events) > 0 {
+				enqueued = append(enqueued, (<-events).Object.GetName())
+			}
+			if diff := cmp.Diff(tc.want.enqueued, enqueued); diff != "" {
+				t.Errorf("\n%s\nServeHTTP(...): -want enqueued, +got enqueued:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	cases := map[string]struct {
+		reason        string
+		resourceGroup string
+		segments      []string
+		want          string
+	}{
+		"ResourceGroup": {
+			reason:        "The path of a resource group should not include a provider.",
+			resourceGroup: "coolRG",
+			want:          "resourcegroups/coolrg",
+		},
+		"Subnet": {
+			reason:        "The path of a child resource should include its parent.",
+			resourceGroup: "coolRG",
+			segments:      []string{"Microsoft.Network/virtualNetworks", "coolVNet", "subnets", "coolSubnet"},
+			want:          "resourcegroups/coolrg/providers/microsoft.network/virtualnetworks/coolvnet/subnets/coolsubnet",
+		},
+		"Unknown": {
+			reason:        "The path should be empty if any segment is unknown.",
+			resourceGroup: "coolRG",
+			segments:      []string{"Microsoft.Network/virtualNetworks", ""},
+			want:          "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Path(tc.resourceGroup, tc.segments...)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nPath(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			id := "/subscriptions/bf1b0e59-93da-42e0-82c6-5a1d94227911/" + strings.ToUpper(got)
+			if got != "" && PathFromID(id) != got {
+				t.Errorf("\n%s\nPathFromID(%q): want %q, got %q", tc.reason, id, got, PathFromID(id))
+			}
+		})
+	}
+}
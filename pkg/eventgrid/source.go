@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventgrid
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+// IndexResourcePath indexes managed resources by the path of their Azure
+// resource, per PathFn.
+const IndexResourcePath = "azure.crossplane.io/resource-path"
+
+// Error strings.
+const (
+	errGetGVK      = "cannot get GroupVersionKind of managed resource"
+	errIndex       = "cannot index managed resources by Azure resource path"
+	errNewList     = "cannot create list of managed resources"
+	errListManaged = "cannot list managed resources by Azure resource path"
+)
+
+const (
+	listKindSuffix = "List"
+
+	// eventBuffer is how many reconciles may be waiting to be enqueued for
+	// each kind of managed resource before the Receiver blocks.
+	eventBuffer = 100
+)
+
+// A PathFn returns the path of the Azure resource of the supplied managed
+// resource, relative to its subscription (see Path), or an empty string if it
+// is not known.
+type PathFn func(mg resource.Managed) string
+
+// A ListFn lists the managed resources of a kind whose Azure resource has the
+// supplied path.
+type ListFn func(ctx context.Context, path string) ([]client.Object, error)
+
+// A SubscriptionFn returns the subscription the supplied managed resource
+// makes requests to.
+type SubscriptionFn func(ctx context.Context, mg resource.Managed) (string, error)
+
+// kinds of managed resource that may be reconciled when Event Grid reports
+// their Azure resources changed.
+var kinds = NewRegistry()
+
+// enabled is whether Event Grid events are received, and thus whether
+// managed resources should be indexed to find those an event is about.
+var enabled bool
+
+// SetEnabled sets whether Event Grid events are received. Managed resources
+// are only indexed, and their controllers only watch for events, if they are.
+// It should be called before any controllers are set up.
+func SetEnabled(e bool) {
+	enabled = e
+}
+
+// A Registry tracks the kinds of managed resource whose reconciles may be
+// triggered by Event Grid events, and how to find them.
+type Registry struct {
+	mu    sync.RWMutex
+	kinds map[schema.GroupVersionKind]*kind
+}
+
+type kind struct {
+	list   ListFn
+	sub    SubscriptionFn
+	events chan event.GenericEvent
+}
+
+// NewRegistry returns a Registry that tracks no kinds.
+func NewRegistry() *Registry {
+	return &Registry{kinds: map[schema.GroupVersionKind]*kind{}}
+}
+
+// Register a kind of managed resource, listed using the supplied ListFn. The
+// subscription of each listed managed resource is determined using the
+// supplied SubscriptionFn. It returns a source of events that trigger
+// reconciles of the kind.
+func (r *Registry) Register(gvk schema.GroupVersionKind, fn ListFn, sub SubscriptionFn) source.Source {
+	k := &kind{list: fn, sub: sub, events: make(chan event.GenericEvent, eventBuffer)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.kinds[gvk] = k
+	return &source.Channel{Source: k.events}
+}
+
+// Enqueue reconciles of all managed resources whose Azure resource has the
+// supplied ID. It returns how many were enqueued. Managed resources are found
+// by the path of their Azure resource, then those that make requests to
+// another subscription are skipped. Managed resources whose subscription
+// can't be determined, e.g. because their ProviderConfig is missing, are
+// enqueued regardless; their reconcile will report why.
+func (r *Registry) Enqueue(ctx context.Context, id string) (int, error) {
+	path := PathFromID(id)
+	if path == "" {
+		return 0, nil
+	}
+	sub := azure.SubscriptionFromPath(id)
+
+	r.mu.RLock()
+	ks := make([]*kind, 0, len(r.kinds))
+	for _, k := range r.kinds {
+		ks = append(ks, k)
+	}
+	r.mu.RUnlock()
+
+	n := 0
+	for _, k := range ks {
+		objs, err := k.list(ctx, path)
+		if err != nil {
+			return n, errors.Wrap(err, errListManaged)
+		}
+		for _, o := range objs {
+			if mg, ok := o.(resource.Managed); ok && sub != "" {
+				if s, err := k.sub(ctx, mg); err == nil && !strings.EqualFold(s, sub) {
+					continue
+				}
+			}
+			select {
+			case k.events <- event.GenericEvent{Object: o}:
+				n++
+			case <-ctx.Done():
+				return n, ctx.Err()
+			}
+		}
+	}
+	return n, nil
+}
+
+// NewSource indexes managed resources of the supplied type by the path of
+// their Azure resource, per the supplied PathFn. It returns a source of events
+// that trigger reconciles of managed resources of the type when Event Grid
+// reports that their Azure resources changed. If Event Grid events are not
+// received (see SetEnabled) managed resources are not indexed, and the
+// returned source never triggers a reconcile.
+func NewSource(mgr ctrl.Manager, o client.Object, fn PathFn) (source.Source, error) {
+	if !enabled {
+		return source.Func(none), nil
+	}
+	gvk, err := apiutil.GVKForObject(o, mgr.GetScheme())
+	if err != nil {
+		return nil, errors.Wrap(err, errGetGVK)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), o, IndexResourcePath, index(fn)); err != nil {
+		return nil, errors.Wrap(err, errIndex)
+	}
+
+	lgvk := gvk.GroupVersion().WithKind(gvk.Kind + listKindSuffix)
+	return kinds.Register(gvk, func(ctx context.Context, path string) ([]client.Object, error) {
+		ro, err := mgr.GetScheme().New(lgvk)
+		if err != nil {
+			return nil, errors.Wrap(err, errNewList)
+		}
+		l, ok := ro.(client.ObjectList)
+		if !ok {
+			return nil, errors.New(errNewList)
+		}
+		if err := mgr.GetClient().List(ctx, l, client.MatchingFields{IndexResourcePath: path}); err != nil {
+			return nil, err
+		}
+		items, err := kmeta.ExtractList(l)
+		if err != nil {
+			return nil, err
+		}
+		objs := make([]client.Object, 0, len(items))
+		for _, i := range items {
+			if o, ok := i.(client.Object); ok {
+				objs = append(objs, o)
+			}
+		}
+		return objs, nil
+	}, func(ctx context.Context, mg resource.Managed) (string, error) {
+		return azure.GetSubscriptionID(ctx, mgr.GetClient(), mg)
+	}), nil
+}
+
+// none is a source of no events.
+func none(_ context.Context, _ handler.EventHandler, _ workqueue.RateLimitingInterface, _ ...predicate.Predicate) error {
+	return nil
+}
+
+func index(fn PathFn) client.IndexerFunc {
+	return func(o client.Object) []string {
+		mg, ok := o.(resource.Managed)
+		if !ok {
+			return nil
+		}
+		if p := fn(mg); p != "" {
+			return []string{p}
+		}
+		return nil
+	}
+}
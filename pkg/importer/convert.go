@@ -0,0 +1,239 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2018-03-31/containerservice"
+	"github.com/Azure/azure-sdk-for-go/services/cosmos-db/mgmt/2015-04-08/documentdb"
+	"github.com/Azure/azure-sdk-for-go/services/mysql/mgmt/2017-12-01/mysql"
+	networkmgmt "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/postgresql/mgmt/2017-12-01/postgresql"
+	redismgmt "github.com/Azure/azure-sdk-for-go/services/redis/mgmt/2018-03-01/redis"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+
+	cachev1beta1 "github.com/crossplane/provider-azure/apis/cache/v1beta1"
+	computev1alpha3 "github.com/crossplane/provider-azure/apis/compute/v1alpha3"
+	databasev1alpha3 "github.com/crossplane/provider-azure/apis/database/v1alpha3"
+	databasev1beta1 "github.com/crossplane/provider-azure/apis/database/v1beta1"
+	networkv1alpha3 "github.com/crossplane/provider-azure/apis/network/v1alpha3"
+	storagev1alpha3 "github.com/crossplane/provider-azure/apis/storage/v1alpha3"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+	"github.com/crossplane/provider-azure/pkg/clients/database"
+	"github.com/crossplane/provider-azure/pkg/clients/database/cosmosdb"
+	"github.com/crossplane/provider-azure/pkg/clients/redis"
+)
+
+// The conversions below produce the spec of a managed resource that is up to
+// date with the supplied Azure resource. Fields that are late initialized are
+// filled by the same functions the managed resource's controller uses.
+
+// PostgreSQLServer returns a PostgreSQLServer for the supplied server.
+func PostgreSQLServer(rg string, in postgresql.Server) *databasev1beta1.PostgreSQLServer {
+	cr := &databasev1beta1.PostgreSQLServer{}
+	cr.SetGroupVersionKind(databasev1beta1.PostgreSQLServerGroupVersionKind)
+	p := &cr.Spec.ForProvider
+	p.ResourceGroupName = rg
+	p.Location = to.String(in.Location)
+	if in.Sku != nil {
+		p.SKU = databasev1beta1.SKU{Tier: string(in.Sku.Tier), Capacity: azure.ToInt(in.Sku.Capacity), Family: to.String(in.Sku.Family)}
+	}
+	if in.ServerProperties != nil {
+		p.AdministratorLogin = to.String(in.AdministratorLogin)
+		p.Version = string(in.Version)
+		if in.StorageProfile != nil {
+			p.StorageProfile.StorageMB = azure.ToInt(in.StorageProfile.StorageMB)
+		}
+		database.LateInitializePostgreSQL(p, in)
+	}
+	return cr
+}
+
+// MySQLServer returns a MySQLServer for the supplied server.
+func MySQLServer(rg string, in mysql.Server) *databasev1beta1.MySQLServer {
+	cr := &databasev1beta1.MySQLServer{}
+	cr.SetGroupVersionKind(databasev1beta1.MySQLServerGroupVersionKind)
+	p := &cr.Spec.ForProvider
+	p.ResourceGroupName = rg
+	p.Location = to.String(in.Location)
+	if in.Sku != nil {
+		p.SKU = databasev1beta1.SKU{Tier: string(in.Sku.Tier), Capacity: azure.ToInt(in.Sku.Capacity), Family: to.String(in.Sku.Family)}
+	}
+	if in.ServerProperties != nil {
+		p.AdministratorLogin = to.String(in.AdministratorLogin)
+		p.Version = string(in.Version)
+		if in.StorageProfile != nil {
+			p.StorageProfile.StorageMB = azure.ToInt(in.StorageProfile.StorageMB)
+		}
+		database.LateInitializeMySQL(p, in)
+	}
+	return cr
+}
+
+// PostgreSQLServerFirewallRule returns a PostgreSQLServerFirewallRule for the
+// supplied firewall rule of the supplied server.
+func PostgreSQLServerFirewallRule(rg, server string, in postgresql.FirewallRule) *databasev1alpha3.PostgreSQLServerFirewallRule {
+	cr := &databasev1alpha3.PostgreSQLServerFirewallRule{}
+	cr.SetGroupVersionKind(databasev1alpha3.PostgreSQLServerFirewallRuleGroupVersionKind)
+	cr.Spec.ForProvider = databasev1alpha3.FirewallRuleParameters{ResourceGroupName: rg, ServerName: server}
+	if in.FirewallRuleProperties != nil {
+		cr.Spec.ForProvider.StartIPAddress = to.String(in.StartIPAddress)
+		cr.Spec.ForProvider.EndIPAddress = to.String(in.EndIPAddress)
+	}
+	return cr
+}
+
+// MySQLServerFirewallRule returns a MySQLServerFirewallRule for the supplied
+// firewall rule of the supplied server.
+func MySQLServerFirewallRule(rg, server string, in mysql.FirewallRule) *databasev1alpha3.MySQLServerFirewallRule {
+	cr := &databasev1alpha3.MySQLServerFirewallRule{}
+	cr.SetGroupVersionKind(databasev1alpha3.MySQLServerFirewallRuleGroupVersionKind)
+	cr.Spec.ForProvider = databasev1alpha3.FirewallRuleParameters{ResourceGroupName: rg, ServerName: server}
+	if in.FirewallRuleProperties != nil {
+		cr.Spec.ForProvider.StartIPAddress = to.String(in.StartIPAddress)
+		cr.Spec.ForProvider.EndIPAddress = to.String(in.EndIPAddress)
+	}
+	return cr
+}
+
+// PostgreSQLServerVirtualNetworkRule returns a
+// PostgreSQLServerVirtualNetworkRule for the supplied virtual network rule of
+// the supplied server.
+func PostgreSQLServerVirtualNetworkRule(rg, server string, in postgresql.VirtualNetworkRule) *databasev1alpha3.PostgreSQLServerVirtualNetworkRule {
+	cr := &databasev1alpha3.PostgreSQLServerVirtualNetworkRule{}
+	cr.SetGroupVersionKind(databasev1alpha3.PostgreSQLServerVirtualNetworkRuleGroupVersionKind)
+	cr.Spec.ResourceGroupName = rg
+	cr.Spec.ServerName = server
+	if in.VirtualNetworkRuleProperties != nil {
+		cr.Spec.VirtualNetworkSubnetID = to.String(in.VirtualNetworkSubnetID)
+		cr.Spec.IgnoreMissingVnetServiceEndpoint = to.Bool(in.IgnoreMissingVnetServiceEndpoint)
+	}
+	return cr
+}
+
+// MySQLServerVirtualNetworkRule returns a MySQLServerVirtualNetworkRule for
+// the supplied virtual network rule of the supplied server.
+func MySQLServerVirtualNetworkRule(rg, server string, in mysql.VirtualNetworkRule) *databasev1alpha3.MySQLServerVirtualNetworkRule {
+	cr := &databasev1alpha3.MySQLServerVirtualNetworkRule{}
+	cr.SetGroupVersionKind(databasev1alpha3.MySQLServerVirtualNetworkRuleGroupVersionKind)
+	cr.Spec.ResourceGroupName = rg
+	cr.Spec.ServerName = server
+	if in.VirtualNetworkRuleProperties != nil {
+		cr.Spec.VirtualNetworkSubnetID = to.String(in.VirtualNetworkSubnetID)
+		cr.Spec.IgnoreMissingVnetServiceEndpoint = to.Bool(in.IgnoreMissingVnetServiceEndpoint)
+	}
+	return cr
+}
+
+// VirtualNetwork returns a VirtualNetwork for the supplied virtual network.
+func VirtualNetwork(rg string, in networkmgmt.VirtualNetwork) *networkv1alpha3.VirtualNetwork {
+	cr := &networkv1alpha3.VirtualNetwork{}
+	cr.SetGroupVersionKind(networkv1alpha3.VirtualNetworkGroupVersionKind)
+	cr.Spec.ResourceGroupName = rg
+	cr.Spec.Location = to.String(in.Location)
+	cr.Spec.Tags = to.StringMap(azure.RemoveOwnerTags(in.Tags))
+	if in.VirtualNetworkPropertiesFormat != nil {
+		if in.AddressSpace != nil && in.AddressSpace.AddressPrefixes != nil {
+			cr.Spec.AddressSpace.AddressPrefixes = *in.AddressSpace.AddressPrefixes
+		}
+		cr.Spec.EnableDDOSProtection = to.Bool(in.EnableDdosProtection)
+		cr.Spec.EnableVMProtection = to.Bool(in.EnableVMProtection)
+	}
+	return cr
+}
+
+// Subnet returns a Subnet for the supplied subnet of the supplied virtual
+// network.
+func Subnet(rg, vnet string, in networkmgmt.Subnet) *networkv1alpha3.Subnet {
+	cr := &networkv1alpha3.Subnet{}
+	cr.SetGroupVersionKind(networkv1alpha3.SubnetGroupVersionKind)
+	cr.Spec.ResourceGroupName = rg
+	cr.Spec.VirtualNetworkName = vnet
+	if in.SubnetPropertiesFormat != nil {
+		cr.Spec.AddressPrefix = to.String(in.AddressPrefix)
+		if in.ServiceEndpoints != nil {
+			for _, e := range *in.ServiceEndpoints {
+				cr.Spec.ServiceEndpoints = append(cr.Spec.ServiceEndpoints, networkv1alpha3.ServiceEndpointPropertiesFormat{
+					Service:   to.String(e.Service),
+					Locations: to.StringSlice(e.Locations),
+				})
+			}
+		}
+	}
+	return cr
+}
+
+// Redis returns a Redis for the supplied cache.
+func Redis(rg string, in redismgmt.ResourceType) *cachev1beta1.Redis {
+	cr := &cachev1beta1.Redis{}
+	cr.SetGroupVersionKind(cachev1beta1.RedisGroupVersionKind)
+	cr.Spec.ForProvider.ResourceGroupName = rg
+	cr.Spec.ForProvider.Location = to.String(in.Location)
+	if in.Properties != nil && in.Sku != nil {
+		cr.Spec.ForProvider.SKU = cachev1beta1.SKU{Name: string(in.Sku.Name), Family: string(in.Sku.Family), Capacity: azure.ToInt(in.Sku.Capacity)}
+	}
+	redis.LateInitialize(&cr.Spec.ForProvider, in)
+	return cr
+}
+
+// CosmosDBAccount returns a CosmosDBAccount for the supplied database account.
+func CosmosDBAccount(rg string, in documentdb.DatabaseAccount) *databasev1alpha3.CosmosDBAccount {
+	cr := &databasev1alpha3.CosmosDBAccount{}
+	cr.SetGroupVersionKind(databasev1alpha3.CosmosDBAccountGroupVersionKind)
+	cr.Spec.ForProvider = databasev1alpha3.CosmosDBAccountParameters{
+		ResourceGroupName: rg,
+		Kind:              in.Kind,
+		Location:          to.String(in.Location),
+		Properties:        cosmosdb.FromDatabaseProperties(in.DatabaseAccountProperties),
+		Tags:              to.StringMap(azure.RemoveOwnerTags(in.Tags)),
+	}
+	return cr
+}
+
+// Account returns a storage Account for the supplied storage account.
+func Account(rg string, in storage.Account) *storagev1alpha3.Account {
+	cr := &storagev1alpha3.Account{}
+	cr.SetGroupVersionKind(storagev1alpha3.AccountGroupVersionKind)
+	cr.Spec.ResourceGroupName = rg
+	cr.Spec.StorageAccountSpec = storagev1alpha3.NewStorageAccountSpec(&in)
+	cr.Spec.StorageAccountSpec.Tags = to.StringMap(azure.RemoveOwnerTags(in.Tags))
+	return cr
+}
+
+// AKSCluster returns an AKSCluster for the supplied managed cluster. Its node
+// pool is the cluster's first agent pool.
+func AKSCluster(rg string, in containerservice.ManagedCluster) *computev1alpha3.AKSCluster {
+	cr := &computev1alpha3.AKSCluster{}
+	cr.SetGroupVersionKind(computev1alpha3.AKSClusterGroupVersionKind)
+	cr.Spec.ResourceGroupName = rg
+	cr.Spec.Location = to.String(in.Location)
+	if in.ManagedClusterProperties == nil {
+		return cr
+	}
+	cr.Spec.Version = to.String(in.KubernetesVersion)
+	cr.Spec.DNSNamePrefix = to.String(in.DNSPrefix)
+	cr.Spec.DisableRBAC = !to.Bool(in.EnableRBAC)
+	if in.AgentPoolProfiles != nil && len(*in.AgentPoolProfiles) > 0 {
+		pool := (*in.AgentPoolProfiles)[0]
+		if pool.Count != nil {
+			cr.Spec.NodeCount = to.IntPtr(int(*pool.Count))
+		}
+		cr.Spec.NodeVMSize = string(pool.VMSize)
+		cr.Spec.VnetSubnetID = to.String(pool.VnetSubnetID)
+	}
+	return cr
+}
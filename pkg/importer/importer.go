@@ -0,0 +1,301 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package importer generates managed resources for existing Azure resources,
+// so that they may be adopted.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2018-03-31/containerservice"
+	"github.com/Azure/azure-sdk-for-go/services/cosmos-db/mgmt/2015-04-08/documentdb"
+	"github.com/Azure/azure-sdk-for-go/services/mysql/mgmt/2017-12-01/mysql"
+	networkmgmt "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/postgresql/mgmt/2017-12-01/postgresql"
+	redismgmt "github.com/Azure/azure-sdk-for-go/services/redis/mgmt/2018-03-01/redis"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
+)
+
+// Error strings.
+const (
+	errListFmt    = "cannot list %s"
+	errListForFmt = "cannot list %s of %s"
+	errConvert    = "cannot convert managed resource"
+	errMarshal    = "cannot marshal managed resource"
+	errWrite      = "cannot write managed resource"
+)
+
+// maxNameLength is the longest name of a Kubernetes object.
+const maxNameLength = 253
+
+// invalidName matches the characters that may not appear in the name of a
+// Kubernetes object.
+var invalidName = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// A Lister lists the Azure resources of a resource group, or of a parent
+// resource within it.
+type Lister interface {
+	PostgreSQLServers(ctx context.Context, rg string) ([]postgresql.Server, error)
+	PostgreSQLFirewallRules(ctx context.Context, rg, server string) ([]postgresql.FirewallRule, error)
+	PostgreSQLVirtualNetworkRules(ctx context.Context, rg, server string) ([]postgresql.VirtualNetworkRule, error)
+	MySQLServers(ctx context.Context, rg string) ([]mysql.Server, error)
+	MySQLFirewallRules(ctx context.Context, rg, server string) ([]mysql.FirewallRule, error)
+	MySQLVirtualNetworkRules(ctx context.Context, rg, server string) ([]mysql.VirtualNetworkRule, error)
+	VirtualNetworks(ctx context.Context, rg string) ([]networkmgmt.VirtualNetwork, error)
+	Subnets(ctx context.Context, rg, vnet string) ([]networkmgmt.Subnet, error)
+	Redis(ctx context.Context, rg string) ([]redismgmt.ResourceType, error)
+	CosmosDBAccounts(ctx context.Context, rg string) ([]documentdb.DatabaseAccount, error)
+	StorageAccounts(ctx context.Context, rg string) ([]storage.Account, error)
+	AKSClusters(ctx context.Context, rg string) ([]containerservice.ManagedCluster, error)
+}
+
+// An Importer generates managed resources for the Azure resources of a
+// resource group. Each managed resource has the external name of its Azure
+// resource, and a spec that is up to date with it.
+type Importer struct {
+	lister Lister
+	out    io.Writer
+
+	providerConfig   string
+	secretsNamespace string
+	observeOnly      bool
+}
+
+// An ImporterOption configures an Importer.
+type ImporterOption func(*Importer)
+
+// WithOutput specifies where the Importer should print managed resources.
+func WithOutput(w io.Writer) ImporterOption {
+	return func(i *Importer) {
+		i.out = w
+	}
+}
+
+// WithProviderConfig specifies the ProviderConfig managed resources should
+// reference.
+func WithProviderConfig(name string) ImporterOption {
+	return func(i *Importer) {
+		i.providerConfig = name
+	}
+}
+
+// WithConnectionSecretNamespace specifies the namespace managed resources
+// that have connection details should write them to, in a secret named after
+// the managed resource. They don't write them if the namespace is empty.
+func WithConnectionSecretNamespace(ns string) ImporterOption {
+	return func(i *Importer) {
+		i.secretsNamespace = ns
+	}
+}
+
+// WithObserveOnly specifies that managed resources should have the observe-only
+// management policy, such that their Azure resources are never modified.
+func WithObserveOnly(observeOnly bool) ImporterOption {
+	return func(i *Importer) {
+		i.observeOnly = observeOnly
+	}
+}
+
+// NewImporter returns an Importer that lists Azure resources using the
+// supplied Lister.
+func NewImporter(l Lister, o ...ImporterOption) *Importer {
+	i := &Importer{lister: l, out: ioutil.Discard}
+	for _, io := range o {
+		io(i)
+	}
+	return i
+}
+
+// Import prints a managed resource for each supported Azure resource in the
+// supplied resource group, as a stream of YAML documents.
+func (i *Importer) Import(ctx context.Context, rg string) error {
+	mgs, err := i.Managed(ctx, rg)
+	if err != nil {
+		return err
+	}
+	for _, mg := range mgs {
+		if err := i.print(mg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Managed returns a managed resource for each supported Azure resource in the
+// supplied resource group. Child resources follow their parent.
+func (i *Importer) Managed(ctx context.Context, rg string) ([]resource.Managed, error) { // nolint:gocyclo
+	mgs := []resource.Managed{}
+
+	pgs, err := i.lister.PostgreSQLServers(ctx, rg)
+	if err != nil {
+		return nil, errors.Wrapf(err, errListFmt, "PostgreSQL servers")
+	}
+	for _, s := range pgs {
+		srv := to.String(s.Name)
+		mgs = append(mgs, i.managed(PostgreSQLServer(rg, s), true, srv))
+		frs, err := i.lister.PostgreSQLFirewallRules(ctx, rg, srv)
+		if err != nil {
+			return nil, errors.Wrapf(err, errListForFmt, "firewall rules", srv)
+		}
+		for _, r := range frs {
+			mgs = append(mgs, i.managed(PostgreSQLServerFirewallRule(rg, srv, r), false, to.String(r.Name), srv))
+		}
+		vrs, err := i.lister.PostgreSQLVirtualNetworkRules(ctx, rg, srv)
+		if err != nil {
+			return nil, errors.Wrapf(err, errListForFmt, "virtual network rules", srv)
+		}
+		for _, r := range vrs {
+			mgs = append(mgs, i.managed(PostgreSQLServerVirtualNetworkRule(rg, srv, r), false, to.String(r.Name), srv))
+		}
+	}
+
+	mys, err := i.lister.MySQLServers(ctx, rg)
+	if err != nil {
+		return nil, errors.Wrapf(err, errListFmt, "MySQL servers")
+	}
+	for _, s := range mys {
+		srv := to.String(s.Name)
+		mgs = append(mgs, i.managed(MySQLServer(rg, s), true, srv))
+		frs, err := i.lister.MySQLFirewallRules(ctx, rg, srv)
+		if err != nil {
+			return nil, errors.Wrapf(err, errListForFmt, "firewall rules", srv)
+		}
+		for _, r := range frs {
+			mgs = append(mgs, i.managed(MySQLServerFirewallRule(rg, srv, r), false, to.String(r.Name), srv))
+		}
+		vrs, err := i.lister.MySQLVirtualNetworkRules(ctx, rg, srv)
+		if err != nil {
+			return nil, errors.Wrapf(err, errListForFmt, "virtual network rules", srv)
+		}
+		for _, r := range vrs {
+			mgs = append(mgs, i.managed(MySQLServerVirtualNetworkRule(rg, srv, r), false, to.String(r.Name), srv))
+		}
+	}
+
+	vnets, err := i.lister.VirtualNetworks(ctx, rg)
+	if err != nil {
+		return nil, errors.Wrapf(err, errListFmt, "virtual networks")
+	}
+	for _, v := range vnets {
+		vnet := to.String(v.Name)
+		mgs = append(mgs, i.managed(VirtualNetwork(rg, v), false, vnet))
+		subnets, err := i.lister.Subnets(ctx, rg, vnet)
+		if err != nil {
+			return nil, errors.Wrapf(err, errListForFmt, "subnets", vnet)
+		}
+		for _, s := range subnets {
+			mgs = append(mgs, i.managed(Subnet(rg, vnet, s), false, to.String(s.Name), vnet))
+		}
+	}
+
+	caches, err := i.lister.Redis(ctx, rg)
+	if err != nil {
+		return nil, errors.Wrapf(err, errListFmt, "Redis caches")
+	}
+	for _, c := range caches {
+		mgs = append(mgs, i.managed(Redis(rg, c), true, to.String(c.Name)))
+	}
+
+	accts, err := i.lister.CosmosDBAccounts(ctx, rg)
+	if err != nil {
+		return nil, errors.Wrapf(err, errListFmt, "Cosmos DB accounts")
+	}
+	for _, a := range accts {
+		mgs = append(mgs, i.managed(CosmosDBAccount(rg, a), true, to.String(a.Name)))
+	}
+
+	sas, err := i.lister.StorageAccounts(ctx, rg)
+	if err != nil {
+		return nil, errors.Wrapf(err, errListFmt, "storage accounts")
+	}
+	for _, a := range sas {
+		mgs = append(mgs, i.managed(Account(rg, a), true, to.String(a.Name)))
+	}
+
+	clusters, err := i.lister.AKSClusters(ctx, rg)
+	if err != nil {
+		return nil, errors.Wrapf(err, errListFmt, "AKS clusters")
+	}
+	for _, c := range clusters {
+		mgs = append(mgs, i.managed(AKSCluster(rg, c), true, to.String(c.Name)))
+	}
+
+	return mgs, nil
+}
+
+// managed completes the supplied managed resource, whose Azure resource has
+// the supplied external name. Child resources are named after their parents,
+// if supplied, to keep their names unique.
+func (i *Importer) managed(mg resource.Managed, hasSecret bool, externalName string, parents ...string) resource.Managed {
+	mg.SetName(Name(append(parents, externalName)...))
+	meta.SetExternalName(mg, externalName)
+	if i.observeOnly {
+		meta.AddAnnotations(mg, map[string]string{policy.AnnotationKeyManagementPolicy: policy.ManagementPolicyObserveOnly})
+	}
+	if i.providerConfig != "" {
+		mg.SetProviderConfigReference(&xpv1.Reference{Name: i.providerConfig})
+	}
+	if hasSecret && i.secretsNamespace != "" {
+		mg.SetWriteConnectionSecretToReference(&xpv1.SecretReference{Name: mg.GetName(), Namespace: i.secretsNamespace})
+	}
+	return mg
+}
+
+// print the supplied managed resource as a YAML document, omitting its status
+// and any fields that are set by the API server.
+func (i *Importer) print(mg resource.Managed) error {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(mg)
+	if err != nil {
+		return errors.Wrap(err, errConvert)
+	}
+	delete(u, "status")
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+	b, err := yaml.Marshal(u)
+	if err != nil {
+		return errors.Wrap(err, errMarshal)
+	}
+	_, err = fmt.Fprintf(i.out, "---\n%s", b)
+	return errors.Wrap(err, errWrite)
+}
+
+// Name returns a valid name for a Kubernetes object derived from the supplied
+// Azure resource names, e.g. coolserver-allow-all for the firewall rule
+// Allow_All of the server coolServer.
+func Name(names ...string) string {
+	n := strings.ToLower(strings.Join(names, "-"))
+	n = invalidName.ReplaceAllString(strings.ReplaceAll(n, "_", "-"), "-")
+	if len(n) > maxNameLength {
+		n = n[:maxNameLength]
+	}
+	return strings.Trim(n, ".-")
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2018-03-31/containerservice"
+	"github.com/Azure/azure-sdk-for-go/services/cosmos-db/mgmt/2015-04-08/documentdb"
+	"github.com/Azure/azure-sdk-for-go/services/mysql/mgmt/2017-12-01/mysql"
+	networkmgmt "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/postgresql/mgmt/2017-12-01/postgresql"
+	redismgmt "github.com/Azure/azure-sdk-for-go/services/redis/mgmt/2018-03-01/redis"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-azure/pkg/clients/database"
+	"github.com/crossplane/provider-azure/pkg/clients/network"
+	"github.com/crossplane/provider-azure/pkg/clients/policy"
+	"github.com/crossplane/provider-azure/pkg/clients/redis"
+)
+
+const rg = "coolRG"
+
+var _ Lister = &fakeLister{}
+
+type fakeLister struct {
+	pgs     []postgresql.Server
+	pgRules []postgresql.FirewallRule
+	vnets   []networkmgmt.VirtualNetwork
+	subnets []networkmgmt.Subnet
+	caches  []redismgmt.ResourceType
+	err     error
+}
+
+func (l *fakeLister) PostgreSQLServers(_ context.Context, _ string) ([]postgresql.Server, error) {
+	return l.pgs, l.err
+}
+
+func (l *fakeLister) PostgreSQLFirewallRules(_ context.Context, _, _ string) ([]postgresql.FirewallRule, error) {
+	return l.pgRules, nil
+}
+
+func (l *fakeLister) PostgreSQLVirtualNetworkRules(_ context.Context, _, _ string) ([]postgresql.VirtualNetworkRule, error) {
+	return nil, nil
+}
+
+func (l *fakeLister) MySQLServers(_ context.Context, _ string) ([]mysql.Server, error) {
+	return nil, nil
+}
+
+func (l *fakeLister) MySQLFirewallRules(_ context.Context, _, _ string) ([]mysql.FirewallRule, error) {
+	return nil, nil
+}
+
+func (l *fakeLister) MySQLVirtualNetworkRules(_ context.Context, _, _ string) ([]mysql.VirtualNetworkRule, error) {
+	return nil, nil
+}
+
+func (l *fakeLister) VirtualNetworks(_ context.Context, _ string) ([]networkmgmt.VirtualNetwork, error) {
+	return l.vnets, nil
+}
+
+func (l *fakeLister) Subnets(_ context.Context, _, _ string) ([]networkmgmt.Subnet, error) {
+	return l.subnets, nil
+}
+
+func (l *fakeLister) Redis(_ context.Context, _ string) ([]redismgmt.ResourceType, error) {
+	return l.caches, nil
+}
+
+func (l *fakeLister) CosmosDBAccounts(_ context.Context, _ string) ([]documentdb.DatabaseAccount, error) {
+	return nil, nil
+}
+
+func (l *fakeLister) StorageAccounts(_ context.Context, _ string) ([]storage.Account, error) {
+	return nil, nil
+}
+
+func (l *fakeLister) AKSClusters(_ context.Context, _ string) ([]containerservice.ManagedCluster, error) {
+	return nil, nil
+}
+
+func pgServer() postgresql.Server {
+	return postgresql.Server{
+		Name:     to.StringPtr("coolServer"),
+		Location: to.StringPtr("westus"),
+		Sku:      &postgresql.Sku{Tier: postgresql.GeneralPurpose, Capacity: to.Int32Ptr(2), Family: to.StringPtr("Gen5")},
+		ServerProperties: &postgresql.ServerProperties{
+			AdministratorLogin: to.StringPtr("cooladmin"),
+			Version:            postgresql.OneOne,
+			SslEnforcement:     postgresql.SslEnforcementEnumEnabled,
+			StorageProfile: &postgresql.StorageProfile{
+				StorageMB:           to.Int32Ptr(5120),
+				BackupRetentionDays: to.Int32Ptr(7),
+				GeoRedundantBackup:  postgresql.Disabled,
+				StorageAutogrow:     postgresql.StorageAutogrowEnabled,
+			},
+		},
+	}
+}
+
+func pgRule() postgresql.FirewallRule {
+	return postgresql.FirewallRule{
+		Name: to.StringPtr("Allow_All"),
+		FirewallRuleProperties: &postgresql.FirewallRuleProperties{
+			StartIPAddress: to.StringPtr("0.0.0.0"),
+			EndIPAddress:   to.StringPtr("255.255.255.255"),
+		},
+	}
+}
+
+func vnet() networkmgmt.VirtualNetwork {
+	return networkmgmt.VirtualNetwork{
+		Name:     to.StringPtr("coolVNet"),
+		Location: to.StringPtr("westus"),
+		Tags:     map[string]*string{"env": to.StringPtr("dev")},
+		VirtualNetworkPropertiesFormat: &networkmgmt.VirtualNetworkPropertiesFormat{
+			AddressSpace:         &networkmgmt.AddressSpace{AddressPrefixes: &[]string{"10.0.0.0/16"}},
+			EnableDdosProtection: to.BoolPtr(false),
+			EnableVMProtection:   to.BoolPtr(true),
+		},
+	}
+}
+
+func subnet() networkmgmt.Subnet {
+	return networkmgmt.Subnet{
+		Name:                   to.StringPtr("coolSubnet"),
+		SubnetPropertiesFormat: &networkmgmt.SubnetPropertiesFormat{AddressPrefix: to.StringPtr("10.0.0.0/24")},
+	}
+}
+
+func cache() redismgmt.ResourceType {
+	return redismgmt.ResourceType{
+		Name:     to.StringPtr("coolCache"),
+		Location: to.StringPtr("westus"),
+		Zones:    &[]string{"1"},
+		Properties: &redismgmt.Properties{
+			Sku:              &redismgmt.Sku{Name: redismgmt.Premium, Family: redismgmt.P, Capacity: to.Int32Ptr(1)},
+			EnableNonSslPort: to.BoolPtr(true),
+			ShardCount:       to.Int32Ptr(2),
+			RedisConfiguration: map[string]*string{
+				"maxmemory-policy": to.StringPtr("allkeys-lru"),
+			},
+		},
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		upToDate func() bool
+	}{
+		"PostgreSQLServer": {
+			reason: "An imported PostgreSQLServer should be up to date with its server.",
+			upToDate: func() bool {
+				return database.IsPostgreSQLUpToDate(PostgreSQLServer(rg, pgServer()).Spec.ForProvider, pgServer())
+			},
+		},
+		"PostgreSQLServerFirewallRule": {
+			reason: "An imported PostgreSQLServerFirewallRule should be up to date with its firewall rule.",
+			upToDate: func() bool {
+				return database.PostgreSQLServerFirewallRuleIsUpToDate(PostgreSQLServerFirewallRule(rg, "coolServer", pgRule()), pgRule())
+			},
+		},
+		"VirtualNetwork": {
+			reason: "An imported VirtualNetwork should be up to date with its virtual network.",
+			upToDate: func() bool {
+				return !network.VirtualNetworkNeedsUpdate(VirtualNetwork(rg, vnet()), vnet())
+			},
+		},
+		"Subnet": {
+			reason: "An imported Subnet should be up to date with its subnet.",
+			upToDate: func() bool {
+				return !network.SubnetNeedsUpdate(Subnet(rg, "coolVNet", subnet()), subnet())
+			},
+		},
+		"Redis": {
+			reason: "An imported Redis should be up to date with its cache.",
+			upToDate: func() bool {
+				return !redis.NeedsUpdate(Redis(rg, cache()).Spec.ForProvider, cache())
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if !tc.upToDate() {
+				t.Errorf("\n%s\nThe imported managed resource is not up to date", tc.reason)
+			}
+		})
+	}
+}
+
+func TestManaged(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		l      Lister
+		want   want
+	}{
+		"Resources": {
+			reason: "A managed resource should be returned for each Azure resource, with each child following its parent.",
+			l: &fakeLister{
+				pgs:     []postgresql.Server{pgServer()},
+				pgRules: []postgresql.FirewallRule{pgRule()},
+				vnets:   []networkmgmt.VirtualNetwork{vnet()},
+				subnets: []networkmgmt.Subnet{subnet()},
+				caches:  []redismgmt.ResourceType{cache()},
+			},
+			want: want{names: []string{"coolserver", "coolserver-allow-all", "coolvnet", "coolvnet-coolsubnet", "coolcache"}},
+		},
+		"ListError": {
+			reason: "Errors listing Azure resources should be returned.",
+			l:      &fakeLister{err: errBoom},
+			want:   want{err: errors.Wrapf(errBoom, errListFmt, "PostgreSQL servers")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			i := NewImporter(tc.l)
+			mgs, err := i.Managed(context.Background(), rg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nManaged(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var got []string
+			for _, mg := range mgs {
+				got = append(got, mg.GetName())
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nManaged(...): -want names, +got names:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	out := &bytes.Buffer{}
+	i := NewImporter(&fakeLister{caches: []redismgmt.ResourceType{cache()}},
+		WithOutput(out),
+		WithProviderConfig("coolConfig"),
+		WithConnectionSecretNamespace("crossplane-system"),
+		WithObserveOnly(true))
+
+	mgs, err := i.Managed(context.Background(), rg)
+	if err != nil {
+		t.Fatalf("Managed(...): %s", err)
+	}
+	mg := mgs[0]
+	if diff := cmp.Diff("coolCache", meta.GetExternalName(mg)); diff != "" {
+		t.Errorf("Managed(...): -want external name, +got external name:\n%s", diff)
+	}
+	if !policy.ObserveOnly(mg) {
+		t.Errorf("Managed(...): managed resource should be observe-only")
+	}
+	if diff := cmp.Diff(&xpv1.Reference{Name: "coolConfig"}, mg.GetProviderConfigReference()); diff != "" {
+		t.Errorf("Managed(...): -want provider config, +got provider config:\n%s", diff)
+	}
+	if diff := cmp.Diff(&xpv1.SecretReference{Name: "coolcache", Namespace: "crossplane-system"}, mg.GetWriteConnectionSecretToReference()); diff != "" {
+		t.Errorf("Managed(...): -want secret reference, +got secret reference:\n%s", diff)
+	}
+
+	if err := i.Import(context.Background(), rg); err != nil {
+		t.Fatalf("Import(...): %s", err)
+	}
+	for _, s := range []string{"---\n", "kind: Redis\n", "crossplane.io/external-name: coolCache\n"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("Import(...): output should contain %q:\n%s", s, out.String())
+		}
+	}
+	for _, s := range []string{"status:", "creationTimestamp:"} {
+		if strings.Contains(out.String(), s) {
+			t.Errorf("Import(...): output should not contain %q:\n%s", s, out.String())
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		names  []string
+		want   string
+	}{
+		"Simple": {
+			reason: "Names should be lower case.",
+			names:  []string{"coolServer"},
+			want:   "coolserver",
+		},
+		"Child": {
+			reason: "Child names should be prefixed with their parent's name, and invalid characters replaced.",
+			names:  []string{"coolServer", "Allow_All IPs"},
+			want:   "coolserver-allow-all-ips",
+		},
+		"Trimmed": {
+			reason: "Names should not begin or end with a separator.",
+			names:  []string{"_cool_"},
+			want:   "cool",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Name(tc.names...)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nName(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
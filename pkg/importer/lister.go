@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerservice/mgmt/2018-03-31/containerservice"
+	"github.com/Azure/azure-sdk-for-go/services/cosmos-db/mgmt/2015-04-08/documentdb"
+	"github.com/Azure/azure-sdk-for-go/services/mysql/mgmt/2017-12-01/mysql"
+	networkmgmt "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-06-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/postgresql/mgmt/2017-12-01/postgresql"
+	redismgmt "github.com/Azure/azure-sdk-for-go/services/redis/mgmt/2018-03-01/redis"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane/provider-azure/apis/v1alpha3"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+// An AzureLister lists Azure resources using the Azure Resource Manager API.
+type AzureLister struct {
+	baseURI        string
+	subscriptionID string
+	authorizer     autorest.Authorizer
+}
+
+// NewAzureLister returns a Lister that lists Azure resources using the
+// credentials of the supplied ProviderConfig.
+func NewAzureLister(ctx context.Context, kube client.Client, providerConfig string) (*AzureLister, error) {
+	// GetAuthInfo loads credentials for a managed resource, so we supply one
+	// that references the ProviderConfig.
+	mg := &v1alpha3.ResourceGroup{}
+	mg.SetProviderConfigReference(&xpv1.Reference{Name: providerConfig})
+	creds, auth, err := azure.GetAuthInfo(ctx, kube, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &AzureLister{
+		baseURI:        creds[azure.CredentialsKeyResourceManagerEndpointURL],
+		subscriptionID: creds[azure.CredentialsKeySubscriptionID],
+		authorizer:     auth,
+	}, nil
+}
+
+// PostgreSQLServers lists the PostgreSQL servers of the supplied resource
+// group.
+func (l *AzureLister) PostgreSQLServers(ctx context.Context, rg string) ([]postgresql.Server, error) {
+	cl := postgresql.NewServersClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	r, err := cl.ListByResourceGroup(ctx, rg)
+	if err != nil || r.Value == nil {
+		return nil, err
+	}
+	return *r.Value, nil
+}
+
+// PostgreSQLFirewallRules lists the firewall rules of the supplied PostgreSQL
+// server.
+func (l *AzureLister) PostgreSQLFirewallRules(ctx context.Context, rg, server string) ([]postgresql.FirewallRule, error) {
+	cl := postgresql.NewFirewallRulesClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	r, err := cl.ListByServer(ctx, rg, server)
+	if err != nil || r.Value == nil {
+		return nil, err
+	}
+	return *r.Value, nil
+}
+
+// PostgreSQLVirtualNetworkRules lists the virtual network rules of the
+// supplied PostgreSQL server.
+func (l *AzureLister) PostgreSQLVirtualNetworkRules(ctx context.Context, rg, server string) ([]postgresql.VirtualNetworkRule, error) {
+	cl := postgresql.NewVirtualNetworkRulesClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	it, err := cl.ListByServerComplete(ctx, rg, server)
+	out := []postgresql.VirtualNetworkRule{}
+	for ; err == nil && it.NotDone(); err = it.NextWithContext(ctx) {
+		out = append(out, it.Value())
+	}
+	return out, err
+}
+
+// MySQLServers lists the MySQL servers of the supplied resource group.
+func (l *AzureLister) MySQLServers(ctx context.Context, rg string) ([]mysql.Server, error) {
+	cl := mysql.NewServersClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	r, err := cl.ListByResourceGroup(ctx, rg)
+	if err != nil || r.Value == nil {
+		return nil, err
+	}
+	return *r.Value, nil
+}
+
+// MySQLFirewallRules lists the firewall rules of the supplied MySQL server.
+func (l *AzureLister) MySQLFirewallRules(ctx context.Context, rg, server string) ([]mysql.FirewallRule, error) {
+	cl := mysql.NewFirewallRulesClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	r, err := cl.ListByServer(ctx, rg, server)
+	if err != nil || r.Value == nil {
+		return nil, err
+	}
+	return *r.Value, nil
+}
+
+// MySQLVirtualNetworkRules lists the virtual network rules of the supplied
+// MySQL server.
+func (l *AzureLister) MySQLVirtualNetworkRules(ctx context.Context, rg, server string) ([]mysql.VirtualNetworkRule, error) {
+	cl := mysql.NewVirtualNetworkRulesClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	it, err := cl.ListByServerComplete(ctx, rg, server)
+	out := []mysql.VirtualNetworkRule{}
+	for ; err == nil && it.NotDone(); err = it.NextWithContext(ctx) {
+		out = append(out, it.Value())
+	}
+	return out, err
+}
+
+// VirtualNetworks lists the virtual networks of the supplied resource group.
+func (l *AzureLister) VirtualNetworks(ctx context.Context, rg string) ([]networkmgmt.VirtualNetwork, error) {
+	cl := networkmgmt.NewVirtualNetworksClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	it, err := cl.ListComplete(ctx, rg)
+	out := []networkmgmt.VirtualNetwork{}
+	for ; err == nil && it.NotDone(); err = it.NextWithContext(ctx) {
+		out = append(out, it.Value())
+	}
+	return out, err
+}
+
+// Subnets lists the subnets of the supplied virtual network.
+func (l *AzureLister) Subnets(ctx context.Context, rg, vnet string) ([]networkmgmt.Subnet, error) {
+	cl := networkmgmt.NewSubnetsClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	it, err := cl.ListComplete(ctx, rg, vnet)
+	out := []networkmgmt.Subnet{}
+	for ; err == nil && it.NotDone(); err = it.NextWithContext(ctx) {
+		out = append(out, it.Value())
+	}
+	return out, err
+}
+
+// Redis lists the Redis caches of the supplied resource group.
+func (l *AzureLister) Redis(ctx context.Context, rg string) ([]redismgmt.ResourceType, error) {
+	cl := redismgmt.NewClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	it, err := cl.ListByResourceGroupComplete(ctx, rg)
+	out := []redismgmt.ResourceType{}
+	for ; err == nil && it.NotDone(); err = it.NextWithContext(ctx) {
+		out = append(out, it.Value())
+	}
+	return out, err
+}
+
+// CosmosDBAccounts lists the Cosmos DB accounts of the supplied resource
+// group.
+func (l *AzureLister) CosmosDBAccounts(ctx context.Context, rg string) ([]documentdb.DatabaseAccount, error) {
+	cl := documentdb.NewDatabaseAccountsClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	r, err := cl.ListByResourceGroup(ctx, rg)
+	if err != nil || r.Value == nil {
+		return nil, err
+	}
+	return *r.Value, nil
+}
+
+// StorageAccounts lists the storage accounts of the supplied resource group.
+func (l *AzureLister) StorageAccounts(ctx context.Context, rg string) ([]storage.Account, error) {
+	cl := storage.NewAccountsClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	r, err := cl.ListByResourceGroup(ctx, rg)
+	if err != nil || r.Value == nil {
+		return nil, err
+	}
+	return *r.Value, nil
+}
+
+// AKSClusters lists the AKS clusters of the supplied resource group.
+func (l *AzureLister) AKSClusters(ctx context.Context, rg string) ([]containerservice.ManagedCluster, error) {
+	cl := containerservice.NewManagedClustersClientWithBaseURI(l.baseURI, l.subscriptionID)
+	azure.ConfigureClient(&cl.Client, l.authorizer)
+	it, err := cl.ListByResourceGroupComplete(ctx, rg)
+	out := []containerservice.ManagedCluster{}
+	for ; err == nil && it.NotDone(); err = it.NextWithContext(ctx) {
+		out = append(out, it.Value())
+	}
+	return out, err
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migration migrates managed resources from the deprecated Provider
+// to ProviderConfig.
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/apis/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1beta1"
+)
+
+// Error strings.
+const (
+	errListProviders     = "cannot list Providers"
+	errGetProviderConfig = "cannot get ProviderConfig"
+	errCreateProviderCfg = "cannot create ProviderConfig"
+	errListManagedFmt    = "cannot list %s"
+	errPatchManagedFmt   = "cannot patch %s %s"
+)
+
+const (
+	listKindSuffix = "List"
+
+	// Storage Containers reference a storage Account, not a Provider.
+	kindContainer = "Container"
+)
+
+// A Migrator migrates Providers to ProviderConfigs, and managed resources
+// from providerRef to providerConfigRef.
+type Migrator struct {
+	client client.Client
+	out    io.Writer
+	dryRun bool
+}
+
+// A MigratorOption configures a Migrator.
+type MigratorOption func(*Migrator)
+
+// WithOutput specifies where the Migrator should print the changes it makes,
+// or would make.
+func WithOutput(w io.Writer) MigratorOption {
+	return func(m *Migrator) {
+		m.out = w
+	}
+}
+
+// WithDryRun specifies that the Migrator should only print the changes it
+// would make, without making them.
+func WithDryRun(dryRun bool) MigratorOption {
+	return func(m *Migrator) {
+		m.dryRun = dryRun
+	}
+}
+
+// NewMigrator returns a Migrator that uses the supplied client. Every managed
+// resource kind known to the client's scheme is migrated.
+func NewMigrator(c client.Client, o ...MigratorOption) *Migrator {
+	m := &Migrator{client: c, out: ioutil.Discard}
+	for _, mo := range o {
+		mo(m)
+	}
+	return m
+}
+
+// Migrate creates a ProviderConfig for each Provider, with the same name and
+// credentials, then patches each managed resource that references a Provider
+// to reference the ProviderConfig instead. Existing ProviderConfigs are
+// reused if their credentials match those of the Provider; managed resources
+// referencing a Provider whose ProviderConfig has different credentials are
+// not migrated.
+//
+// Storage Containers reference their storage Account rather than a Provider,
+// and use the same Account when they reference it as a ProviderConfig, so
+// they are always migrated.
+func (m *Migrator) Migrate(ctx context.Context) error {
+	conflicts, err := m.migrateProviders(ctx)
+	if err != nil {
+		return err
+	}
+	for _, gvk := range m.managedListKinds() {
+		if err := m.migrateManaged(ctx, gvk, conflicts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateProviders creates a ProviderConfig for each Provider, and returns the
+// names of Providers whose ProviderConfig conflicts with them.
+func (m *Migrator) migrateProviders(ctx context.Context) (map[string]bool, error) {
+	l := &v1alpha3.ProviderList{}
+	if err := m.client.List(ctx, l); err != nil {
+		return nil, errors.Wrap(err, errListProviders)
+	}
+	conflicts := map[string]bool{}
+	for i := range l.Items {
+		p := &l.Items[i]
+		want := ProviderConfig(p)
+		got := &v1beta1.ProviderConfig{}
+		err := m.client.Get(ctx, types.NamespacedName{Name: p.GetName()}, got)
+		switch {
+		case resource.IgnoreNotFound(err) != nil:
+			return nil, errors.Wrap(err, errGetProviderConfig)
+		case err == nil && sameCredentials(got, want):
+			m.printf("ProviderConfig %s already exists\n", p.GetName())
+			continue
+		case err == nil:
+			m.printf("ProviderConfig %s already exists with different credentials; skipping managed resources that reference Provider %s\n", p.GetName(), p.GetName())
+			conflicts[p.GetName()] = true
+			continue
+		}
+		ref := p.Spec.CredentialsSecretRef
+		m.printf("create ProviderConfig %s with credentials from key %s of Secret %s/%s\n", p.GetName(), ref.Key, ref.Namespace, ref.Name)
+		if m.dryRun {
+			continue
+		}
+		if err := m.client.Create(ctx, want); err != nil {
+			return nil, errors.Wrap(err, errCreateProviderCfg)
+		}
+	}
+	return conflicts, nil
+}
+
+// migrateManaged patches each managed resource of the supplied list kind that
+// references a Provider to reference a ProviderConfig instead.
+func (m *Migrator) migrateManaged(ctx context.Context, gvk schema.GroupVersionKind, conflicts map[string]bool) error {
+	obj, err := m.client.Scheme().New(gvk)
+	if err != nil {
+		return errors.Wrapf(err, errListManagedFmt, gvk.Kind)
+	}
+	l := obj.(resource.ManagedList)
+	if err := m.client.List(ctx, l); err != nil {
+		// The CRD of this kind is not installed, so there's nothing to
+		// migrate.
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return errors.Wrapf(err, errListManagedFmt, gvk.Kind)
+	}
+	kind := strings.TrimSuffix(gvk.Kind, listKindSuffix)
+	for _, mg := range l.GetItems() {
+		ref := mg.GetProviderReference()
+		if ref == nil || mg.GetProviderConfigReference() != nil {
+			continue
+		}
+		if conflicts[ref.Name] && kind != kindContainer {
+			continue
+		}
+		m.printf("patch %s %s: providerRef %s -> providerConfigRef %s\n", kind, mg.GetName(), ref.Name, ref.Name)
+		if m.dryRun {
+			continue
+		}
+		patch := client.MergeFrom(mg.DeepCopyObject())
+		mg.SetProviderConfigReference(&xpv1.Reference{Name: ref.Name})
+		mg.SetProviderReference(nil)
+		if err := m.client.Patch(ctx, mg, patch); err != nil {
+			return errors.Wrapf(err, errPatchManagedFmt, kind, mg.GetName())
+		}
+	}
+	return nil
+}
+
+// managedListKinds returns the list kinds of every managed resource known to
+// the client's scheme, in a stable order.
+func (m *Migrator) managedListKinds() []schema.GroupVersionKind {
+	s := m.client.Scheme()
+	gvks := []schema.GroupVersionKind{}
+	for gvk := range s.AllKnownTypes() {
+		if !strings.HasSuffix(gvk.Kind, listKindSuffix) {
+			continue
+		}
+		obj, err := s.New(gvk)
+		if err != nil {
+			continue
+		}
+		if _, ok := obj.(resource.ManagedList); ok {
+			gvks = append(gvks, gvk)
+		}
+	}
+	sort.Slice(gvks, func(i, j int) bool { return gvks[i].String() < gvks[j].String() })
+	return gvks
+}
+
+func (m *Migrator) printf(format string, a ...interface{}) {
+	fmt.Fprintf(m.out, format, a...) // nolint:errcheck
+}
+
+// ProviderConfig returns a ProviderConfig equivalent to the supplied Provider.
+func ProviderConfig(p *v1alpha3.Provider) *v1beta1.ProviderConfig {
+	ref := p.Spec.CredentialsSecretRef
+	pc := &v1beta1.ProviderConfig{
+		Spec: v1beta1.ProviderConfigSpec{
+			Credentials: v1beta1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+					SecretRef: &xpv1.SecretKeySelector{
+						SecretReference: xpv1.SecretReference{Name: ref.Name, Namespace: ref.Namespace},
+						Key:             ref.Key,
+					},
+				},
+			},
+		},
+	}
+	pc.SetName(p.GetName())
+	pc.SetLabels(p.GetLabels())
+	return pc
+}
+
+func sameCredentials(a, b *v1beta1.ProviderConfig) bool {
+	ca, cb := a.Spec.Credentials, b.Spec.Credentials
+	if ca.Source != cb.Source || ca.SecretRef == nil || cb.SecretRef == nil {
+		return false
+	}
+	return *ca.SecretRef == *cb.SecretRef
+}
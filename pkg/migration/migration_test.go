@@ -0,0 +1,176 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/apis"
+	storagev1alpha3 "github.com/crossplane/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1alpha3"
+	"github.com/crossplane/provider-azure/apis/v1beta1"
+)
+
+func TestMigrate(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	secretRef := xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "azure-creds"},
+		Key:             "credentials",
+	}
+	provider := &v1alpha3.Provider{
+		ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+		Spec:       v1alpha3.ProviderSpec{CredentialsSecretRef: secretRef},
+	}
+	rg := func() *v1alpha3.ResourceGroup {
+		rg := &v1alpha3.ResourceGroup{ObjectMeta: metav1.ObjectMeta{Name: "cool-rg"}}
+		rg.SetProviderReference(&xpv1.Reference{Name: "cool"})
+		return rg
+	}
+	container := func() *storagev1alpha3.Container {
+		c := &storagev1alpha3.Container{ObjectMeta: metav1.ObjectMeta{Name: "cool-container"}}
+		c.SetProviderReference(&xpv1.Reference{Name: "cool-account"})
+		return c
+	}
+	otherPC := &v1beta1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+		Spec: v1beta1.ProviderConfigSpec{Credentials: v1beta1.ProviderCredentials{
+			Source: xpv1.CredentialsSourceInjectedIdentity,
+		}},
+	}
+
+	type want struct {
+		out    string
+		pc     *v1beta1.ProviderConfig
+		rgRef  string
+		ctrRef string
+	}
+	cases := map[string]struct {
+		reason  string
+		objects []client.Object
+		dryRun  bool
+		want    want
+	}{
+		"DryRun": {
+			reason:  "A dry run should print the planned changes without making them.",
+			objects: []client.Object{provider, rg(), container()},
+			dryRun:  true,
+			want: want{
+				out: "create ProviderConfig cool with credentials from key credentials of Secret crossplane-system/azure-creds\n" +
+					"patch ResourceGroup cool-rg: providerRef cool -> providerConfigRef cool\n" +
+					"patch Container cool-container: providerRef cool-account -> providerConfigRef cool-account\n",
+			},
+		},
+		"Migrate": {
+			reason:  "A ProviderConfig should be created for each Provider, and managed resources should reference it.",
+			objects: []client.Object{provider, rg(), container()},
+			want: want{
+				out: "create ProviderConfig cool with credentials from key credentials of Secret crossplane-system/azure-creds\n" +
+					"patch ResourceGroup cool-rg: providerRef cool -> providerConfigRef cool\n" +
+					"patch Container cool-container: providerRef cool-account -> providerConfigRef cool-account\n",
+				pc:     ProviderConfig(provider),
+				rgRef:  "cool",
+				ctrRef: "cool-account",
+			},
+		},
+		"ExistingProviderConfig": {
+			reason:  "An existing ProviderConfig with the same credentials should be reused.",
+			objects: []client.Object{provider, ProviderConfig(provider), rg()},
+			want: want{
+				out: "ProviderConfig cool already exists\n" +
+					"patch ResourceGroup cool-rg: providerRef cool -> providerConfigRef cool\n",
+				pc:    ProviderConfig(provider),
+				rgRef: "cool",
+			},
+		},
+		"ConflictingProviderConfig": {
+			reason:  "Managed resources should not be migrated to a ProviderConfig with different credentials, except Containers.",
+			objects: []client.Object{provider, otherPC, rg(), container()},
+			want: want{
+				out: "ProviderConfig cool already exists with different credentials; skipping managed resources that reference Provider cool\n" +
+					"patch Container cool-container: providerRef cool-account -> providerConfigRef cool-account\n",
+				pc:     otherPC,
+				ctrRef: "cool-account",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(tc.objects...).Build()
+			out := &bytes.Buffer{}
+			if err := NewMigrator(c, WithOutput(out), WithDryRun(tc.dryRun)).Migrate(context.Background()); err != nil {
+				t.Fatalf("\n%s\nMigrate(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.out, out.String()); diff != "" {
+				t.Errorf("\n%s\nMigrate(...): -want output, +got output:\n%s", tc.reason, diff)
+			}
+
+			var pc *v1beta1.ProviderConfig
+			if got := (&v1beta1.ProviderConfig{}); c.Get(context.Background(), types.NamespacedName{Name: "cool"}, got) == nil {
+				pc = got
+			}
+			if diff := cmp.Diff(specOf(tc.want.pc), specOf(pc)); diff != "" {
+				t.Errorf("\n%s\nMigrate(...): -want ProviderConfig, +got ProviderConfig:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rgRef, configRefOf(t, c, "cool-rg", &v1alpha3.ResourceGroup{})); diff != "" {
+				t.Errorf("\n%s\nMigrate(...): -want ResourceGroup providerConfigRef, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ctrRef, configRefOf(t, c, "cool-container", &storagev1alpha3.Container{})); diff != "" {
+				t.Errorf("\n%s\nMigrate(...): -want Container providerConfigRef, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func specOf(pc *v1beta1.ProviderConfig) *v1beta1.ProviderConfigSpec {
+	if pc == nil {
+		return nil
+	}
+	return &pc.Spec
+}
+
+// configRefOf returns the name of the ProviderConfig referenced by the named
+// managed resource, if it exists.
+func configRefOf(t *testing.T, c client.Client, name string, mg resource.Managed) string {
+	t.Helper()
+	if err := c.Get(context.Background(), types.NamespacedName{Name: name}, mg); err != nil {
+		return ""
+	}
+	if mg.GetProviderConfigReference() == nil {
+		return ""
+	}
+	if mg.GetProviderReference() != nil {
+		t.Errorf("%s references both a Provider and a ProviderConfig", name)
+	}
+	return mg.GetProviderConfigReference().Name
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package orphan finds the Azure resources created by the provider that are
+// no longer owned by a managed resource.
+package orphan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-azure/apis/v1alpha3"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+// Error strings.
+const (
+	errListOwned   = "cannot list Azure resources tagged with an owner"
+	errGetOwnerFmt = "cannot get %s %s"
+	errWrite       = "cannot write orphaned Azure resources"
+)
+
+// Reasons an Azure resource is orphaned.
+const (
+	ReasonUnknownKind = "UnknownKind"
+	ReasonNotFound    = "OwnerNotFound"
+	ReasonReplaced    = "OwnerReplaced"
+)
+
+// A Resource is an Azure resource that is tagged with its owner.
+type Resource struct {
+	ID   string            `json:"id"`
+	Name string            `json:"name"`
+	Type string            `json:"type"`
+	Tags map[string]string `json:"tags"`
+}
+
+// An Orphan is an Azure resource whose owner no longer exists.
+type Orphan struct {
+	Resource
+
+	// Reason the Azure resource is orphaned.
+	Reason string
+}
+
+// A Lister lists the Azure resources that are tagged with an owner.
+type Lister interface {
+	Owned(ctx context.Context) ([]Resource, error)
+}
+
+// A GraphLister lists Azure resources using Azure Resource Graph.
+type GraphLister struct {
+	endpoint       string
+	subscriptionID string
+	authorizer     autorest.Authorizer
+}
+
+// NewGraphLister returns a Lister that queries Resource Graph for the Azure
+// resources of the subscription of the supplied ProviderConfig.
+func NewGraphLister(ctx context.Context, kube client.Client, providerConfig string) (*GraphLister, error) {
+	// GetAuthInfo loads credentials for a managed resource, so we supply one
+	// that references the ProviderConfig.
+	mg := &v1alpha3.ResourceGroup{}
+	mg.SetProviderConfigReference(&xpv1.Reference{Name: providerConfig})
+	creds, auth, err := azure.GetAuthInfo(ctx, kube, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphLister{
+		endpoint:       creds[azure.CredentialsKeyResourceManagerEndpointURL],
+		subscriptionID: creds[azure.CredentialsKeySubscriptionID],
+		authorizer:     auth,
+	}, nil
+}
+
+// Owned returns the Azure resources, including resource groups, that are
+// tagged with an owner.
+func (l *GraphLister) Owned(ctx context.Context) ([]Resource, error) {
+	q := fmt.Sprintf("Resources | union (ResourceContainers | where type =~ 'microsoft.resources/subscriptions/resourcegroups') | where isnotempty(tags[%q]) | project id, name, type, tags", azure.TagKeyOwnerUID)
+	rs, err := azure.QueryGraphResources(ctx, l.subscriptionID, l.endpoint, l.authorizer, q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Resource, 0, len(rs))
+	for _, raw := range rs {
+		r := Resource{}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// A Finder finds orphaned Azure resources, i.e. those tagged with an owner
+// that no longer exists. An Azure resource whose owner was replaced by a
+// managed resource of the same kind and name is orphaned unless the new
+// managed resource has adopted it, i.e. has its name as its external name.
+type Finder struct {
+	client client.Client
+	lister Lister
+	out    io.Writer
+}
+
+// A FinderOption configures a Finder.
+type FinderOption func(*Finder)
+
+// WithOutput specifies where the Finder should report orphaned Azure
+// resources.
+func WithOutput(w io.Writer) FinderOption {
+	return func(f *Finder) {
+		f.out = w
+	}
+}
+
+// NewFinder returns a Finder that lists Azure resources using the supplied
+// Lister, and gets their owners using the supplied client. Azure resources
+// whose owner is of a kind unknown to the client's scheme are orphaned.
+func NewFinder(c client.Client, l Lister, o ...FinderOption) *Finder {
+	f := &Finder{client: c, lister: l, out: ioutil.Discard}
+	for _, fo := range o {
+		fo(f)
+	}
+	return f
+}
+
+// Report prints the orphaned Azure resources as a table.
+func (f *Finder) Report(ctx context.Context) error {
+	orphans, err := f.Find(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(f.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tOWNER\tREASON")
+	for _, o := range orphans {
+		fmt.Fprintf(w, "%s\t%s/%s\t%s\n", o.ID, o.Tags[azure.TagKeyOwnerKind], o.Tags[azure.TagKeyOwnerName], o.Reason)
+	}
+	return errors.Wrap(w.Flush(), errWrite)
+}
+
+// Find returns the orphaned Azure resources, ordered by ID.
+func (f *Finder) Find(ctx context.Context) ([]Orphan, error) {
+	rs, err := f.lister.Owned(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errListOwned)
+	}
+	orphans := []Orphan{}
+	for _, r := range rs {
+		reason, err := f.orphaned(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			orphans = append(orphans, Orphan{Resource: r, Reason: reason})
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].ID < orphans[j].ID })
+	return orphans, nil
+}
+
+// orphaned returns the reason the supplied Azure resource is orphaned, or an
+// empty string if it is not.
+func (f *Finder) orphaned(ctx context.Context, r Resource) (string, error) {
+	o, ok := azure.OwnerOf(r.Tags)
+	if !ok {
+		return ReasonUnknownKind, nil
+	}
+	gvk, ok := f.managedKind(o.Kind)
+	if !ok {
+		return ReasonUnknownKind, nil
+	}
+	obj, err := f.client.Scheme().New(gvk)
+	if err != nil {
+		return ReasonUnknownKind, nil
+	}
+	mg, ok := obj.(resource.Managed)
+	if !ok {
+		return ReasonUnknownKind, nil
+	}
+	err = f.client.Get(ctx, types.NamespacedName{Name: o.Name}, mg)
+	switch {
+	// The owner can't exist if its CRD is not installed.
+	case kerrors.IsNotFound(err), kmeta.IsNoMatchError(err):
+		return ReasonNotFound, nil
+	case err != nil:
+		return "", errors.Wrapf(err, errGetOwnerFmt, o.Kind.Kind, o.Name)
+	case mg.GetUID() == o.UID, strings.EqualFold(meta.GetExternalName(mg), r.Name):
+		return "", nil
+	}
+	return ReasonReplaced, nil
+}
+
+// managedKind returns the kind of managed resource known to the client's
+// scheme with the supplied group and kind.
+func (f *Finder) managedKind(gk schema.GroupKind) (schema.GroupVersionKind, bool) {
+	for gvk := range f.client.Scheme().AllKnownTypes() {
+		if gvk.GroupKind() == gk {
+			return gvk, true
+		}
+	}
+	return schema.GroupVersionKind{}, false
+}
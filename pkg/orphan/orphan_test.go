@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphan
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-azure/apis"
+	cachev1beta1 "github.com/crossplane/provider-azure/apis/cache/v1beta1"
+	azure "github.com/crossplane/provider-azure/pkg/clients"
+)
+
+type fakeLister struct {
+	resources []Resource
+	err       error
+}
+
+func (l *fakeLister) Owned(_ context.Context) ([]Resource, error) {
+	return l.resources, l.err
+}
+
+func TestFind(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	errBoom := errors.New("boom")
+
+	cache := func(name, uid, externalName string) *cachev1beta1.Redis {
+		cr := &cachev1beta1.Redis{ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(uid)}}
+		meta.SetExternalName(cr, externalName)
+		return cr
+	}
+	owned := func(id, name, kind, owner, uid string) Resource {
+		return Resource{ID: id, Name: name, Tags: map[string]string{
+			azure.TagKeyOwnerKind: kind,
+			azure.TagKeyOwnerName: owner,
+			azure.TagKeyOwnerUID:  uid,
+		}}
+	}
+	redisKind := "Redis.cache.azure.crossplane.io"
+
+	type want struct {
+		orphans []Orphan
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		objects []client.Object
+		l       Lister
+		want    want
+	}{
+		"Owned": {
+			reason:  "Azure resources whose owner exists should not be orphaned.",
+			objects: []client.Object{cache("cool", "cool-uid", "coolCache")},
+			l:       &fakeLister{resources: []Resource{owned("/a", "coolCache", redisKind, "cool", "cool-uid")}},
+			want:    want{orphans: []Orphan{}},
+		},
+		"Adopted": {
+			reason:  "Azure resources adopted by a replacement owner should not be orphaned.",
+			objects: []client.Object{cache("cool", "new-uid", "coolCache")},
+			l:       &fakeLister{resources: []Resource{owned("/a", "coolCache", redisKind, "cool", "old-uid")}},
+			want:    want{orphans: []Orphan{}},
+		},
+		"Replaced": {
+			reason:  "Azure resources whose owner was replaced by a managed resource with another external name should be orphaned.",
+			objects: []client.Object{cache("cool", "new-uid", "otherCache")},
+			l:       &fakeLister{resources: []Resource{owned("/a", "coolCache", redisKind, "cool", "old-uid")}},
+			want: want{orphans: []Orphan{
+				{Resource: owned("/a", "coolCache", redisKind, "cool", "old-uid"), Reason: ReasonReplaced},
+			}},
+		},
+		"NotFoundAndUnknown": {
+			reason: "Azure resources whose owner does not exist, or is of an unknown kind, should be orphaned in order of their IDs.",
+			l: &fakeLister{resources: []Resource{
+				owned("/b", "coolCache", redisKind, "cool", "cool-uid"),
+				owned("/a", "coolThing", "Thing.example.org", "cool", "cool-uid"),
+			}},
+			want: want{orphans: []Orphan{
+				{Resource: owned("/a", "coolThing", "Thing.example.org", "cool", "cool-uid"), Reason: ReasonUnknownKind},
+				{Resource: owned("/b", "coolCache", redisKind, "cool", "cool-uid"), Reason: ReasonNotFound},
+			}},
+		},
+		"ListError": {
+			reason: "Errors listing Azure resources should be returned.",
+			l:      &fakeLister{err: errBoom},
+			want:   want{err: errors.Wrap(errBoom, errListOwned)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(tc.objects...).Build()
+			got, err := NewFinder(c, tc.l).Find(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nFind(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.orphans, got); diff != "" {
+				t.Errorf("\n%s\nFind(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	l := &fakeLister{resources: []Resource{{ID: "/a", Name: "coolCache", Tags: map[string]string{
+		azure.TagKeyOwnerKind: "Redis.cache.azure.crossplane.io",
+		azure.TagKeyOwnerName: "cool",
+		azure.TagKeyOwnerUID:  "cool-uid",
+	}}}}
+	out := &bytes.Buffer{}
+	if err := NewFinder(fake.NewClientBuilder().WithScheme(s).Build(), l, WithOutput(out)).Report(context.Background()); err != nil {
+		t.Fatalf("Report(...): %s", err)
+	}
+	want := []string{
+		"ID  OWNER                                 REASON",
+		"/a  Redis.cache.azure.crossplane.io/cool  OwnerNotFound",
+	}
+	if diff := cmp.Diff(want, strings.Split(strings.TrimSpace(out.String()), "\n")); diff != "" {
+		t.Errorf("Report(...): -want, +got:\n%s", diff)
+	}
+}